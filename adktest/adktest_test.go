@@ -0,0 +1,149 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adktest_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/adk/adktest"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+func newWeatherAgent(t *testing.T, m *adktest.Model) agent.Agent {
+	t.Helper()
+	type Args struct {
+		City string `json:"city"`
+	}
+	getWeather, err := functiontool.New(functiontool.Config{Name: "get_weather", Description: "returns the weather in a city"}, func(_ tool.Context, args Args) map[string]any {
+		return map[string]any{"weather": "sunny in " + args.City}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "weather",
+		Model:       m,
+		Instruction: "Answer about the weather.",
+		Tools:       []tool.Tool{getWeather},
+		OutputKey:   "answer",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestHarness(t *testing.T) {
+	m := adktest.NewModel(t,
+		adktest.FunctionCall("get_weather", map[string]any{"city": "Paris"}).Expect(
+			adktest.ExpectText("What is the weather in Paris?"),
+			adktest.ExpectSystemInstruction("Answer about the weather."),
+		),
+		adktest.Text("It is sunny in Paris.").Expect(
+			adktest.ExpectFunctionResponse("get_weather", map[string]any{"weather": "sunny in Paris"}),
+		),
+	)
+	h := adktest.NewHarness(t, runner.Config{Agent: newWeatherAgent(t, m)})
+
+	events := h.Run("session", "What is the weather in Paris?")
+
+	adktest.AssertTrajectory(t, events, adktest.ToolCall{Name: "get_weather", Args: map[string]any{"city": "Paris"}})
+	adktest.AssertStateDelta(t, events, map[string]any{"answer": "It is sunny in Paris."})
+	adktest.AssertGolden(t, events, filepath.Join("testdata", "TestHarness.golden"))
+	if got := len(m.Requests()); got != 2 {
+		t.Errorf("model requests = %d, want 2", got)
+	}
+	if got, err := h.Session("session").State().Get("answer"); err != nil || got != "It is sunny in Paris." {
+		t.Errorf("session state answer = %v, %v, want %q", got, err, "It is sunny in Paris.")
+	}
+}
+
+func TestHarness_InitialState(t *testing.T) {
+	m := adktest.NewModel(t, adktest.Text("Hello Ada.").Expect(adktest.ExpectSystemInstruction("The user is Ada.")))
+	a, err := llmagent.New(llmagent.Config{Name: "greeter", Model: m, Instruction: "The user is {name}."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := adktest.NewHarness(t, runner.Config{Agent: a})
+	h.CreateSession("session", map[string]any{"name": "Ada"})
+
+	events := h.Run("session", "hi")
+
+	adktest.AssertTrajectory(t, events)
+}
+
+func TestHarness_ModelError(t *testing.T) {
+	errBackend := errors.New("backend unavailable")
+	m := adktest.NewModel(t, adktest.Error(errBackend))
+	h := adktest.NewHarness(t, runner.Config{Agent: newWeatherAgent(t, m)})
+
+	_, err := adktest.Collect(h.RunContent("session", genai.NewContentFromText("hi", genai.RoleUser), agent.RunConfig{}))
+	if !errors.Is(err, errBackend) {
+		t.Errorf("Run() error = %v, want %v", err, errBackend)
+	}
+}
+
+func TestAssertTrajectory_Mismatch(t *testing.T) {
+	m := adktest.NewModel(t,
+		adktest.FunctionCall("get_weather", map[string]any{"city": "Paris"}),
+		adktest.Text("It is sunny."),
+	)
+	h := adktest.NewHarness(t, runner.Config{Agent: newWeatherAgent(t, m)})
+	events := h.Run("session", "weather?")
+
+	tests := []struct {
+		name string
+		want []adktest.ToolCall
+	}{
+		{name: "other tool", want: []adktest.ToolCall{{Name: "get_time"}}},
+		{name: "other args", want: []adktest.ToolCall{{Name: "get_weather", Args: map[string]any{"city": "Rome"}}}},
+		{name: "missing call", want: nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeTB{TB: t}
+			adktest.AssertTrajectory(fake, events, tc.want...)
+			if !fake.failed {
+				t.Errorf("AssertTrajectory() succeeded, want a failure")
+			}
+		})
+	}
+}
+
+func TestModel_UnusedTurns(t *testing.T) {
+	fake := &fakeTB{TB: t}
+	t.Run("script", func(t *testing.T) {
+		fake.TB = t
+		adktest.NewModel(fake, adktest.Text("never requested"))
+	})
+	if !fake.failed {
+		t.Errorf("NewModel() with unused turns succeeded, want a failure")
+	}
+}
+
+// fakeTB records the failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeTB) Errorf(string, ...any) { f.failed = true }
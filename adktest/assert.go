@@ -0,0 +1,179 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adktest
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/session"
+)
+
+var update = flag.Bool("adktest.update", false, "update the golden files of adktest.AssertGolden")
+
+// ToolCall is a call of a tool in the trajectory of an agent.
+type ToolCall struct {
+	Name string
+	// Args are the arguments of the call. In the expected trajectory of
+	// AssertTrajectory, nil matches any arguments.
+	Args map[string]any
+}
+
+// Trajectory returns the tool calls of the events, in order.
+func Trajectory(events []*session.Event) []ToolCall {
+	var calls []ToolCall
+	for _, ev := range events {
+		if ev.Partial || ev.Content == nil {
+			continue
+		}
+		for _, p := range ev.Content.Parts {
+			if p.FunctionCall != nil {
+				calls = append(calls, ToolCall{Name: p.FunctionCall.Name, Args: p.FunctionCall.Args})
+			}
+		}
+	}
+	return calls
+}
+
+// AssertTrajectory checks that the tool calls of the events are want.
+func AssertTrajectory(t testing.TB, events []*session.Event, want ...ToolCall) {
+	t.Helper()
+	got := Trajectory(events)
+	for i := range min(len(got), len(want)) {
+		if want[i].Args == nil {
+			got[i].Args = nil
+		}
+	}
+	// The arguments are compared in JSON, so that e.g. 1 matches the
+	// float64 decoded from the model response.
+	if diff := cmp.Diff(want, got, cmp.Transformer("json", func(args map[string]any) string {
+		b, err := json.Marshal(args)
+		if err != nil {
+			return fmt.Sprint(args)
+		}
+		return string(b)
+	})); diff != "" {
+		t.Errorf("trajectory mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// StateDelta returns the state changes of the events, the later ones
+// overriding the earlier ones.
+func StateDelta(events []*session.Event) map[string]any {
+	delta := make(map[string]any)
+	for _, ev := range events {
+		if !ev.Partial {
+			maps.Copy(delta, ev.Actions.StateDelta)
+		}
+	}
+	return delta
+}
+
+// AssertStateDelta checks that the state changes of the events are want.
+func AssertStateDelta(t testing.TB, events []*session.Event, want map[string]any) {
+	t.Helper()
+	if diff := cmp.Diff(want, StateDelta(events)); diff != "" {
+		t.Errorf("state delta mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Transcript returns a readable description of the events, one line per
+// part or action, without the IDs and timestamps which change between the
+// runs. The partial events are skipped.
+func Transcript(events []*session.Event) string {
+	var b strings.Builder
+	line := func(author, format string, args ...any) {
+		fmt.Fprintf(&b, "%s: %s\n", author, fmt.Sprintf(format, args...))
+	}
+	for _, ev := range events {
+		if ev.Partial {
+			continue
+		}
+		if ev.Content != nil {
+			for _, p := range ev.Content.Parts {
+				switch {
+				case p.Thought:
+					line(ev.Author, "thought %q", p.Text)
+				case p.Text != "":
+					line(ev.Author, "%q", p.Text)
+				case p.FunctionCall != nil:
+					line(ev.Author, "call %s(%s)", p.FunctionCall.Name, marshal(p.FunctionCall.Args))
+				case p.FunctionResponse != nil:
+					line(ev.Author, "response %s %s", p.FunctionResponse.Name, marshal(p.FunctionResponse.Response))
+				}
+			}
+		}
+		if ev.ErrorCode != "" || ev.ErrorMessage != "" {
+			line(ev.Author, "error %s %q", ev.ErrorCode, ev.ErrorMessage)
+		}
+		if len(ev.Actions.StateDelta) > 0 {
+			line(ev.Author, "state %s", marshal(ev.Actions.StateDelta))
+		}
+		if len(ev.Actions.ArtifactDelta) > 0 {
+			line(ev.Author, "artifacts %s", marshal(ev.Actions.ArtifactDelta))
+		}
+		if ev.Actions.TransferToAgent != "" {
+			line(ev.Author, "transfer to %s", ev.Actions.TransferToAgent)
+		}
+		if ev.Actions.Escalate {
+			line(ev.Author, "escalate")
+		}
+	}
+	return b.String()
+}
+
+func marshal(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// AssertGolden checks that the transcript of the events, see Transcript,
+// is the content of the golden file at path. Run the test with the flag
+// -adktest.update to write the transcript to the file instead.
+func AssertGolden(t testing.TB, events []*session.Event, path string) {
+	t.Helper()
+	got := Transcript(events)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden file %s does not exist, run the test with -adktest.update to create it", path)
+	}
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if diff := cmp.Diff(string(want), got); diff != "" {
+		t.Errorf("transcript mismatch with %s (-want +got):\n%s", path, diff)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package adktest provides utilities for testing agents.
+//
+// Model is a model.LLM answering with scripted turns, so that the agents
+// can be tested without calling a real model. Harness runs an agent with
+// in-memory services and collects the events, which can be checked with
+// AssertTrajectory, AssertStateDelta and AssertGolden:
+//
+//	m := adktest.NewModel(t,
+//		adktest.FunctionCall("get_weather", map[string]any{"city": "Paris"}),
+//		adktest.Text("It is sunny in Paris.").Expect(
+//			adktest.ExpectFunctionResponse("get_weather", map[string]any{"weather": "sunny"})),
+//	)
+//	a, _ := llmagent.New(llmagent.Config{Name: "weather", Model: m, Tools: tools})
+//	h := adktest.NewHarness(t, runner.Config{Agent: a})
+//	events := h.Run("session", "What is the weather in Paris?")
+//	adktest.AssertTrajectory(t, events, adktest.ToolCall{Name: "get_weather"})
+package adktest
+
+import (
+	"iter"
+	"testing"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// UserID is the ID of the user of the sessions of a Harness.
+const UserID = "test_user"
+
+// Harness runs an agent in tests.
+type Harness struct {
+	t       testing.TB
+	cfg     runner.Config
+	runner  *runner.Runner
+	created map[string]bool
+}
+
+// NewHarness returns a harness running the agent of cfg. The app name
+// defaults to "test_app" and the session service to an in-memory one.
+func NewHarness(t testing.TB, cfg runner.Config) *Harness {
+	t.Helper()
+	if cfg.AppName == "" {
+		cfg.AppName = "test_app"
+	}
+	if cfg.SessionService == nil {
+		cfg.SessionService = session.InMemoryService()
+	}
+	r, err := runner.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to create runner: %v", err)
+	}
+	return &Harness{t: t, cfg: cfg, runner: r, created: make(map[string]bool)}
+}
+
+// CreateSession creates the session with the initial state. The sessions
+// are otherwise created, empty, by their first run.
+func (h *Harness) CreateSession(sessionID string, state map[string]any) {
+	h.t.Helper()
+	_, err := h.cfg.SessionService.Create(h.t.Context(), &session.CreateRequest{
+		AppName:   h.cfg.AppName,
+		UserID:    UserID,
+		SessionID: sessionID,
+		State:     state,
+	})
+	if err != nil {
+		h.t.Fatalf("failed to create session %q: %v", sessionID, err)
+	}
+	h.created[sessionID] = true
+}
+
+// Session returns the session, e.g. to check its state.
+func (h *Harness) Session(sessionID string) session.Session {
+	h.t.Helper()
+	resp, err := h.cfg.SessionService.Get(h.t.Context(), &session.GetRequest{
+		AppName:   h.cfg.AppName,
+		UserID:    UserID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		h.t.Fatalf("failed to get session %q: %v", sessionID, err)
+	}
+	return resp.Session
+}
+
+// Run runs the agent with the user message text in the session, and returns
+// the events. It fails the test if the run fails; use RunContent to test
+// the errors.
+func (h *Harness) Run(sessionID, text string) []*session.Event {
+	h.t.Helper()
+	events, err := Collect(h.RunContent(sessionID, genai.NewContentFromText(text, genai.RoleUser), agent.RunConfig{}))
+	if err != nil {
+		h.t.Fatalf("run failed: %v", err)
+	}
+	return events
+}
+
+// RunContent runs the agent with the user message content in the session.
+func (h *Harness) RunContent(sessionID string, content *genai.Content, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
+	h.t.Helper()
+	if !h.created[sessionID] {
+		h.CreateSession(sessionID, nil)
+	}
+	return h.runner.Run(h.t.Context(), UserID, sessionID, content, cfg)
+}
+
+// Collect collects the events of stream until it fails. It returns the
+// events collected so far and the error.
+func Collect(stream iter.Seq2[*session.Event, error]) ([]*session.Event, error) {
+	var events []*session.Event
+	for ev, err := range stream {
+		if err != nil {
+			return events, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httprr
+
+import (
+	"google.golang.org/genai"
+)
+
+// NewGeminiClientConfig returns the configuration of the genai clients,
+// e.g. of gemini.NewModel, sending their requests through rr.
+//
+// It adds to rr the scrubbers of the Gemini API requests: the credentials
+// and the headers holding version numbers are removed, and the JSON bodies
+// are canonicalized. In replay mode, the API key is a fake one, so that no
+// credentials are needed; in record mode, it is read from the environment,
+// see genai.NewClient.
+func NewGeminiClientConfig(rr *RecordReplay) *genai.ClientConfig {
+	rr.ScrubReq(
+		ScrubAuth,
+		ScrubHeaders("X-Goog-Api-Client", "User-Agent"),
+		CanonicalizeJSON,
+	)
+	cfg := &genai.ClientConfig{HTTPClient: rr.Client()}
+	if !rr.Recording() {
+		cfg.APIKey = "fake-key"
+	}
+	return cfg
+}
@@ -6,8 +6,15 @@
 //
 // [Open] creates a new [RecordReplay]. Whether it is recording or replaying
 // is controlled by the -httprecord flag, which is defined by this package
-// only in test programs (built by “go test”).
+// only in test programs (built by “go test”), or by the [Mode] passed to
+// [OpenMode].
 // See the [Open] documentation for more details.
+//
+// The requests are matched exactly against the log, after scrubbing.
+// The scrubbers of this package, e.g. [ScrubAuth] and [ScrubUUIDs], remove
+// the secrets and canonicalize the nondeterministic parts of the requests.
+// [NewGeminiClientConfig] wires a RecordReplay into the clients of
+// gemini.NewModel.
 package httprr
 
 import (
@@ -105,14 +112,46 @@ func (rr *RecordReplay) Recording() bool {
 // makes actual HTTP requests using rt but then logs the requests and
 // responses to the file for replaying in a future run.
 func Open(file string, rt http.RoundTripper) (*RecordReplay, error) {
-	record, err := Recording(file)
-	if err != nil {
-		return nil, err
+	return OpenMode(file, rt, ModeFlag)
+}
+
+// Mode selects whether a [RecordReplay] records or replays.
+type Mode int
+
+const (
+	// ModeFlag records if the -httprecord flag matches the file, and
+	// replays otherwise.
+	ModeFlag Mode = iota
+	// ModeReplay replays the log of the file.
+	ModeReplay
+	// ModeRecord records a new log in the file.
+	ModeRecord
+)
+
+// OpenMode is like [Open], but records or replays according to mode.
+//
+// If rt is nil, [http.DefaultTransport] makes the requests in record mode.
+func OpenMode(file string, rt http.RoundTripper, mode Mode) (*RecordReplay, error) {
+	if rt == nil {
+		rt = http.DefaultTransport
 	}
-	if record {
+	switch mode {
+	case ModeFlag:
+		record, err := Recording(file)
+		if err != nil {
+			return nil, err
+		}
+		if record {
+			return create(file, rt)
+		}
+		return open(file, rt)
+	case ModeReplay:
+		return open(file, rt)
+	case ModeRecord:
 		return create(file, rt)
+	default:
+		return nil, fmt.Errorf("invalid httprr mode %d", mode)
 	}
-	return open(file, rt)
 }
 
 // Recording reports whether the "-httprecord" flag is set
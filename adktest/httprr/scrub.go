@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httprr
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// authHeaders are the headers removed by ScrubAuth.
+var authHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Goog-Api-Key",
+	"X-Api-Key",
+	"Api-Key",
+}
+
+// authParams are the query parameters removed by ScrubAuth.
+var authParams = []string{"key", "api_key", "access_token"}
+
+// ScrubAuth is a request scrubber removing the credentials: the
+// authorization and API key headers, and the API key query parameters.
+func ScrubAuth(req *http.Request) error {
+	if err := ScrubHeaders(authHeaders...)(req); err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	changed := false
+	for _, name := range authParams {
+		if q.Has(name) {
+			q.Del(name)
+			changed = true
+		}
+	}
+	if changed {
+		req.URL.RawQuery = q.Encode()
+	}
+	return nil
+}
+
+// ScrubHeaders returns a request scrubber removing the headers, whether
+// their keys are canonicalized or not.
+func ScrubHeaders(names ...string) func(*http.Request) error {
+	return func(req *http.Request) error {
+		for _, name := range names {
+			req.Header.Del(name)
+			for key := range req.Header {
+				if strings.EqualFold(key, name) {
+					delete(req.Header, key)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// ScrubHeaderPrefix returns a request scrubber removing the headers whose
+// names start with prefix, ignoring the case, e.g. "X-Stainless-".
+func ScrubHeaderPrefix(prefix string) func(*http.Request) error {
+	return func(req *http.Request) error {
+		for key := range req.Header {
+			if len(key) >= len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+				delete(req.Header, key)
+			}
+		}
+		return nil
+	}
+}
+
+// CanonicalizeJSON is a request scrubber compacting the JSON bodies, whose
+// spacing may vary between the runs.
+func CanonicalizeJSON(req *http.Request) error {
+	b, ok := jsonBody(req)
+	if !ok {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b.Data); err == nil {
+		b.Data = buf.Bytes()
+	}
+	return nil
+}
+
+// ScrubJSONFields returns a request scrubber replacing the values of the
+// fields with the names in the JSON bodies, at any depth, with a
+// placeholder. The bodies are re-encoded with their object keys sorted.
+func ScrubJSONFields(names ...string) func(*http.Request) error {
+	return func(req *http.Request) error {
+		b, ok := jsonBody(req)
+		if !ok {
+			return nil
+		}
+		dec := json.NewDecoder(bytes.NewReader(b.Data))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil // not JSON after all, keep it as is
+		}
+		data, err := json.Marshal(scrubFields(v, names))
+		if err != nil {
+			return err
+		}
+		b.Data = data
+		return nil
+	}
+}
+
+func scrubFields(v any, names []string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			scrubbed := false
+			for _, name := range names {
+				if k == name {
+					v[k] = "<scrubbed>"
+					scrubbed = true
+					break
+				}
+			}
+			if !scrubbed {
+				v[k] = scrubFields(val, names)
+			}
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = scrubFields(val, names)
+		}
+	}
+	return v
+}
+
+var (
+	uuidPattern      = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?`)
+)
+
+// ScrubUUIDs is a request scrubber replacing the UUIDs in the URL and the
+// body, e.g. generated IDs, with a placeholder.
+var ScrubUUIDs = ScrubPattern(uuidPattern, "<uuid>")
+
+// ScrubTimestamps is a request scrubber replacing the RFC 3339 timestamps
+// in the URL and the body with a placeholder.
+var ScrubTimestamps = ScrubPattern(timestampPattern, "<timestamp>")
+
+// ScrubPattern returns a request scrubber replacing the matches of re in
+// the URL and the body with repl, see [regexp.Regexp.ReplaceAll].
+func ScrubPattern(re *regexp.Regexp, repl string) func(*http.Request) error {
+	return func(req *http.Request) error {
+		req.URL.Path = re.ReplaceAllString(req.URL.Path, repl)
+		req.URL.RawPath = re.ReplaceAllString(req.URL.RawPath, repl)
+		req.URL.RawQuery = re.ReplaceAllString(req.URL.RawQuery, repl)
+		if req.Body != nil {
+			b := req.Body.(*Body)
+			b.Data = re.ReplaceAll(b.Data, []byte(repl))
+		}
+		return nil
+	}
+}
+
+// jsonBody returns the body of req if it is JSON.
+func jsonBody(req *http.Request) (*Body, bool) {
+	if req.Body == nil {
+		return nil, false
+	}
+	ctype := req.Header.Get("Content-Type")
+	if ctype != "application/json" && !strings.HasPrefix(ctype, "application/json;") {
+		return nil, false
+	}
+	return req.Body.(*Body), true
+}
@@ -0,0 +1,161 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httprr
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// respond returns a transport answering all the requests with body.
+func respond(body string) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+}
+
+func post(t *testing.T, rr *RecordReplay, url, body string, header http.Header) error {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := rr.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestScrubbers_TolerantMatching(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "trace.httprr")
+	scrubs := []func(*http.Request) error{
+		ScrubAuth,
+		ScrubUUIDs,
+		ScrubTimestamps,
+		ScrubJSONFields("nonce"),
+		CanonicalizeJSON,
+	}
+
+	rr, err := OpenMode(file, respond(`{"ok":true}`), ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr.ScrubReq(scrubs...)
+	err = post(t, rr, "https://example.com/v1/sessions/0b8a1f6e-8f3c-4c1e-9a55-6f0f2a7d1c11?key=secret1",
+		`{"id": "0b8a1f6e-8f3c-4c1e-9a55-6f0f2a7d1c11", "time": "2025-01-02T03:04:05.678Z", "nested": {"nonce": 1}}`,
+		http.Header{"Authorization": {"Bearer secret2"}, "x-goog-api-key": {"secret3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret1", "secret2", "secret3"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("trace contains %q:\n%s", secret, data)
+		}
+	}
+
+	rr, err = OpenMode(file, nil, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr.ScrubReq(scrubs...)
+	err = post(t, rr, "https://example.com/v1/sessions/7d2e4b1a-0c9f-4e6b-8d3a-2b1c0e9f8a77?key=other",
+		`{"id":"7d2e4b1a-0c9f-4e6b-8d3a-2b1c0e9f8a77","time":"2025-06-07T08:09:10Z","nested":{"nonce":2}}`,
+		http.Header{"Authorization": {"Bearer other"}})
+	if err != nil {
+		t.Errorf("replay of an equivalent request failed: %v", err)
+	}
+	err = post(t, rr, "https://example.com/v1/sessions/7d2e4b1a-0c9f-4e6b-8d3a-2b1c0e9f8a77",
+		`{"id":"7d2e4b1a-0c9f-4e6b-8d3a-2b1c0e9f8a77","time":"2025-06-07T08:09:10Z","nested":{"nonce":2},"extra":1}`, nil)
+	if err == nil {
+		t.Errorf("replay of a different request succeeded, want an error")
+	}
+}
+
+func TestOpenMode_Invalid(t *testing.T) {
+	if _, err := OpenMode(filepath.Join(t.TempDir(), "trace.httprr"), nil, Mode(42)); err == nil {
+		t.Errorf("OpenMode() with an invalid mode succeeded, want an error")
+	}
+}
+
+func TestNewGeminiClientConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "trace.httprr")
+	t.Setenv("GOOGLE_API_KEY", "real-key")
+	const body = `{"candidates": [{"content": {"role": "model", "parts": [{"text": "Paris"}]}}]}`
+
+	generate := func(mode Mode, rt http.RoundTripper) string {
+		t.Helper()
+		rr, err := OpenMode(file, rt, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rr.Close()
+		client, err := genai.NewClient(t.Context(), NewGeminiClientConfig(rr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Models.GenerateContent(t.Context(), "gemini-2.5-flash", genai.Text("Capital of France?"), nil)
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		return resp.Text()
+	}
+
+	if got := generate(ModeRecord, respond(body)); got != "Paris" {
+		t.Errorf("recorded response = %q, want %q", got, "Paris")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "real-key") {
+		t.Errorf("trace contains the API key:\n%s", data)
+	}
+	if got := generate(ModeReplay, nil); got != "Paris" {
+		t.Errorf("replayed response = %q, want %q", got, "Paris")
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adktest
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// Check asserts on a request received by a Model. It reports the failures
+// with t.Errorf.
+type Check func(t testing.TB, req *model.LLMRequest)
+
+// Turn is the scripted answer of a Model to one request.
+type Turn struct {
+	// Response is returned by the model, unless Err is set.
+	Response *model.LLMResponse
+	// Err is returned by the model.
+	Err error
+	// Checks assert on the request of the turn.
+	Checks []Check
+}
+
+// Text returns the turn answering with text.
+func Text(text string) Turn {
+	return Content(genai.NewContentFromText(text, genai.RoleModel))
+}
+
+// FunctionCall returns the turn calling the function name with args.
+func FunctionCall(name string, args map[string]any) Turn {
+	return Content(genai.NewContentFromFunctionCall(name, args, genai.RoleModel))
+}
+
+// Content returns the turn answering with content, e.g. several function
+// calls.
+func Content(content *genai.Content) Turn {
+	return Turn{Response: &model.LLMResponse{Content: content, TurnComplete: true}}
+}
+
+// Error returns the turn failing with err.
+func Error(err error) Turn {
+	return Turn{Err: err}
+}
+
+// Expect returns the turn with the checks added.
+func (t Turn) Expect(checks ...Check) Turn {
+	t.Checks = append(append([]Check(nil), t.Checks...), checks...)
+	return t
+}
+
+// ExpectText checks that the last content of the request is the text
+// want, e.g. the message of the user.
+func ExpectText(want string) Check {
+	return func(t testing.TB, req *model.LLMRequest) {
+		t.Helper()
+		if got := ContentText(lastContent(req)); got != want {
+			t.Errorf("request text = %q, want %q", got, want)
+		}
+	}
+}
+
+// ExpectFunctionResponse checks that the last content of the request holds
+// the response want of the function name.
+func ExpectFunctionResponse(name string, want map[string]any) Check {
+	return func(t testing.TB, req *model.LLMRequest) {
+		t.Helper()
+		if c := lastContent(req); c != nil {
+			for _, p := range c.Parts {
+				if p.FunctionResponse != nil && p.FunctionResponse.Name == name {
+					if diff := cmp.Diff(want, p.FunctionResponse.Response); diff != "" {
+						t.Errorf("function response %s mismatch (-want +got):\n%s", name, diff)
+					}
+					return
+				}
+			}
+		}
+		t.Errorf("request has no function response %s", name)
+	}
+}
+
+// ExpectSystemInstruction checks that the system instruction of the request
+// contains substr.
+func ExpectSystemInstruction(substr string) Check {
+	return func(t testing.TB, req *model.LLMRequest) {
+		t.Helper()
+		var instruction string
+		if req.Config != nil {
+			instruction = ContentText(req.Config.SystemInstruction)
+		}
+		if !strings.Contains(instruction, substr) {
+			t.Errorf("system instruction = %q, want it to contain %q", instruction, substr)
+		}
+	}
+}
+
+// Model is a model.LLM answering the requests with scripted turns, in
+// order. It fails the test if it gets more requests than turns, or if turns
+// are left when the test ends.
+//
+// It is safe for concurrent use.
+type Model struct {
+	t    testing.TB
+	name string
+
+	mu       sync.Mutex
+	turns    []Turn
+	requests []*model.LLMRequest
+}
+
+// NewModel returns a model answering with the turns.
+func NewModel(t testing.TB, turns ...Turn) *Model {
+	m := &Model{t: t, name: "adktest-model", turns: turns}
+	t.Cleanup(func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if len(m.turns) > 0 {
+			t.Errorf("model %s: %d scripted turns were not used", m.name, len(m.turns))
+		}
+	})
+	return m
+}
+
+// Name implements model.LLM.
+func (m *Model) Name() string {
+	return m.name
+}
+
+// GenerateContent implements model.LLM. It answers with the next turn,
+// after running its checks on req.
+func (m *Model) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.mu.Lock()
+		m.requests = append(m.requests, req)
+		n := len(m.requests)
+		if len(m.turns) == 0 {
+			m.mu.Unlock()
+			m.t.Errorf("model %s: unexpected request %d: %q", m.name, n, ContentText(lastContent(req)))
+			yield(nil, fmt.Errorf("model %s: no scripted turn for request %d", m.name, n))
+			return
+		}
+		turn := m.turns[0]
+		m.turns = m.turns[1:]
+		m.mu.Unlock()
+
+		for _, check := range turn.Checks {
+			check(m.t, req)
+		}
+		if turn.Err != nil {
+			yield(nil, turn.Err)
+			return
+		}
+		var resp model.LLMResponse
+		if turn.Response != nil {
+			resp = *turn.Response
+		}
+		yield(&resp, nil)
+	}
+}
+
+// Requests returns the requests received so far.
+func (m *Model) Requests() []*model.LLMRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*model.LLMRequest(nil), m.requests...)
+}
+
+var _ model.LLM = (*Model)(nil)
+
+// ContentText returns the concatenated text parts of c, excluding the
+// thoughts.
+func ContentText(c *genai.Content) string {
+	if c == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, p := range c.Parts {
+		if !p.Thought {
+			b.WriteString(p.Text)
+		}
+	}
+	return b.String()
+}
+
+func lastContent(req *model.LLMRequest) *genai.Content {
+	if len(req.Contents) == 0 {
+		return nil
+	}
+	return req.Contents[len(req.Contents)-1]
+}
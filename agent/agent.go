@@ -21,6 +21,7 @@ import (
 
 	"google.golang.org/adk/artifact"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/plugininternal/pluginctx"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
@@ -213,6 +214,13 @@ func getAuthorForEvent(ctx InvocationContext, event *session.Event) string {
 	return ctx.Agent().Name()
 }
 
+// pluginCallbacks are the agent callbacks of the plugins of the runner, run
+// by all the agents before their own callbacks. See the plugin package.
+type pluginCallbacks interface {
+	BeforeAgentCallbacks() []BeforeAgentCallback
+	AfterAgentCallbacks() []AfterAgentCallback
+}
+
 // runBeforeAgentCallbacks checks if any beforeAgentCallback returns non-nil content
 // then it skips agent run and returns callback result.
 func runBeforeAgentCallbacks(ctx InvocationContext) (*session.Event, error) {
@@ -224,7 +232,11 @@ func runBeforeAgentCallbacks(ctx InvocationContext) (*session.Event, error) {
 		actions:           &session.EventActions{StateDelta: make(map[string]any)},
 	}
 
-	for _, callback := range ctx.Agent().internal().beforeAgentCallbacks {
+	callbacks := ctx.Agent().internal().beforeAgentCallbacks
+	if p, ok := pluginctx.FromContext(ctx).(pluginCallbacks); ok {
+		callbacks = append(p.BeforeAgentCallbacks(), callbacks...)
+	}
+	for _, callback := range callbacks {
 		content, err := callback(callbackCtx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to run before agent callback: %w", err)
@@ -267,7 +279,11 @@ func runAfterAgentCallbacks(ctx InvocationContext) (*session.Event, error) {
 		actions:           &session.EventActions{StateDelta: make(map[string]any)},
 	}
 
-	for _, callback := range agent.internal().afterAgentCallbacks {
+	callbacks := agent.internal().afterAgentCallbacks
+	if p, ok := pluginctx.FromContext(ctx).(pluginCallbacks); ok {
+		callbacks = append(p.AfterAgentCallbacks(), callbacks...)
+	}
+	for _, callback := range callbacks {
 		newContent, err := callback(callbackCtx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to run after agent callback: %w", err)
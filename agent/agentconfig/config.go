@@ -0,0 +1,89 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentconfig
+
+// The agent classes of AgentConfig.AgentClass.
+const (
+	ClassLLMAgent        = "LlmAgent"
+	ClassSequentialAgent = "SequentialAgent"
+	ClassParallelAgent   = "ParallelAgent"
+	ClassLoopAgent       = "LoopAgent"
+)
+
+// AgentConfig is the definition of an agent in a YAML or JSON file.
+//
+// For example:
+//
+//	name: root_agent
+//	model: gemini-2.5-flash
+//	instruction: You are a helpful assistant.
+//	tools:
+//	  - name: google_search
+//	sub_agents:
+//	  - config_path: researcher.yaml
+type AgentConfig struct {
+	// AgentClass is the kind of agent: LlmAgent (the default),
+	// SequentialAgent, ParallelAgent or LoopAgent.
+	AgentClass string `yaml:"agent_class"`
+	// Name of the agent, unique within the agent tree.
+	Name string `yaml:"name"`
+	// Description of the agent's capability.
+	Description string `yaml:"description"`
+	// SubAgents of the agent.
+	SubAgents []SubAgentConfig `yaml:"sub_agents"`
+
+	// Model is the name of the model of an LlmAgent, e.g. gemini-2.5-flash.
+	Model string `yaml:"model"`
+	// Instruction of an LlmAgent, see llmagent.Config.Instruction.
+	Instruction string `yaml:"instruction"`
+	// GlobalInstruction of an LlmAgent, see
+	// llmagent.Config.GlobalInstruction.
+	GlobalInstruction string `yaml:"global_instruction"`
+	// OutputKey of an LlmAgent, see llmagent.Config.OutputKey.
+	OutputKey string `yaml:"output_key"`
+	// IncludeContents of an LlmAgent: default or none.
+	IncludeContents string `yaml:"include_contents"`
+	// DisallowTransferToParent of an LlmAgent.
+	DisallowTransferToParent bool `yaml:"disallow_transfer_to_parent"`
+	// DisallowTransferToPeers of an LlmAgent.
+	DisallowTransferToPeers bool `yaml:"disallow_transfer_to_peers"`
+	// GenerateContentConfig of an LlmAgent, with the fields of
+	// genai.GenerateContentConfig in snake_case or camelCase, e.g.
+	// temperature or max_output_tokens.
+	GenerateContentConfig map[string]any `yaml:"generate_content_config"`
+	// Tools of an LlmAgent.
+	Tools []ToolConfig `yaml:"tools"`
+
+	// MaxIterations of a LoopAgent. If it is zero, the loop runs until a
+	// sub-agent escalates.
+	MaxIterations uint `yaml:"max_iterations"`
+}
+
+// SubAgentConfig is a sub-agent, either defined inline or referenced by the
+// path of its file.
+type SubAgentConfig struct {
+	// ConfigPath is the path of the file of the sub-agent, relative to the
+	// file referencing it.
+	ConfigPath  string `yaml:"config_path"`
+	AgentConfig `yaml:",inline"`
+}
+
+// ToolConfig references a tool of the Registry.
+type ToolConfig struct {
+	// Name of the tool in the Registry.
+	Name string `yaml:"name"`
+	// Args are passed to the factory of the tool.
+	Args map[string]any `yaml:"args"`
+}
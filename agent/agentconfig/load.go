@@ -0,0 +1,329 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agentconfig loads agent trees from declarative YAML or JSON files,
+// so that the agents can be changed without recompiling.
+//
+// The files follow AgentConfig. LLM agents reference their model by name and
+// their tools by the names of a Registry, where Go code registers the tool
+// factories. Sub-agents are defined inline or in other files.
+package agentconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
+	"google.golang.org/adk/agent/workflowagents/parallelagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+	"gopkg.in/yaml.v3"
+)
+
+// Config of the loading of agent configs.
+type Config struct {
+	// Tools are the tools the agent configs can reference. If it is nil,
+	// only the built-in tools of NewRegistry can be referenced.
+	Tools *Registry
+	// NewModel creates the model of the LLM agents from its name. If it is
+	// nil, Gemini models are created with the client configuration of the
+	// environment, see genai.NewClient.
+	NewModel func(ctx context.Context, name string) (model.LLM, error)
+}
+
+// Load loads the agent tree defined in the YAML or JSON file at path.
+func Load(ctx context.Context, path string, cfg Config) (agent.Agent, error) {
+	if cfg.Tools == nil {
+		cfg.Tools = NewRegistry()
+	}
+	if cfg.NewModel == nil {
+		cfg.NewModel = func(ctx context.Context, name string) (model.LLM, error) {
+			return gemini.NewModel(ctx, name, &genai.ClientConfig{})
+		}
+	}
+	l := &loader{cfg: cfg, models: make(map[string]model.LLM)}
+	return l.loadFile(ctx, path, nil)
+}
+
+// LoadAll loads the agent trees defined in the files at paths, and returns
+// their root agents in the order of the paths.
+func LoadAll(ctx context.Context, cfg Config, paths ...string) ([]agent.Agent, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("at least one agent config is required")
+	}
+	agents := make([]agent.Agent, 0, len(paths))
+	for _, path := range paths {
+		a, err := Load(ctx, path, cfg)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, a)
+	}
+	return agents, nil
+}
+
+type loader struct {
+	cfg Config
+	// models are the models created so far, by name.
+	models map[string]model.LLM
+}
+
+// loadFile loads the agent of the file at path. stack holds the files
+// referencing it, to detect cycles.
+func (l *loader) loadFile(ctx context.Context, path string, stack []string) (agent.Agent, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve agent config path: %w", err)
+	}
+	if slices.Contains(stack, path) {
+		return nil, fmt.Errorf("agent config %q references itself", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent config: %w", err)
+	}
+	var c AgentConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse agent config %q: %w", path, err)
+	}
+	a, err := l.build(ctx, &c, filepath.Dir(path), append(stack, path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent config %q: %w", path, err)
+	}
+	return a, nil
+}
+
+// build creates the agent of c. dir is the directory of the file defining
+// it.
+func (l *loader) build(ctx context.Context, c *AgentConfig, dir string, stack []string) (agent.Agent, error) {
+	var subAgents []agent.Agent
+	for i, sub := range c.SubAgents {
+		var (
+			a   agent.Agent
+			err error
+		)
+		if sub.ConfigPath != "" {
+			if !reflect.ValueOf(sub.AgentConfig).IsZero() {
+				return nil, fmt.Errorf("agent %q: sub_agents[%d] sets both config_path and an inline agent", c.Name, i)
+			}
+			path := sub.ConfigPath
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			a, err = l.loadFile(ctx, path, stack)
+		} else {
+			a, err = l.build(ctx, &sub.AgentConfig, dir, stack)
+		}
+		if err != nil {
+			return nil, err
+		}
+		subAgents = append(subAgents, a)
+	}
+
+	if c.MaxIterations != 0 && c.AgentClass != ClassLoopAgent {
+		return nil, fmt.Errorf("agent %q: max_iterations is only supported by %s", c.Name, ClassLoopAgent)
+	}
+	agentCfg := agent.Config{
+		Name:        c.Name,
+		Description: c.Description,
+		SubAgents:   subAgents,
+	}
+	switch c.AgentClass {
+	case "", ClassLLMAgent:
+		return l.buildLLMAgent(ctx, c, subAgents)
+	case ClassSequentialAgent, ClassParallelAgent, ClassLoopAgent:
+		if fields := llmFields(c); len(fields) > 0 {
+			return nil, fmt.Errorf("agent %q: %v only supported by %s", c.Name, fields, ClassLLMAgent)
+		}
+	default:
+		return nil, fmt.Errorf("agent %q: unknown agent_class %q", c.Name, c.AgentClass)
+	}
+	switch c.AgentClass {
+	case ClassSequentialAgent:
+		return sequentialagent.New(sequentialagent.Config{AgentConfig: agentCfg})
+	case ClassParallelAgent:
+		return parallelagent.New(parallelagent.Config{AgentConfig: agentCfg})
+	default:
+		return loopagent.New(loopagent.Config{AgentConfig: agentCfg, MaxIterations: c.MaxIterations})
+	}
+}
+
+func (l *loader) buildLLMAgent(ctx context.Context, c *AgentConfig, subAgents []agent.Agent) (agent.Agent, error) {
+	if c.Model == "" {
+		return nil, fmt.Errorf("agent %q: model is required", c.Name)
+	}
+	m, ok := l.models[c.Model]
+	if !ok {
+		var err error
+		if m, err = l.cfg.NewModel(ctx, c.Model); err != nil {
+			return nil, fmt.Errorf("agent %q: failed to create model %q: %w", c.Name, c.Model, err)
+		}
+		l.models[c.Model] = m
+	}
+
+	var tools []tool.Tool
+	for _, t := range c.Tools {
+		created, err := l.cfg.Tools.newTool(ctx, t.Name, t.Args)
+		if err != nil {
+			return nil, fmt.Errorf("agent %q: %w", c.Name, err)
+		}
+		tools = append(tools, created)
+	}
+
+	var generateConfig *genai.GenerateContentConfig
+	if c.GenerateContentConfig != nil {
+		generateConfig = &genai.GenerateContentConfig{}
+		if err := decodeGenerateContentConfig(c.GenerateContentConfig, generateConfig); err != nil {
+			return nil, fmt.Errorf("agent %q: invalid generate_content_config: %w", c.Name, err)
+		}
+	}
+
+	includeContents := llmagent.IncludeContents(c.IncludeContents)
+	switch includeContents {
+	case "", llmagent.IncludeContentsDefault, llmagent.IncludeContentsNone:
+	default:
+		return nil, fmt.Errorf("agent %q: invalid include_contents %q", c.Name, c.IncludeContents)
+	}
+
+	return llmagent.New(llmagent.Config{
+		Name:                     c.Name,
+		Description:              c.Description,
+		SubAgents:                subAgents,
+		Model:                    m,
+		Instruction:              c.Instruction,
+		GlobalInstruction:        c.GlobalInstruction,
+		OutputKey:                c.OutputKey,
+		IncludeContents:          includeContents,
+		DisallowTransferToParent: c.DisallowTransferToParent,
+		DisallowTransferToPeers:  c.DisallowTransferToPeers,
+		GenerateContentConfig:    generateConfig,
+		Tools:                    tools,
+	})
+}
+
+// llmFields returns the fields of c set in the config which only apply to
+// LLM agents.
+func llmFields(c *AgentConfig) []string {
+	var fields []string
+	v := reflect.ValueOf(*c)
+	for _, name := range []string{"Model", "Instruction", "GlobalInstruction", "OutputKey", "IncludeContents", "DisallowTransferToParent", "DisallowTransferToPeers", "GenerateContentConfig", "Tools"} {
+		if f, _ := v.Type().FieldByName(name); !v.FieldByName(name).IsZero() {
+			fields = append(fields, f.Tag.Get("yaml"))
+		}
+	}
+	return fields
+}
+
+// decodeGenerateContentConfig decodes the generate content config written
+// in YAML into cfg. The fields can be named in snake_case or camelCase.
+func decodeGenerateContentConfig(m map[string]any, cfg *genai.GenerateContentConfig) error {
+	b, err := json.Marshal(normalizeKeys(m, reflect.TypeOf(cfg)))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(cfg)
+}
+
+// normalizeKeys returns v with the snake_case keys of the objects decoded
+// into structs of type t converted to the JSON names of their fields. The
+// keys of the maps, e.g. labels, are kept.
+func normalizeKeys(v any, t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			switch t.Kind() {
+			case reflect.Struct:
+				if name, ft, ok := jsonField(t, k); ok {
+					out[name] = normalizeKeys(val, ft)
+					continue
+				}
+				out[k] = val
+			case reflect.Map:
+				out[k] = normalizeKeys(val, t.Elem())
+			default:
+				out[k] = val
+			}
+		}
+		return out
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return v
+		}
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = normalizeKeys(val, t.Elem())
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// jsonField returns the JSON name and the type of the field of the struct
+// type t named key, in snake_case or as in JSON.
+func jsonField(t reflect.Type, key string) (string, reflect.Type, bool) {
+	camel := snakeToCamel(key)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if name == key || name == camel {
+			return name, f.Type, true
+		}
+	}
+	return "", nil, false
+}
+
+// snakeToCamel converts a snake_case name to camelCase.
+func snakeToCamel(s string) string {
+	b := make([]byte, 0, len(s))
+	upper := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '_':
+			upper = true
+		case upper && 'a' <= c && c <= 'z':
+			b = append(b, c-'a'+'A')
+			upper = false
+		default:
+			b = append(b, c)
+			upper = false
+		}
+	}
+	return string(b)
+}
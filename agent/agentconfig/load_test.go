@@ -0,0 +1,320 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentconfig_test
+
+import (
+	"context"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/agentconfig"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+type fakeLLM struct {
+	name     string
+	requests []*model.LLMRequest
+}
+
+func (m *fakeLLM) Name() string { return m.name }
+
+func (m *fakeLLM) GenerateContent(_ context.Context, req *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	m.requests = append(m.requests, req)
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{Content: genai.NewContentFromText("ok", genai.RoleModel)}, nil)
+	}
+}
+
+// writeFiles writes the files in a temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func newConfig(t *testing.T) (agentconfig.Config, *[]string) {
+	t.Helper()
+	type Args struct {
+		City string `json:"city"`
+	}
+	registry := agentconfig.NewRegistry()
+	err := registry.Register("get_weather", func(_ context.Context, args map[string]any) (tool.Tool, error) {
+		units, _ := args["units"].(string)
+		return functiontool.New(functiontool.Config{Name: "get_weather", Description: "weather in " + units}, func(tool.Context, Args) string {
+			return "sunny"
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var models []string
+	return agentconfig.Config{
+		Tools: registry,
+		NewModel: func(_ context.Context, name string) (model.LLM, error) {
+			models = append(models, name)
+			return &fakeLLM{name: name}, nil
+		},
+	}, &models
+}
+
+// tree describes the agent tree of a.
+func tree(a agent.Agent) string {
+	var b strings.Builder
+	var walk func(a agent.Agent, depth int)
+	walk = func(a agent.Agent, depth int) {
+		b.WriteString(strings.Repeat("  ", depth) + a.Name() + "\n")
+		for _, sub := range a.SubAgents() {
+			walk(sub, depth+1)
+		}
+	}
+	walk(a, 0)
+	return b.String()
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"root.yaml": `
+agent_class: SequentialAgent
+name: pipeline
+description: researches then writes
+sub_agents:
+  - config_path: researcher.yaml
+  - agent_class: LoopAgent
+    name: refine
+    max_iterations: 3
+    sub_agents:
+      - name: writer
+        model: gemini-2.5-pro
+        instruction: Write about {topic}.
+        output_key: draft
+        tools:
+          - name: exit_loop
+`,
+		"researcher.yaml": `
+name: researcher
+model: gemini-2.5-flash
+instruction: Research the topic.
+include_contents: none
+generate_content_config:
+  temperature: 0.2
+  max_output_tokens: 256
+  thinking_config:
+    thinking_budget: 128
+  labels:
+    team_name: research
+tools:
+  - name: get_weather
+    args:
+      units: metric
+  - name: google_search
+`,
+	})
+	cfg, models := newConfig(t)
+
+	root, err := agentconfig.Load(t.Context(), filepath.Join(dir, "root.yaml"), cfg)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := `pipeline
+  researcher
+  refine
+    writer
+`
+	if diff := cmp.Diff(want, tree(root)); diff != "" {
+		t.Errorf("agent tree mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"gemini-2.5-flash", "gemini-2.5-pro"}, *models); diff != "" {
+		t.Errorf("created models mismatch (-want +got):\n%s", diff)
+	}
+	if got := root.Description(); got != "researches then writes" {
+		t.Errorf("Description() = %q, want %q", got, "researches then writes")
+	}
+}
+
+func TestLoad_GenerateContentConfig(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"root.yaml": `
+name: root
+model: gemini-2.5-flash
+instruction: Be brief.
+generate_content_config:
+  temperature: 0.2
+  maxOutputTokens: 256
+  thinking_config:
+    thinking_budget: 128
+  labels:
+    team_name: research
+`,
+	})
+	m := &fakeLLM{name: "gemini-2.5-flash"}
+	cfg := agentconfig.Config{NewModel: func(context.Context, string) (model.LLM, error) { return m, nil }}
+	root, err := agentconfig.Load(t.Context(), filepath.Join(dir, "root.yaml"), cfg)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, root).Run(t, "session", "hi")); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(m.requests) != 1 {
+		t.Fatalf("model requests = %d, want 1", len(m.requests))
+	}
+	got := m.requests[0].Config
+	if got.Temperature == nil || *got.Temperature != 0.2 || got.MaxOutputTokens != 256 ||
+		got.ThinkingConfig == nil || got.ThinkingConfig.ThinkingBudget == nil || *got.ThinkingConfig.ThinkingBudget != 128 {
+		t.Errorf("request config = %+v, want the generate_content_config", got)
+	}
+	if diff := cmp.Diff(map[string]string{"team_name": "research"}, got.Labels); diff != "" {
+		t.Errorf("labels mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoad_JSON(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"root.json": `{"name": "root", "model": "gemini-2.5-flash", "generate_content_config": {"topP": 0.5}}`,
+	})
+	cfg, _ := newConfig(t)
+	root, err := agentconfig.Load(t.Context(), filepath.Join(dir, "root.json"), cfg)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if root.Name() != "root" {
+		t.Errorf("Name() = %q, want %q", root.Name(), "root")
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			files:   map[string]string{"root.yaml": "name: root\nmodel: m\ninstructions: typo\n"},
+			wantErr: "field instructions not found",
+		},
+		{
+			name:    "unknown agent class",
+			files:   map[string]string{"root.yaml": "name: root\nagent_class: Robot\n"},
+			wantErr: `unknown agent_class "Robot"`,
+		},
+		{
+			name:    "missing model",
+			files:   map[string]string{"root.yaml": "name: root\n"},
+			wantErr: "model is required",
+		},
+		{
+			name:    "unknown tool",
+			files:   map[string]string{"root.yaml": "name: root\nmodel: m\ntools:\n  - name: teleport\n"},
+			wantErr: `unknown tool "teleport"`,
+		},
+		{
+			name:    "llm field on workflow agent",
+			files:   map[string]string{"root.yaml": "name: root\nagent_class: ParallelAgent\nmodel: m\n"},
+			wantErr: "[model] only supported by LlmAgent",
+		},
+		{
+			name:    "max_iterations on llm agent",
+			files:   map[string]string{"root.yaml": "name: root\nmodel: m\nmax_iterations: 2\n"},
+			wantErr: "max_iterations is only supported by LoopAgent",
+		},
+		{
+			name:    "invalid generate_content_config",
+			files:   map[string]string{"root.yaml": "name: root\nmodel: m\ngenerate_content_config:\n  temprature: 1\n"},
+			wantErr: `unknown field "temprature"`,
+		},
+		{
+			name: "config_path and inline agent",
+			files: map[string]string{
+				"root.yaml": "name: root\nmodel: m\nsub_agents:\n  - config_path: sub.yaml\n    name: sub\n",
+				"sub.yaml":  "name: sub\nmodel: m\n",
+			},
+			wantErr: "sets both config_path and an inline agent",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"root.yaml": "name: root\nmodel: m\nsub_agents:\n  - config_path: sub.yaml\n",
+				"sub.yaml":  "name: sub\nmodel: m\nsub_agents:\n  - config_path: root.yaml\n",
+			},
+			wantErr: "references itself",
+		},
+		{
+			name:    "missing sub-agent file",
+			files:   map[string]string{"root.yaml": "name: root\nmodel: m\nsub_agents:\n  - config_path: missing.yaml\n"},
+			wantErr: "failed to read agent config",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+			cfg, _ := newConfig(t)
+			_, err := agentconfig.Load(t.Context(), filepath.Join(dir, "root.yaml"), cfg)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Load() error = %v, want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := agentconfig.NewRegistry()
+	factory := func(context.Context, map[string]any) (tool.Tool, error) { return nil, nil }
+	if err := registry.Register("my_tool", factory); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	for _, name := range []string{"my_tool", "google_search"} {
+		if err := registry.Register(name, factory); err == nil {
+			t.Errorf("Register(%q) succeeded, want a duplicate error", name)
+		}
+	}
+}
+
+func TestLoadAll(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"assistant.yaml": "name: assistant\nmodel: m\n",
+		"reviewer.yaml":  "name: reviewer\nmodel: m\n",
+	})
+	cfg, _ := newConfig(t)
+
+	agents, err := agentconfig.LoadAll(t.Context(), cfg, filepath.Join(dir, "assistant.yaml"), filepath.Join(dir, "reviewer.yaml"))
+	if err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	var names []string
+	for _, a := range agents {
+		names = append(names, a.Name())
+	}
+	if diff := cmp.Diff([]string{"assistant", "reviewer"}, names); diff != "" {
+		t.Errorf("LoadAll() agents mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := agentconfig.LoadAll(t.Context(), cfg); err == nil {
+		t.Error("LoadAll() without paths succeeded, want an error")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentconfig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/exitlooptool"
+	"google.golang.org/adk/tool/geminitool"
+	"google.golang.org/adk/tool/loadartifactstool"
+)
+
+// ToolFactory creates a tool referenced in an agent config, with the args
+// of the reference.
+type ToolFactory func(ctx context.Context, args map[string]any) (tool.Tool, error)
+
+// Registry holds the factories of the tools that agent configs reference by
+// name.
+//
+// It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]ToolFactory
+}
+
+// NewRegistry returns a registry of the built-in tools: google_search,
+// exit_loop and load_artifacts.
+func NewRegistry() *Registry {
+	r := &Registry{tools: make(map[string]ToolFactory)}
+	r.tools["google_search"] = func(context.Context, map[string]any) (tool.Tool, error) {
+		return geminitool.GoogleSearch{}, nil
+	}
+	r.tools["exit_loop"] = func(context.Context, map[string]any) (tool.Tool, error) {
+		return exitlooptool.New()
+	}
+	r.tools["load_artifacts"] = func(context.Context, map[string]any) (tool.Tool, error) {
+		return loadartifactstool.New(), nil
+	}
+	return r
+}
+
+// Register registers the factory of the tool named name. It fails if a tool
+// with this name is already registered.
+func (r *Registry) Register(name string, factory ToolFactory) error {
+	if name == "" || factory == nil {
+		return fmt.Errorf("tool name and factory are required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tools[name]; ok {
+		return fmt.Errorf("tool %q is already registered", name)
+	}
+	r.tools[name] = factory
+	return nil
+}
+
+// newTool creates the tool named name with args.
+func (r *Registry) newTool(ctx context.Context, name string, args map[string]any) (tool.Tool, error) {
+	r.mu.RLock()
+	factory, ok := r.tools[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown tool %q", name)
+	}
+	t, err := factory(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tool %q: %w", name, err)
+	}
+	return t, nil
+}
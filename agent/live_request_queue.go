@@ -0,0 +1,99 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// LiveRequestQueue carries user input to an agent running in bidirectional
+// streaming mode.
+//
+// The queue is unbounded, so senders never block. Once closed, all further
+// requests are dropped and Receive keeps returning a close request.
+type LiveRequestQueue struct {
+	mu       sync.Mutex
+	requests []*model.LiveRequest
+	closed   bool
+	ready    chan struct{}
+}
+
+// NewLiveRequestQueue creates an empty LiveRequestQueue.
+func NewLiveRequestQueue() *LiveRequestQueue {
+	return &LiveRequestQueue{ready: make(chan struct{}, 1)}
+}
+
+// Send enqueues the request.
+func (q *LiveRequestQueue) Send(req *model.LiveRequest) {
+	if req == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	if req.Close {
+		q.closed = true
+	}
+	q.requests = append(q.requests, req)
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// SendContent enqueues turn-by-turn content, e.g. user text.
+func (q *LiveRequestQueue) SendContent(content *genai.Content) {
+	q.Send(&model.LiveRequest{Content: content})
+}
+
+// SendRealtime enqueues realtime input, e.g. an audio or video chunk.
+func (q *LiveRequestQueue) SendRealtime(blob *genai.Blob) {
+	q.Send(&model.LiveRequest{Blob: blob})
+}
+
+// Close ends the live session once all previously sent requests are consumed.
+func (q *LiveRequestQueue) Close() {
+	q.Send(&model.LiveRequest{Close: true})
+}
+
+// Receive blocks until the next request is available or ctx is done.
+func (q *LiveRequestQueue) Receive(ctx context.Context) (*model.LiveRequest, error) {
+	for {
+		q.mu.Lock()
+		if len(q.requests) > 0 {
+			req := q.requests[0]
+			q.requests = q.requests[1:]
+			q.mu.Unlock()
+			return req, nil
+		}
+		closed := q.closed
+		q.mu.Unlock()
+		if closed {
+			return &model.LiveRequest{Close: true}, nil
+		}
+
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
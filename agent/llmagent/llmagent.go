@@ -20,10 +20,12 @@ import (
 	"strings"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
 	agentinternal "google.golang.org/adk/internal/agent"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/llminternal"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/genai"
@@ -51,15 +53,28 @@ func New(cfg Config) (agent.Agent, error) {
 		afterToolCallbacks = append(afterToolCallbacks, llminternal.AfterToolCallback(c))
 	}
 
+	onModelErrorCallbacks := make([]llminternal.OnModelErrorCallback, 0, len(cfg.OnModelErrorCallbacks))
+	for _, c := range cfg.OnModelErrorCallbacks {
+		onModelErrorCallbacks = append(onModelErrorCallbacks, llminternal.OnModelErrorCallback(c))
+	}
+
+	onToolErrorCallbacks := make([]llminternal.OnToolErrorCallback, 0, len(cfg.OnToolErrorCallbacks))
+	for _, c := range cfg.OnToolErrorCallbacks {
+		onToolErrorCallbacks = append(onToolErrorCallbacks, llminternal.OnToolErrorCallback(c))
+	}
+
 	a := &llmAgent{
-		beforeModelCallbacks: beforeModelCallbacks,
-		model:                cfg.Model,
-		afterModelCallbacks:  afterModelCallbacks,
-		beforeToolCallbacks:  beforeToolCallbacks,
-		afterToolCallbacks:   afterToolCallbacks,
-		instruction:          cfg.Instruction,
-		inputSchema:          cfg.InputSchema,
-		outputSchema:         cfg.OutputSchema,
+		beforeModelCallbacks:   beforeModelCallbacks,
+		model:                  cfg.Model,
+		afterModelCallbacks:    afterModelCallbacks,
+		beforeToolCallbacks:    beforeToolCallbacks,
+		afterToolCallbacks:     afterToolCallbacks,
+		onModelErrorCallbacks:  onModelErrorCallbacks,
+		onToolErrorCallbacks:   onToolErrorCallbacks,
+		toolConfirmationPolicy: cfg.ToolConfirmationPolicy,
+		instruction:            cfg.Instruction,
+		inputSchema:            cfg.InputSchema,
+		outputSchema:           cfg.OutputSchema,
 
 		State: llminternal.State{
 			Model:                    cfg.Model,
@@ -72,11 +87,19 @@ func New(cfg Config) (agent.Agent, error) {
 			OutputSchema:             cfg.OutputSchema,
 			// TODO: internal type for includeContents
 			IncludeContents:           string(cfg.IncludeContents),
+			HistoryWindow:             (*llminternal.HistoryWindow)(cfg.HistoryWindow),
+			DisableEventCompaction:    cfg.DisableEventCompaction,
 			Instruction:               cfg.Instruction,
 			InstructionProvider:       llminternal.InstructionProvider(cfg.InstructionProvider),
 			GlobalInstruction:         cfg.GlobalInstruction,
 			GlobalInstructionProvider: llminternal.InstructionProvider(cfg.GlobalInstructionProvider),
 			OutputKey:                 cfg.OutputKey,
+			CodeExecutor:              cfg.CodeExecutor,
+			Planner:                   cfg.Planner,
+			MaxOutputContinuations:    cfg.MaxOutputContinuations,
+			FailOnTruncatedOutput:     cfg.FailOnTruncatedOutput,
+			OutputValidator:           cfg.OutputValidator,
+			MaxOutputRepairs:          cfg.MaxOutputRepairs,
 		},
 	}
 
@@ -156,6 +179,15 @@ type Config struct {
 	// This is the ideal place to log model responses, collect metrics on token
 	// usage, or perform post-processing on the raw `LLMResponse`.
 	AfterModelCallbacks []AfterModelCallback
+	// OnModelErrorCallbacks will be called in the order they are provided when
+	// the model call fails, until there's a callback that returns a non-nil
+	// LLMResponse or error. A returned response is used in place of the error,
+	// e.g. to degrade gracefully with a canned reply; a returned error replaces
+	// the model error. If all the callbacks return (nil, nil), the model error
+	// is propagated.
+	//
+	// The AfterModelCallbacks are called with the outcome.
+	OnModelErrorCallbacks []OnModelErrorCallback
 
 	// Instruction is set for the LLM model guiding the agent's behavior.
 	//
@@ -212,14 +244,21 @@ type Config struct {
 
 	// Whether to include contents (conversation history) in the model request.
 	IncludeContents IncludeContents
+	// HistoryWindow limits the included conversation history to its most
+	// recent part. By default, the whole history is included.
+	HistoryWindow *HistoryWindow
+	// DisableEventCompaction makes the agent receive the events compacted by
+	// the runner, instead of their summaries. See runner.Config.Compaction.
+	DisableEventCompaction bool
 
 	// TODO(ngeorgy): consider to switch to jsonschema for input and output schema.
 	// The input schema when agent is used as a tool.
 	InputSchema *genai.Schema
 	// The output schema when agent replies.
 	//
-	// NOTE: when this is set, agent can only reply and cannot use any tools,
-	// such as function tools, RAGs, agent transfer, etc.
+	// The agent can still use tools: with the models which do not accept a
+	// response schema along with tools, the agent provides its final reply
+	// by calling a set_model_response tool taking the schema as parameters.
 	OutputSchema *genai.Schema
 
 	// Callbacks are executed in the order they are provided.
@@ -244,6 +283,25 @@ type Config struct {
 	//   - If a callback returns (nil, nil), the execution continues to the next [AfterToolCallback]
 	//     in the sequence.
 	AfterToolCallbacks []AfterToolCallback
+	// OnToolErrorCallbacks are called in the order they are provided when the
+	// tool's Run method fails, until there's a callback that returns a
+	// non-nil result or error.
+	//   - If a callback returns (map[string]any, nil), it is used as the result
+	//     of the tool call in place of the error.
+	//   - If a callback returns (nil, error), the returned error replaces the
+	//     tool error. Like the tool error, it is reported to the model as the
+	//     result of the call: it does not fail the agent run.
+	//   - If all the callbacks return (nil, nil), the tool error is reported to
+	//     the model.
+	//
+	// The AfterToolCallbacks are called with the outcome, i.e. the substituted
+	// result or the remaining error.
+	OnToolErrorCallbacks []OnToolErrorCallback
+	// ToolConfirmationPolicy decides which tool calls must be confirmed by a
+	// human before they run, e.g. refunds above some amount, in addition to
+	// the tools implementing tool.ConfirmableTool. See package
+	// toolconfirmation.
+	ToolConfirmationPolicy tool.ConfirmationPolicy
 	// Toolsets will be used by llmagent to extract tools and pass to the
 	// underlying LLM.
 	Toolsets []tool.Toolset
@@ -254,6 +312,51 @@ type Config struct {
 	// - Extracts agent reply for later use, such as in tools, callbacks, etc.
 	// - Connects agents to coordinate with each other.
 	OutputKey string
+	// OutputValidator validates the final text reply of the agent and returns
+	// its decoded value, saved under OutputKey in place of the text. See
+	// WithOutput for typed structured outputs.
+	//
+	// When the validation fails, the model is asked to repair its reply with
+	// the validation error, up to MaxOutputRepairs times. If the reply is
+	// still invalid, it is yielded and the run fails with
+	// model.ErrInvalidOutput.
+	OutputValidator func(output string) (any, error)
+	// MaxOutputRepairs is the number of times the model is asked to repair a
+	// reply rejected by OutputValidator or, for an agent with OutputSchema
+	// and tools replying with the set_model_response tool, a response not
+	// matching the schema.
+	MaxOutputRepairs int
+
+	// CodeExecutor executes the code blocks in the model responses.
+	//
+	// With codeexecutor.BuiltIn, the model's native code execution is used.
+	// With other executors, the code is run by ADK, the files it generates are
+	// saved as artifacts and the execution result is sent back to the model.
+	CodeExecutor codeexecutor.CodeExecutor
+
+	// Planner makes the agent plan and reason before it acts, see
+	// planner.NewPlanReAct and planner.NewBuiltIn.
+	//
+	// The planning and reasoning are marked as thoughts, and thoughts are not
+	// sent back to the model in later requests.
+	Planner planner.Planner
+
+	// MaxOutputContinuations is the number of times the model is asked to
+	// continue a response cut off because it reached the maximum number of
+	// output tokens (finish reason MAX_TOKENS). The continued chunks are
+	// stitched into a single response event.
+	//
+	// If it is zero, the truncated response is yielded as a final response,
+	// unless FailOnTruncatedOutput is set. If the response is still
+	// truncated after the continuations, the truncated response is yielded
+	// and the run fails with model.ErrOutputTruncated.
+	MaxOutputContinuations int
+
+	// FailOnTruncatedOutput makes the run fail with model.ErrOutputTruncated
+	// when a response is truncated and there are no continuations left,
+	// including when MaxOutputContinuations is zero. The truncated response
+	// is yielded before the error.
+	FailOnTruncatedOutput bool
 }
 
 // BeforeModelCallback that is called before sending a request to the model.
@@ -268,6 +371,13 @@ type BeforeModelCallback func(ctx agent.CallbackContext, llmRequest *model.LLMRe
 // is replaced with the returned response/error.
 type AfterModelCallback func(ctx agent.CallbackContext, llmResponse *model.LLMResponse, llmResponseError error) (*model.LLMResponse, error)
 
+// OnModelErrorCallback is called when the model call fails, with the error
+// returned by the model.
+//
+// If it returns non-nil LLMResponse or error, the model error is replaced
+// with the returned response/error.
+type OnModelErrorCallback func(ctx agent.CallbackContext, llmRequest *model.LLMRequest, err error) (*model.LLMResponse, error)
+
 // BeforeToolCallback is a function type executed before a tool's Run method is invoked.
 //
 // Parameters:
@@ -287,6 +397,17 @@ type BeforeToolCallback func(ctx tool.Context, tool tool.Tool, args map[string]a
 //   - err:    The error returned by the tool's Run method.
 type AfterToolCallback func(ctx tool.Context, tool tool.Tool, args map[string]any, result map[string]any, err error) (map[string]any, error)
 
+// OnToolErrorCallback is a function type executed when a tool's Run method
+// returns an error.
+//
+// Parameters:
+//   - ctx:  The tool.Context for the tool execution.
+//   - tool: The tool.Tool instance that failed.
+//   - args: The arguments originally passed to the tool.
+//   - err:  The error returned by the tool's Run method, e.g. a
+//     *functiontool.PanicError if the function panicked.
+type OnToolErrorCallback func(ctx tool.Context, tool tool.Tool, args map[string]any, err error) (map[string]any, error)
+
 // IncludeContents controls what parts of prior conversation history is received by llmagent.
 type IncludeContents string
 
@@ -297,6 +418,26 @@ const (
 	IncludeContentsDefault IncludeContents = "default"
 )
 
+// HistoryWindow limits the conversation history received by llmagent with
+// IncludeContentsDefault to its most recent part. The limits that are set
+// all apply.
+//
+// Function calls and their responses are kept or dropped together, and the
+// latest ones are kept even if they exceed the limits.
+type HistoryWindow struct {
+	// MaxTurns is the number of most recent turns kept. Each user message
+	// starts a turn.
+	MaxTurns int
+	// MaxEvents is the number of most recent events kept.
+	MaxEvents int
+	// MaxTokens is the token budget of the kept contents.
+	MaxTokens int
+	// TokenCounter counts the tokens of the contents for MaxTokens. Defaults
+	// to model.HeuristicTokenCounter. The Gemini models implement
+	// model.TokenCounter with the CountTokens API.
+	TokenCounter model.TokenCounter
+}
+
 type llmAgent struct {
 	agent.Agent
 	llminternal.State
@@ -310,6 +451,11 @@ type llmAgent struct {
 	beforeToolCallbacks []llminternal.BeforeToolCallback
 	afterToolCallbacks  []llminternal.AfterToolCallback
 
+	onModelErrorCallbacks []llminternal.OnModelErrorCallback
+	onToolErrorCallbacks  []llminternal.OnToolErrorCallback
+
+	toolConfirmationPolicy tool.ConfirmationPolicy
+
 	inputSchema  *genai.Schema
 	outputSchema *genai.Schema
 }
@@ -319,23 +465,27 @@ type agentState = agentinternal.State
 func (a *llmAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	// TODO: branch context?
 	ctx = icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
-		Artifacts:   ctx.Artifacts(),
-		Memory:      ctx.Memory(),
-		Session:     ctx.Session(),
-		Branch:      ctx.Branch(),
-		Agent:       a,
-		UserContent: ctx.UserContent(),
-		RunConfig:   ctx.RunConfig(),
+		InvocationID: ctx.InvocationID(),
+		Artifacts:    ctx.Artifacts(),
+		Memory:       ctx.Memory(),
+		Session:      ctx.Session(),
+		Branch:       ctx.Branch(),
+		Agent:        a,
+		UserContent:  ctx.UserContent(),
+		RunConfig:    ctx.RunConfig(),
 	})
 
 	f := &llminternal.Flow{
-		Model:                a.model,
-		RequestProcessors:    llminternal.DefaultRequestProcessors,
-		ResponseProcessors:   llminternal.DefaultResponseProcessors,
-		BeforeModelCallbacks: a.beforeModelCallbacks,
-		AfterModelCallbacks:  a.afterModelCallbacks,
-		BeforeToolCallbacks:  a.beforeToolCallbacks,
-		AfterToolCallbacks:   a.afterToolCallbacks,
+		Model:                  a.model,
+		RequestProcessors:      llminternal.DefaultRequestProcessors,
+		ResponseProcessors:     llminternal.DefaultResponseProcessors,
+		BeforeModelCallbacks:   a.beforeModelCallbacks,
+		AfterModelCallbacks:    a.afterModelCallbacks,
+		BeforeToolCallbacks:    a.beforeToolCallbacks,
+		AfterToolCallbacks:     a.afterToolCallbacks,
+		OnModelErrorCallbacks:  a.onModelErrorCallbacks,
+		OnToolErrorCallbacks:   a.onToolErrorCallbacks,
+		ToolConfirmationPolicy: a.toolConfirmationPolicy,
 	}
 
 	return func(yield func(*session.Event, error) bool) {
@@ -365,14 +515,24 @@ func (a *llmAgent) maybeSaveOutputToState(event *session.Event) {
 				sb.WriteString(part.Text)
 			}
 		}
-		result := sb.String()
+		var result any = sb.String()
 
-		// TODO: add output schema validation and unmarshalling
-		if a.OutputSchema != nil {
+		if a.OutputValidator != nil {
+			if strings.TrimSpace(sb.String()) == "" {
+				return
+			}
+			// The invalid replies are not saved. The flow fails on them.
+			v, err := a.OutputValidator(sb.String())
+			if err != nil {
+				return
+			}
+			result = v
+		} else if a.OutputSchema != nil {
+			// TODO: add output schema validation and unmarshalling
 			// If the result from the final chunk is just whitespace or empty,
 			// it means this is an empty final chunk of a stream.
 			// Do not attempt to parse it as JSON.
-			if strings.TrimSpace(result) == "" {
+			if strings.TrimSpace(sb.String()) == "" {
 				return
 			}
 		}
@@ -0,0 +1,307 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/auth"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+// newOAuthServer returns a server acting as an OAuth2 provider and as the
+// API protected by it, and the number of tokens it issued.
+func newOAuthServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var tokens atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok {
+			clientID, clientSecret = r.Form.Get("client_id"), r.Form.Get("client_secret")
+		}
+		if clientID != "client-id" || clientSecret != "client-secret" {
+			http.Error(w, "invalid client", http.StatusUnauthorized)
+			return
+		}
+		if r.Form.Get("grant_type") != "authorization_code" || r.Form.Get("code") != "good-code" {
+			http.Error(w, "invalid grant", http.StatusBadRequest)
+			return
+		}
+		tokens.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"access-token","token_type":"Bearer","refresh_token":"refresh-token","expires_in":3600}`)
+	})
+	mux.HandleFunc("/api/data", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "secret data")
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &tokens
+}
+
+func TestToolAuth(t *testing.T) {
+	ctx := t.Context()
+	appName, userID, sessionID := "testApp", "testUser", "testSession"
+	server, tokens := newOAuthServer(t)
+
+	authConfig := &auth.Config{
+		Scheme: &auth.Scheme{
+			Type:             auth.SchemeTypeOAuth2AuthorizationCode,
+			AuthorizationURL: server.URL + "/authorize",
+			TokenURL:         server.URL + "/token",
+			Scopes:           []string{"data.read"},
+		},
+		RawCredential: &auth.Credential{OAuth2: &auth.OAuth2{
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+			RedirectURI:  "https://example.com/callback",
+		}},
+	}
+	type Args struct{}
+	dataTool, err := functiontool.New(functiontool.Config{
+		Name:        "get_data",
+		Description: "returns the user data",
+	}, func(ctx tool.Context, args Args) map[string]string {
+		cred, err := ctx.Credential(authConfig)
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		if cred == nil {
+			if err := ctx.RequestCredential(authConfig); err != nil {
+				return map[string]string{"error": err.Error()}
+			}
+			return map[string]string{"status": "pending user authorization"}
+		}
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/data", nil)
+		req.Header.Set("Authorization", "Bearer "+cred.Token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return map[string]string{"data": string(body)}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("get_data", map[string]any{}, genai.RoleModel),
+			genai.NewContentFromText("Your data is: secret data", genai.RoleModel),
+			// Later invocations, in the same session and in a new one.
+			genai.NewContentFromFunctionCall("get_data", map[string]any{}, genai.RoleModel),
+			genai.NewContentFromText("Still: secret data", genai.RoleModel),
+			genai.NewContentFromFunctionCall("get_data", map[string]any{}, genai.RoleModel),
+			genai.NewContentFromText("Still: secret data", genai.RoleModel),
+			genai.NewContentFromFunctionCall("get_data", map[string]any{}, genai.RoleModel),
+			genai.NewContentFromText("Still: secret data", genai.RoleModel),
+		},
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "auth_agent",
+		Model: mockModel,
+		Tools: []tool.Tool{dataTool},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: appName, UserID: userID, SessionID: sessionID}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := runner.New(runner.Config{AppName: appName, Agent: a, SessionService: sessionService})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first invocation ends with a credential request.
+	var requestEvent *session.Event
+	for ev, err := range r.Run(ctx, userID, sessionID, genai.NewContentFromText("show my data", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		requestEvent = ev
+	}
+	if requestEvent == nil || len(requestEvent.Content.Parts) != 1 || requestEvent.Content.Parts[0].FunctionCall == nil {
+		t.Fatalf("last event = %v, want a credential request", requestEvent)
+	}
+	call := requestEvent.Content.Parts[0].FunctionCall
+	if call.Name != auth.RequestCredentialFunctionName {
+		t.Fatalf("last event calls %q, want %q", call.Name, auth.RequestCredentialFunctionName)
+	}
+	if len(requestEvent.LongRunningToolIDs) != 1 || requestEvent.LongRunningToolIDs[0] != call.ID {
+		t.Errorf("LongRunningToolIDs = %v, want [%s]", requestEvent.LongRunningToolIDs, call.ID)
+	}
+	if b, _ := json.Marshal(call.Args); strings.Contains(string(b), "client-secret") {
+		t.Errorf("credential request contains the client secret: %s", b)
+	}
+	var args auth.RequestCredentialArgs
+	remarshal(t, call.Args, &args)
+	if args.FunctionCallID == "" {
+		t.Errorf("credential request has no function call ID")
+	}
+	exchanged := args.AuthConfig.ExchangedCredential
+	if exchanged == nil || exchanged.OAuth2 == nil || exchanged.OAuth2.State == "" {
+		t.Fatalf("credential request has no oauth2 state: %+v", args.AuthConfig)
+	}
+	authURI, err := url.Parse(exchanged.OAuth2.AuthURI)
+	if err != nil {
+		t.Fatalf("invalid auth URI: %v", err)
+	}
+	if q := authURI.Query(); q.Get("client_id") != "client-id" || q.Get("state") != exchanged.OAuth2.State {
+		t.Errorf("auth URI = %q, want client ID and state", exchanged.OAuth2.AuthURI)
+	}
+
+	// The user grants access, the client replies with the auth response URI.
+	exchanged.OAuth2.AuthResponseURI = "https://example.com/callback?code=good-code&state=" + exchanged.OAuth2.State
+	newReply := func(cfg *auth.Config) *genai.Content {
+		var response map[string]any
+		remarshal(t, cfg, &response)
+		return &genai.Content{
+			Role: genai.RoleUser,
+			Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+				ID:       call.ID,
+				Name:     auth.RequestCredentialFunctionName,
+				Response: response,
+			}}},
+		}
+	}
+
+	// The client cannot redirect the exchange or store the credential under
+	// another key.
+	for _, tamper := range []func(cfg *auth.Config){
+		func(cfg *auth.Config) { cfg.Scheme.TokenURL = "https://attacker.example.com/token" },
+		func(cfg *auth.Config) { cfg.CredentialKey = "user:other" },
+	} {
+		var tampered auth.Config
+		remarshal(t, args.AuthConfig, &tampered)
+		tamper(&tampered)
+		var runErr error
+		for _, err := range r.Run(ctx, userID, sessionID, newReply(&tampered), agent.RunConfig{}) {
+			if err != nil {
+				runErr = err
+			}
+		}
+		if runErr == nil {
+			t.Errorf("Run() with tampered credential response %+v succeeded, want an error", tampered.Scheme)
+		}
+	}
+	if got := tokens.Load(); got != 0 {
+		t.Fatalf("tokens issued for tampered responses = %d, want 0", got)
+	}
+
+	reply := newReply(args.AuthConfig)
+
+	var texts []string
+	var toolResult map[string]any
+	for ev, err := range r.Run(ctx, userID, sessionID, reply, agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		for _, p := range ev.Content.Parts {
+			if p.FunctionResponse != nil && p.FunctionResponse.Name == "get_data" {
+				toolResult = p.FunctionResponse.Response
+			}
+			if p.Text != "" {
+				texts = append(texts, p.Text)
+			}
+		}
+	}
+	if got, want := fmt.Sprint(toolResult), "map[data:secret data]"; got != want {
+		t.Errorf("resumed tool result = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(texts, ""), "Your data is: secret data"; got != want {
+		t.Errorf("final answer = %q, want %q", got, want)
+	}
+
+	// The model sees the resumed tool result, but not the credential exchange.
+	if len(mockModel.Requests) < 2 {
+		t.Fatalf("model called %d times, want at least 2", len(mockModel.Requests))
+	}
+	history, _ := json.Marshal(mockModel.Requests[1].Contents)
+	if !strings.Contains(string(history), "secret data") {
+		t.Errorf("second request history does not contain the resumed tool result: %s", history)
+	}
+	for _, s := range []string{auth.RequestCredentialFunctionName, "pending user authorization"} {
+		if strings.Contains(string(history), s) {
+			t.Errorf("second request history contains %q: %s", s, history)
+		}
+	}
+
+	// The credential is persisted: the later invocations of the user, in the
+	// same session or in a new one, call the tool without another consent.
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: appName, UserID: userID, SessionID: "otherSession"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{sessionID, sessionID, "otherSession"} {
+		toolResult = nil
+		for ev, err := range r.Run(ctx, userID, id, genai.NewContentFromText("show my data again", genai.RoleUser), agent.RunConfig{}) {
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			for _, p := range ev.Content.Parts {
+				if p.FunctionCall != nil && p.FunctionCall.Name == auth.RequestCredentialFunctionName {
+					t.Errorf("session %s: credential requested again after authorization", id)
+				}
+				if p.FunctionResponse != nil && p.FunctionResponse.Name == "get_data" {
+					toolResult = p.FunctionResponse.Response
+				}
+			}
+		}
+		if got, want := fmt.Sprint(toolResult), "map[data:secret data]"; got != want {
+			t.Errorf("session %s: tool result = %s, want %s", id, got, want)
+		}
+	}
+	if got := tokens.Load(); got != 1 {
+		t.Errorf("tokens issued = %d, want 1", got)
+	}
+}
+
+func remarshal(t *testing.T, from, to any) {
+	t.Helper()
+	b, err := json.Marshal(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, to); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/compaction"
+	"google.golang.org/genai"
+)
+
+func TestEventCompaction(t *testing.T) {
+	text := func(s string, role genai.Role) *genai.Content {
+		return genai.NewContentFromText(s, role)
+	}
+	tests := []struct {
+		name    string
+		disable bool
+		// wantContents are the contents of the third model call.
+		wantContents []*genai.Content
+	}{
+		{
+			name: "summary replaces the compacted invocations",
+			wantContents: []*genai.Content{
+				text("summary of q1 and q2", genai.RoleModel),
+				text("q3", genai.RoleUser),
+			},
+		},
+		{
+			name:    "compaction disabled for the agent",
+			disable: true,
+			wantContents: []*genai.Content{
+				text("q1", genai.RoleUser), text("a1", genai.RoleModel),
+				text("q2", genai.RoleUser), text("a2", genai.RoleModel),
+				text("q3", genai.RoleUser),
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockModel := &testutil.MockModel{Responses: []*genai.Content{
+				text("a1", genai.RoleModel), text("a2", genai.RoleModel), text("a3", genai.RoleModel),
+			}}
+			a, err := llmagent.New(llmagent.Config{Name: "agent", Model: mockModel, DisableEventCompaction: tc.disable})
+			if err != nil {
+				t.Fatal(err)
+			}
+			summarizer, err := compaction.NewLLMSummarizer(compaction.LLMSummarizerConfig{
+				Model: &testutil.MockModel{Responses: []*genai.Content{text("summary of q1 and q2", genai.RoleModel)}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessionService := session.InMemoryService()
+			r, err := runner.New(runner.Config{
+				AppName:        "app",
+				Agent:          a,
+				SessionService: sessionService,
+				Compaction:     &compaction.Config{Summarizer: summarizer, InvocationInterval: 2},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, msg := range []string{"q1", "q2", "q3"} {
+				if _, err := testutil.CollectEvents(r.Run(t.Context(), "user", "session", text(msg, genai.RoleUser), agent.RunConfig{})); err != nil {
+					t.Fatalf("Run(%q) error = %v", msg, err)
+				}
+			}
+
+			if diff := cmp.Diff(tc.wantContents, mockModel.Requests[2].Contents); diff != "" {
+				t.Errorf("third model call contents mismatch (-want +got):\n%s", diff)
+			}
+			resp, err := sessionService.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var compactions int
+			for ev := range resp.Session.Events().All() {
+				if ev.Actions.Compaction != nil {
+					compactions++
+				}
+			}
+			if compactions != 1 {
+				t.Errorf("compaction events = %d, want 1", compactions)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/adk/tool/toolconfirmation"
+	"google.golang.org/genai"
+)
+
+type transferArgs struct {
+	Amount int `json:"amount"`
+}
+
+// newTransferAgent returns an agent calling the transfer tool with amount,
+// then answering with the response of the tool, and the amounts transferred
+// by the tool.
+func newTransferAgent(t *testing.T, amount int, toolCfg functiontool.Config, policy tool.ConfirmationPolicy) (*testutil.TestAgentRunner, *[]int) {
+	t.Helper()
+	var transferred []int
+	toolCfg.Name = "transfer"
+	toolCfg.Description = "transfers money"
+	transfer, err := functiontool.New(toolCfg, func(_ tool.Context, args transferArgs) map[string]any {
+		transferred = append(transferred, args.Amount)
+		return map[string]any{"status": "sent"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name: "agent",
+		Model: &FakeLLM{GenerateContentFunc: func(_ context.Context, req *model.LLMRequest, _ bool) (model.LLMResponse, error) {
+			last := req.Contents[len(req.Contents)-1]
+			for _, p := range last.Parts {
+				if p.FunctionResponse != nil {
+					b, err := json.Marshal(p.FunctionResponse.Response)
+					if err != nil {
+						return model.LLMResponse{}, err
+					}
+					return model.LLMResponse{Content: genai.NewContentFromText(string(b), genai.RoleModel)}, nil
+				}
+			}
+			return model.LLMResponse{Content: genai.NewContentFromFunctionCall("transfer", map[string]any{"amount": float64(amount)}, genai.RoleModel)}, nil
+		}},
+		Tools:                  []tool.Tool{transfer},
+		ToolConfirmationPolicy: policy,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testutil.NewTestAgentRunner(t, a), &transferred
+}
+
+// confirmationRequests returns the confirmation requests of the events.
+func confirmationRequests(t *testing.T, events []*session.Event) []*genai.FunctionCall {
+	t.Helper()
+	var requests []*genai.FunctionCall
+	for _, ev := range events {
+		if ev.Content == nil {
+			continue
+		}
+		for _, p := range ev.Content.Parts {
+			if p.FunctionCall == nil || p.FunctionCall.Name != toolconfirmation.FunctionName {
+				continue
+			}
+			if !slices.Contains(ev.LongRunningToolIDs, p.FunctionCall.ID) {
+				t.Errorf("confirmation request %q is not long-running", p.FunctionCall.ID)
+			}
+			requests = append(requests, p.FunctionCall)
+		}
+	}
+	return requests
+}
+
+func confirmationResponse(id string, confirmation map[string]any) *genai.Content {
+	return &genai.Content{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+		ID:       id,
+		Name:     toolconfirmation.FunctionName,
+		Response: confirmation,
+	}}}}
+}
+
+func TestToolConfirmation(t *testing.T) {
+	tests := []struct {
+		name            string
+		confirmation    map[string]any
+		wantTransferred []int
+		want            []string
+	}{
+		{
+			name:            "confirmed",
+			confirmation:    map[string]any{"confirmed": true},
+			wantTransferred: []int{500},
+			want:            []string{`{"status":"sent"}`},
+		},
+		{
+			name:            "confirmed with edited args",
+			confirmation:    map[string]any{"confirmed": true, "args": map[string]any{"amount": 100}},
+			wantTransferred: []int{100},
+			want:            []string{`{"status":"sent"}`},
+		},
+		{
+			name:         "rejected",
+			confirmation: map[string]any{"confirmed": false},
+			want:         []string{`{"error":"This tool call is rejected."}`},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runner, transferred := newTransferAgent(t, 500, functiontool.Config{
+				RequireConfirmation: true,
+				ConfirmationHint:    "Transfer the money?",
+			}, nil)
+
+			events, err := testutil.CollectEvents(runner.Run(t, "session", "send 500"))
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			requests := confirmationRequests(t, events)
+			if len(requests) != 1 {
+				t.Fatalf("got %d confirmation requests, want 1", len(requests))
+			}
+			var args toolconfirmation.RequestArgs
+			b, _ := json.Marshal(requests[0].Args)
+			if err := json.Unmarshal(b, &args); err != nil {
+				t.Fatal(err)
+			}
+			if args.OriginalFunctionCall == nil || args.OriginalFunctionCall.Name != "transfer" ||
+				args.OriginalFunctionCall.Args["amount"] != float64(500) {
+				t.Errorf("original function call = %+v, want the transfer of 500", args.OriginalFunctionCall)
+			}
+			if args.ToolConfirmation == nil || args.ToolConfirmation.Hint != "Transfer the money?" {
+				t.Errorf("tool confirmation = %+v, want the hint of the tool", args.ToolConfirmation)
+			}
+			if len(*transferred) != 0 {
+				t.Fatalf("tool ran before the confirmation: %v", *transferred)
+			}
+
+			got, err := testutil.CollectTextParts(runner.RunContent(t, "session", confirmationResponse(requests[0].ID, tc.confirmation)))
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Run() texts mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantTransferred, *transferred); diff != "" {
+				t.Errorf("transferred amounts mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToolConfirmation_Policy(t *testing.T) {
+	policy := func(_ tool.Context, t tool.Tool, args map[string]any) (string, bool) {
+		amount, _ := args["amount"].(float64)
+		return "Large transfer.", t.Name() == "transfer" && amount > 100
+	}
+	tests := []struct {
+		name             string
+		amount           int
+		wantConfirmation bool
+	}{
+		{name: "small amount", amount: 50},
+		{name: "large amount", amount: 500, wantConfirmation: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runner, transferred := newTransferAgent(t, tc.amount, functiontool.Config{}, policy)
+
+			events, err := testutil.CollectEvents(runner.Run(t, "session", "send"))
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			requests := confirmationRequests(t, events)
+			if got := len(requests) > 0; got != tc.wantConfirmation {
+				t.Errorf("confirmation requested = %v, want %v", got, tc.wantConfirmation)
+			}
+			if got := len(*transferred) > 0; got == tc.wantConfirmation {
+				t.Errorf("tool ran = %v, want %v", got, !tc.wantConfirmation)
+			}
+		})
+	}
+}
+
+func TestToolConfirmation_Replay(t *testing.T) {
+	runner, transferred := newTransferAgent(t, 500, functiontool.Config{RequireConfirmation: true}, nil)
+
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "send 500"))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	requests := confirmationRequests(t, events)
+	if len(requests) != 1 {
+		t.Fatalf("got %d confirmation requests, want 1", len(requests))
+	}
+	// The confirmation sent again does not run the tool again.
+	for range 2 {
+		if _, err := testutil.CollectEvents(runner.RunContent(t, "session", confirmationResponse(requests[0].ID, map[string]any{"confirmed": true}))); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+	if diff := cmp.Diff([]int{500}, *transferred); diff != "" {
+		t.Errorf("transferred amounts mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+func TestOnModelErrorCallbacks(t *testing.T) {
+	errBackend := errors.New("backend unavailable")
+	errRethrown := errors.New("rethrown")
+
+	tests := []struct {
+		name      string
+		callbacks []llmagent.OnModelErrorCallback
+		want      []string
+		wantErr   error
+	}{
+		{
+			name: "response substituted",
+			callbacks: []llmagent.OnModelErrorCallback{
+				func(ctx agent.CallbackContext, req *model.LLMRequest, err error) (*model.LLMResponse, error) {
+					return nil, nil
+				},
+				func(ctx agent.CallbackContext, req *model.LLMRequest, err error) (*model.LLMResponse, error) {
+					if !errors.Is(err, errBackend) {
+						t.Errorf("OnModelErrorCallback error = %v, want %v", err, errBackend)
+					}
+					return &model.LLMResponse{Content: genai.NewContentFromText("try again later", genai.RoleModel)}, nil
+				},
+			},
+			want: []string{"try again later"},
+		},
+		{
+			name: "error rethrown",
+			callbacks: []llmagent.OnModelErrorCallback{
+				func(ctx agent.CallbackContext, req *model.LLMRequest, err error) (*model.LLMResponse, error) {
+					return nil, errRethrown
+				},
+			},
+			wantErr: errRethrown,
+		},
+		{
+			name: "error propagated",
+			callbacks: []llmagent.OnModelErrorCallback{
+				func(ctx agent.CallbackContext, req *model.LLMRequest, err error) (*model.LLMResponse, error) {
+					return nil, nil
+				},
+			},
+			wantErr: errBackend,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := llmagent.New(llmagent.Config{
+				Name: "agent",
+				Model: &FakeLLM{GenerateContentFunc: func(context.Context, *model.LLMRequest, bool) (model.LLMResponse, error) {
+					return model.LLMResponse{}, errBackend
+				}},
+				OnModelErrorCallbacks: tc.callbacks,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runner := testutil.NewTestAgentRunner(t, a)
+
+			got, err := testutil.CollectTextParts(runner.Run(t, "session", "hi"))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Run() error = %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Run() texts mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOnToolErrorCallbacks(t *testing.T) {
+	type Args struct {
+		Country string `json:"country"`
+	}
+	flaky, err := functiontool.New(functiontool.Config{Name: "lookup", Description: "looks up the capital"}, func(_ tool.Context, args Args) map[string]any {
+		panic("connection reset")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		callbacks []llmagent.OnToolErrorCallback
+		// afterResult is the result returned by the AfterToolCallback.
+		afterResult map[string]any
+		// wantAfterResult and wantAfterErr are the outcome the
+		// AfterToolCallback is called with.
+		wantAfterResult map[string]any
+		wantAfterErr    string
+		want            map[string]any
+	}{
+		{
+			name: "result substituted",
+			callbacks: []llmagent.OnToolErrorCallback{
+				func(ctx tool.Context, tool tool.Tool, args map[string]any, err error) (map[string]any, error) {
+					var panicErr *functiontool.PanicError
+					if !errors.As(err, &panicErr) || panicErr.Value != "connection reset" {
+						t.Errorf("OnToolErrorCallback error = %v, want the panic of the tool", err)
+					}
+					return map[string]any{"capital": "unknown"}, nil
+				},
+			},
+			wantAfterResult: map[string]any{"capital": "unknown"},
+			want:            map[string]any{"capital": "unknown"},
+		},
+		{
+			name: "error replaced",
+			callbacks: []llmagent.OnToolErrorCallback{
+				func(ctx tool.Context, tool tool.Tool, args map[string]any, err error) (map[string]any, error) {
+					return nil, errors.New("lookup is down")
+				},
+			},
+			wantAfterErr: "lookup is down",
+			want:         map[string]any{"error": `tool "lookup" failed: lookup is down`},
+		},
+		{
+			name:         "error reported",
+			wantAfterErr: `tool "lookup" panicked: connection reset`,
+			want:         map[string]any{"error": `tool "lookup" failed: tool "lookup" panicked: connection reset`},
+		},
+		{
+			name:         "error replaced by the AfterToolCallback",
+			afterResult:  map[string]any{"capital": "Paris"},
+			wantAfterErr: `tool "lookup" panicked: connection reset`,
+			want:         map[string]any{"capital": "Paris"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []*model.LLMRequest
+			afterCalls := 0
+			a, err := llmagent.New(llmagent.Config{
+				Name: "agent",
+				Model: &FakeLLM{GenerateContentFunc: func(_ context.Context, req *model.LLMRequest, _ bool) (model.LLMResponse, error) {
+					requests = append(requests, req)
+					if len(requests) == 1 {
+						return model.LLMResponse{Content: genai.NewContentFromFunctionCall("lookup", map[string]any{"country": "France"}, genai.RoleModel)}, nil
+					}
+					return model.LLMResponse{Content: genai.NewContentFromText("done", genai.RoleModel)}, nil
+				}},
+				Tools:                []tool.Tool{flaky},
+				OnToolErrorCallbacks: tc.callbacks,
+				AfterToolCallbacks: []llmagent.AfterToolCallback{
+					func(ctx tool.Context, tool tool.Tool, args, result map[string]any, err error) (map[string]any, error) {
+						afterCalls++
+						gotErr := ""
+						if err != nil {
+							gotErr = err.Error()
+						}
+						if gotErr != tc.wantAfterErr {
+							t.Errorf("AfterToolCallback error = %q, want %q", gotErr, tc.wantAfterErr)
+						}
+						if diff := cmp.Diff(tc.wantAfterResult, result); diff != "" {
+							t.Errorf("AfterToolCallback result mismatch (-want +got):\n%s", diff)
+						}
+						return tc.afterResult, nil
+					},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runner := testutil.NewTestAgentRunner(t, a)
+
+			if _, err := testutil.CollectEvents(runner.Run(t, "session", "France?")); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(requests) != 2 {
+				t.Fatalf("model requests = %d, want 2", len(requests))
+			}
+			if afterCalls != 1 {
+				t.Errorf("AfterToolCallback calls = %d, want 1", afterCalls)
+			}
+			contents := requests[1].Contents
+			fr := contents[len(contents)-1].Parts[0].FunctionResponse
+			if fr == nil {
+				t.Fatalf("last content = %+v, want the function response", contents[len(contents)-1])
+			}
+			// The error is reported as a string to the model.
+			got := make(map[string]any)
+			for k, v := range fr.Response {
+				if err, ok := v.(error); ok {
+					v = err.Error()
+				}
+				got[k] = v
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("function response mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+func TestInvocationLimits(t *testing.T) {
+	type Args struct{}
+	type Result struct{}
+
+	newPingTool := func(t *testing.T, delay time.Duration) tool.Tool {
+		t.Helper()
+		ping, err := functiontool.New(functiontool.Config{Name: "ping", Description: "pings"}, func(tool.Context, Args) Result {
+			time.Sleep(delay)
+			return Result{}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ping
+	}
+	// newLoopingAgent returns an agent whose model calls the tool forever.
+	newLoopingAgent := func(t *testing.T, name string, toolDelay time.Duration) (agent.Agent, *testutil.MockModel) {
+		t.Helper()
+		mockModel := &testutil.MockModel{}
+		for range 100 {
+			mockModel.Responses = append(mockModel.Responses, genai.NewContentFromFunctionCall("ping", nil, genai.RoleModel))
+		}
+		a, err := llmagent.New(llmagent.Config{
+			Name:  name,
+			Model: mockModel,
+			Tools: []tool.Tool{newPingTool(t, toolDelay)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a, mockModel
+	}
+
+	tests := []struct {
+		name         string
+		cfg          agent.RunConfig
+		toolDelay    time.Duration
+		sequential   bool
+		wantCode     string
+		wantLLMCalls int
+	}{
+		{
+			name:         "max LLM calls",
+			cfg:          agent.RunConfig{MaxLLMCalls: 3},
+			wantCode:     agent.ErrorCodeMaxLLMCallsExceeded,
+			wantLLMCalls: 3,
+		},
+		{
+			name:         "max tool calls",
+			cfg:          agent.RunConfig{MaxToolCalls: 2},
+			wantCode:     agent.ErrorCodeMaxToolCallsExceeded,
+			wantLLMCalls: 3,
+		},
+		{
+			name:         "max duration",
+			cfg:          agent.RunConfig{MaxDuration: 150 * time.Millisecond},
+			toolDelay:    60 * time.Millisecond,
+			wantCode:     agent.ErrorCodeMaxDurationExceeded,
+			wantLLMCalls: 3,
+		},
+		{
+			name:         "limits shared by sub-agents",
+			cfg:          agent.RunConfig{MaxLLMCalls: 3},
+			sequential:   true,
+			wantCode:     agent.ErrorCodeMaxLLMCallsExceeded,
+			wantLLMCalls: 3,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root, mockModel := newLoopingAgent(t, "looping_agent", tc.toolDelay)
+			if tc.sequential {
+				// The first sub-agent makes two model calls, the second one
+				// exceeds the limit.
+				first, err := llmagent.New(llmagent.Config{
+					Name: "first_agent",
+					Model: &testutil.MockModel{Responses: []*genai.Content{
+						genai.NewContentFromFunctionCall("ping", nil, genai.RoleModel),
+						genai.NewContentFromText("done", genai.RoleModel),
+					}},
+					Tools: []tool.Tool{newPingTool(t, 0)},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				root, err = sequentialagent.New(sequentialagent.Config{
+					AgentConfig: agent.Config{Name: "sequential_agent", SubAgents: []agent.Agent{first, root}},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				tc.wantLLMCalls = 1
+			}
+
+			runner := testutil.NewTestAgentRunner(t, root)
+			var last *session.Event
+			for ev, err := range runner.RunContentWithConfig(t, "session", genai.NewContentFromText("ping", genai.RoleUser), tc.cfg) {
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				last = ev
+			}
+			if last == nil {
+				t.Fatal("Run() returned no events")
+			}
+			if last.ErrorCode != tc.wantCode || last.ErrorMessage == "" {
+				t.Errorf("last event error = (%q, %q), want code %q", last.ErrorCode, last.ErrorMessage, tc.wantCode)
+			}
+			if last.Author != "looping_agent" {
+				t.Errorf("last event author = %q, want %q", last.Author, "looping_agent")
+			}
+			if got := len(mockModel.Requests); got != tc.wantLLMCalls {
+				t.Errorf("model calls = %d, want %d", got, tc.wantLLMCalls)
+			}
+		})
+	}
+}
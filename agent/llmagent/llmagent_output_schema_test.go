@@ -0,0 +1,234 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+// namedLLM is a FakeLLM with another name.
+type namedLLM struct {
+	*FakeLLM
+	name string
+}
+
+func (m *namedLLM) Name() string { return m.name }
+
+func TestOutputSchemaWithTools(t *testing.T) {
+	type Args struct {
+		Country string `json:"country"`
+	}
+	type Result struct {
+		City string `json:"city"`
+	}
+	lookup, err := functiontool.New(functiontool.Config{Name: "lookup_capital", Description: "looks up the capital of a country"}, func(_ tool.Context, args Args) Result {
+		return Result{City: "Paris"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"country": {Type: genai.TypeString},
+			"city":    {Type: genai.TypeString},
+		},
+		Required: []string{"country", "city"},
+	}
+	toolNames := func(req *model.LLMRequest) []string {
+		var names []string
+		for _, t := range req.Config.Tools {
+			for _, decl := range t.FunctionDeclarations {
+				names = append(names, decl.Name)
+			}
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	t.Run("set_model_response", func(t *testing.T) {
+		var requests []*model.LLMRequest
+		// The model looks up the capital, sets an invalid response, then
+		// repairs it.
+		replies := []*genai.Content{
+			genai.NewContentFromFunctionCall("lookup_capital", map[string]any{"country": "France"}, genai.RoleModel),
+			genai.NewContentFromFunctionCall("set_model_response", map[string]any{"country": "France"}, genai.RoleModel),
+			genai.NewContentFromFunctionCall("set_model_response", map[string]any{"country": "France", "city": "Paris"}, genai.RoleModel),
+		}
+		fakeModel := &FakeLLM{GenerateContentFunc: func(_ context.Context, req *model.LLMRequest, _ bool) (model.LLMResponse, error) {
+			requests = append(requests, req)
+			return model.LLMResponse{Content: replies[len(requests)-1]}, nil
+		}}
+		a, err := llmagent.New(llmagent.Config{
+			Name:             "capital_agent",
+			Model:            fakeModel,
+			Tools:            []tool.Tool{lookup},
+			OutputSchema:     schema,
+			OutputKey:        "capital",
+			MaxOutputRepairs: 1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		runner := testutil.NewTestAgentRunner(t, a)
+
+		events, err := testutil.CollectEvents(runner.Run(t, "session", "France?"))
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if len(requests) != 3 {
+			t.Fatalf("model requests = %d, want 3", len(requests))
+		}
+		req := requests[0]
+		if req.Config.ResponseSchema != nil || req.Config.ResponseMIMEType != "" {
+			t.Errorf("request config = %+v, want no response schema", req.Config)
+		}
+		if diff := cmp.Diff([]string{"lookup_capital", "set_model_response"}, toolNames(req)); diff != "" {
+			t.Errorf("request tools mismatch (-want +got):\n%s", diff)
+		}
+		// The invalid response is sent back to the model as an error.
+		invalid := requests[2].Contents[len(requests[2].Contents)-1].Parts[0].FunctionResponse
+		if invalid == nil || invalid.Response["error"] == nil {
+			t.Errorf("function response to the invalid response = %+v, want an error", invalid)
+		}
+
+		last := events[len(events)-1]
+		if !last.IsFinalResponse() || last.Author != "capital_agent" {
+			t.Fatalf("last event = %+v, want the final response of the agent", last)
+		}
+		var got map[string]any
+		if err := json.Unmarshal([]byte(last.Content.Parts[0].Text), &got); err != nil {
+			t.Fatalf("final response is not JSON: %v", err)
+		}
+		want := map[string]any{"country": "France", "city": "Paris"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("final response mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(last.Content.Parts[0].Text, last.Actions.StateDelta["capital"]); diff != "" {
+			t.Errorf("saved output mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("repairs exhausted", func(t *testing.T) {
+		var requests int
+		fakeModel := &FakeLLM{GenerateContentFunc: func(_ context.Context, req *model.LLMRequest, _ bool) (model.LLMResponse, error) {
+			requests++
+			return model.LLMResponse{Content: genai.NewContentFromFunctionCall("set_model_response", map[string]any{"country": "France"}, genai.RoleModel)}, nil
+		}}
+		a, err := llmagent.New(llmagent.Config{
+			Name:             "capital_agent",
+			Model:            fakeModel,
+			Tools:            []tool.Tool{lookup},
+			OutputSchema:     schema,
+			MaxOutputRepairs: 1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		runner := testutil.NewTestAgentRunner(t, a)
+
+		_, err = testutil.CollectEvents(runner.Run(t, "session", "France?"))
+		if !errors.Is(err, model.ErrInvalidOutput) {
+			t.Errorf("Run() error = %v, want %v", err, model.ErrInvalidOutput)
+		}
+		if requests != 2 {
+			t.Errorf("model requests = %d, want 2", requests)
+		}
+	})
+
+	t.Run("non-object schema", func(t *testing.T) {
+		var req *model.LLMRequest
+		fakeModel := &FakeLLM{GenerateContentFunc: func(_ context.Context, r *model.LLMRequest, _ bool) (model.LLMResponse, error) {
+			req = r
+			return model.LLMResponse{Content: genai.NewContentFromFunctionCall("set_model_response", map[string]any{"result": []any{"Paris", "Lyon"}}, genai.RoleModel)}, nil
+		}}
+		a, err := llmagent.New(llmagent.Config{
+			Name:         "cities_agent",
+			Model:        fakeModel,
+			Tools:        []tool.Tool{lookup},
+			OutputSchema: &genai.Schema{Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		runner := testutil.NewTestAgentRunner(t, a)
+
+		events, err := testutil.CollectEvents(runner.Run(t, "session", "Cities of France?"))
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if req.Config.ResponseSchema != nil {
+			t.Errorf("request config = %+v, want no response schema", req.Config)
+		}
+		// The array schema is wrapped in the parameters of the tool.
+		var params *genai.Schema
+		for _, tool := range req.Config.Tools {
+			for _, decl := range tool.FunctionDeclarations {
+				if decl.Name == "set_model_response" {
+					params = decl.Parameters
+				}
+			}
+		}
+		if params == nil || params.Type != genai.TypeObject || params.Properties["result"] == nil || params.Properties["result"].Type != genai.TypeArray {
+			t.Errorf("set_model_response parameters = %+v, want the array schema wrapped in an object", params)
+		}
+		// The final response is unwrapped.
+		last := events[len(events)-1]
+		if got, want := last.Content.Parts[0].Text, `["Paris","Lyon"]`; got != want {
+			t.Errorf("final response = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("native", func(t *testing.T) {
+		// Gemini 2 and above on Vertex AI accept a response schema with tools.
+		t.Setenv("GOOGLE_GENAI_USE_VERTEXAI", "true")
+		var req *model.LLMRequest
+		fakeModel := &namedLLM{name: "gemini-2.5-flash", FakeLLM: &FakeLLM{GenerateContentFunc: func(_ context.Context, r *model.LLMRequest, _ bool) (model.LLMResponse, error) {
+			req = r
+			return model.LLMResponse{Content: genai.NewContentFromText(`{"country": "France", "city": "Paris"}`, genai.RoleModel)}, nil
+		}}}
+		a, err := llmagent.New(llmagent.Config{
+			Name:         "capital_agent",
+			Model:        fakeModel,
+			Tools:        []tool.Tool{lookup},
+			OutputSchema: schema,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		runner := testutil.NewTestAgentRunner(t, a)
+		if _, err := testutil.CollectEvents(runner.Run(t, "session", "France?")); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if req.Config.ResponseSchema != schema || req.Config.ResponseMIMEType != "application/json" {
+			t.Errorf("request config = %+v, want the response schema", req.Config)
+		}
+		if diff := cmp.Diff([]string{"lookup_capital"}, toolNames(req)); diff != "" {
+			t.Errorf("request tools mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+type capital struct {
+	Country string `json:"country"`
+	City    string `json:"city"`
+}
+
+func TestWithOutput(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRepairs   int
+		replies      []string
+		want         *capital
+		wantErr      error
+		wantRequests int
+	}{
+		{
+			name:         "valid",
+			replies:      []string{`{"country": "France", "city": "Paris"}`},
+			want:         &capital{Country: "France", City: "Paris"},
+			wantRequests: 1,
+		},
+		{
+			name:         "code fence",
+			replies:      []string{"```json\n{\"country\": \"France\", \"city\": \"Paris\"}\n```"},
+			want:         &capital{Country: "France", City: "Paris"},
+			wantRequests: 1,
+		},
+		{
+			name:         "repaired",
+			maxRepairs:   2,
+			replies:      []string{`Paris`, `{"country": "France"}`, `{"country": "France", "city": "Paris"}`},
+			want:         &capital{Country: "France", City: "Paris"},
+			wantRequests: 3,
+		},
+		{
+			name:         "repairs exhausted",
+			maxRepairs:   1,
+			replies:      []string{`Paris`, `{"country": "France", "city": 1}`},
+			wantErr:      model.ErrInvalidOutput,
+			wantRequests: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []*model.LLMRequest
+			fakeModel := &FakeLLM{GenerateContentFunc: func(_ context.Context, req *model.LLMRequest, _ bool) (model.LLMResponse, error) {
+				requests = append(requests, req)
+				return model.LLMResponse{Content: genai.NewContentFromText(tc.replies[len(requests)-1], genai.RoleModel)}, nil
+			}}
+			cfg, err := llmagent.WithOutput[capital](llmagent.Config{
+				Name:             "capital_agent",
+				Model:            fakeModel,
+				OutputKey:        "capital",
+				MaxOutputRepairs: tc.maxRepairs,
+			})
+			if err != nil {
+				t.Fatalf("WithOutput() error = %v", err)
+			}
+			a, err := llmagent.New(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessionService := session.InMemoryService()
+			if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s"}); err != nil {
+				t.Fatal(err)
+			}
+			r, err := runner.New(runner.Config{AppName: "app", Agent: a, SessionService: sessionService})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var (
+				texts  []string
+				got    *capital
+				gotErr error
+			)
+			for ev, err := range r.Run(t.Context(), "user", "s", genai.NewContentFromText("France?", genai.RoleUser), agent.RunConfig{}) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				texts = append(texts, ev.Content.Parts[0].Text)
+				if out, ok, err := llmagent.OutputFromEvent[capital](ev, "capital"); err != nil {
+					t.Errorf("OutputFromEvent() error = %v", err)
+				} else if ok {
+					got = &out
+				}
+			}
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Errorf("Run() error = %v, want %v", gotErr, tc.wantErr)
+			}
+			// Only the valid reply, or the last invalid one, is yielded.
+			if diff := cmp.Diff([]string{tc.replies[len(tc.replies)-1]}, texts); diff != "" {
+				t.Errorf("Run() texts mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("OutputFromEvent() mismatch (-want +got):\n%s", diff)
+			}
+			if len(requests) != tc.wantRequests {
+				t.Fatalf("model requests = %d, want %d", len(requests), tc.wantRequests)
+			}
+
+			if cfg := requests[0].Config; cfg.ResponseMIMEType != "application/json" || cfg.ResponseJsonSchema == nil {
+				t.Errorf("request config = %+v, want the JSON response schema", cfg)
+			}
+			if len(requests) > 1 {
+				// The model is asked to repair its invalid reply.
+				contents := requests[len(requests)-1].Contents
+				prev, last := contents[len(contents)-2], contents[len(contents)-1]
+				if prev.Role != genai.RoleModel || prev.Parts[0].Text != tc.replies[len(requests)-2] {
+					t.Errorf("repair request previous content = %+v, want the invalid reply", prev.Parts[0])
+				}
+				if last.Role != genai.RoleUser || !strings.Contains(last.Parts[0].Text, "invalid") {
+					t.Errorf("repair request last content = %+v, want the validation error", last.Parts[0])
+				}
+			}
+
+			resp, err := sessionService.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			saved, err := llmagent.OutputFromState[capital](resp.Session.State(), "capital")
+			if tc.want == nil {
+				if !errors.Is(err, session.ErrStateKeyNotExist) {
+					t.Errorf("OutputFromState() error = %v, want %v", err, session.ErrStateKeyNotExist)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OutputFromState() error = %v", err)
+			}
+			if diff := cmp.Diff(*tc.want, saved); diff != "" {
+				t.Errorf("OutputFromState() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOutputFromEvent_Stored(t *testing.T) {
+	// Once stored, e.g. in a database, the output is held as JSON values.
+	ev := session.NewEvent("invocation")
+	ev.Actions.StateDelta["capital"] = map[string]any{"country": "France", "city": "Paris"}
+
+	got, ok, err := llmagent.OutputFromEvent[capital](ev, "capital")
+	if err != nil || !ok {
+		t.Fatalf("OutputFromEvent() = (%v, %v, %v), want the output", got, ok, err)
+	}
+	if want := (capital{Country: "France", City: "Paris"}); got != want {
+		t.Errorf("OutputFromEvent() = %+v, want %+v", got, want)
+	}
+	if _, ok, err := llmagent.OutputFromEvent[capital](ev, "other"); ok || err != nil {
+		t.Errorf("OutputFromEvent() of a missing key = (%v, %v), want (false, nil)", ok, err)
+	}
+}
+
+func TestWithOutput_OutputSchema(t *testing.T) {
+	_, err := llmagent.WithOutput[capital](llmagent.Config{Name: "a", OutputSchema: &genai.Schema{Type: genai.TypeObject}})
+	if err == nil {
+		t.Error("WithOutput() with OutputSchema succeeded, want an error")
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+// concurrencyTracker records how many tool calls run at the same time.
+type concurrencyTracker struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	running int
+	started int
+	max     int
+}
+
+func newConcurrencyTracker() *concurrencyTracker {
+	c := &concurrencyTracker{}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *concurrencyTracker) start() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running++
+	c.started++
+	c.max = max(c.max, c.running)
+	c.cond.Broadcast()
+	return c.running
+}
+
+// waitStarted blocks until n calls have started.
+func (c *concurrencyTracker) waitStarted(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.started < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *concurrencyTracker) done() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running--
+}
+
+func TestParallelFunctionCalls(t *testing.T) {
+	type Args struct {
+		Key string `json:"key"`
+	}
+	call := func(name, key string) *genai.Part {
+		return genai.NewPartFromFunctionCall(name, map[string]any{"key": key})
+	}
+
+	tests := []struct {
+		name  string
+		cfg   agent.RunConfig
+		calls []*genai.Part
+		// started is, for each key, the number of calls started before its
+		// call returns, so that the calls expected to run concurrently
+		// overlap.
+		started       map[string]int
+		wantMax       int
+		wantOrdered   []string
+		wantConflicts []string
+	}{
+		{
+			name:          "concurrent",
+			calls:         []*genai.Part{call("lookup", "a"), call("lookup", "b"), call("lookup", "c"), call("lookup", "d")},
+			started:       map[string]int{"a": 4, "b": 4, "c": 4, "d": 4},
+			wantMax:       4,
+			wantOrdered:   []string{"a", "b", "c", "d"},
+			wantConflicts: []string{"last"},
+		},
+		{
+			name:          "concurrency limit",
+			cfg:           agent.RunConfig{MaxConcurrentToolCalls: 2},
+			calls:         []*genai.Part{call("lookup", "a"), call("lookup", "b"), call("lookup", "c"), call("lookup", "d")},
+			started:       map[string]int{"a": 2, "b": 2, "c": 4, "d": 4},
+			wantMax:       2,
+			wantOrdered:   []string{"a", "b", "c", "d"},
+			wantConflicts: []string{"last"},
+		},
+		{
+			name:          "sequential tool",
+			calls:         []*genai.Part{call("lookup", "a"), call("lookup", "b"), call("write", "c"), call("lookup", "d")},
+			started:       map[string]int{"a": 2, "b": 2, "c": 3, "d": 4},
+			wantMax:       2,
+			wantOrdered:   []string{"a", "b", "c", "d"},
+			wantConflicts: []string{"last"},
+		},
+		{
+			name:        "single call",
+			calls:       []*genai.Part{call("lookup", "a")},
+			started:     map[string]int{"a": 1},
+			wantMax:     1,
+			wantOrdered: []string{"a"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker := newConcurrencyTracker()
+			handler := func(sequential bool) functiontool.Func[Args, map[string]any] {
+				return func(ctx tool.Context, args Args) map[string]any {
+					if n := tracker.start(); sequential && n != 1 {
+						t.Errorf("sequential tool ran with %d concurrent calls", n)
+					}
+					defer tracker.done()
+					// Wait for the calls expected to run concurrently.
+					tracker.waitStarted(tc.started[args.Key])
+					if err := ctx.State().Set("key_"+args.Key, true); err != nil {
+						t.Error(err)
+					}
+					if err := ctx.State().Set("last", args.Key); err != nil {
+						t.Error(err)
+					}
+					if _, err := ctx.Artifacts().Save(ctx, args.Key+".txt", genai.NewPartFromText(args.Key)); err != nil {
+						t.Error(err)
+					}
+					return map[string]any{"value": args.Key}
+				}
+			}
+			lookup, err := functiontool.New(functiontool.Config{Name: "lookup", Description: "looks up a key"}, handler(false))
+			if err != nil {
+				t.Fatal(err)
+			}
+			write, err := functiontool.New(functiontool.Config{Name: "write", Description: "writes a key", Sequential: true}, handler(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mockModel := &testutil.MockModel{
+				Responses: []*genai.Content{
+					genai.NewContentFromParts(tc.calls, genai.RoleModel),
+					genai.NewContentFromText("done", genai.RoleModel),
+				},
+			}
+			a, err := llmagent.New(llmagent.Config{
+				Name:  "parallel_agent",
+				Model: mockModel,
+				Tools: []tool.Tool{lookup, write},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessionService := session.InMemoryService()
+			r, err := runner.New(runner.Config{AppName: "app", Agent: a, SessionService: sessionService, ArtifactService: artifact.InMemoryService()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+				t.Fatal(err)
+			}
+			events, err := testutil.CollectEvents(r.Run(t.Context(), "user", "session", genai.NewContentFromText("look up", genai.RoleUser), tc.cfg))
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			if tracker.max != tc.wantMax {
+				t.Errorf("max concurrent calls = %d, want %d", tracker.max, tc.wantMax)
+			}
+
+			var fnResponseEvent *session.Event
+			for _, ev := range events {
+				if ev.Content != nil && len(ev.Content.Parts) > 0 && ev.Content.Parts[0].FunctionResponse != nil {
+					fnResponseEvent = ev
+				}
+			}
+			if fnResponseEvent == nil {
+				t.Fatal("no function response event")
+			}
+			var got []string
+			for _, p := range fnResponseEvent.Content.Parts {
+				got = append(got, p.FunctionResponse.Response["value"].(string))
+			}
+			if diff := cmp.Diff(tc.wantOrdered, got); diff != "" {
+				t.Errorf("function responses mismatch (-want +got):\n%s", diff)
+			}
+
+			// The state deltas are merged key by key and the last call wins
+			// on conflicts, regardless of the completion order.
+			wantState := map[string]any{"last": tc.wantOrdered[len(tc.wantOrdered)-1]}
+			wantArtifacts := map[string]int64{}
+			for _, k := range tc.wantOrdered {
+				wantState["key_"+k] = true
+				wantArtifacts[k+".txt"] = 1
+			}
+			if diff := cmp.Diff(wantState, fnResponseEvent.Actions.StateDelta); diff != "" {
+				t.Errorf("state delta mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(wantArtifacts, fnResponseEvent.Actions.ArtifactDelta); diff != "" {
+				t.Errorf("artifact delta mismatch (-want +got):\n%s", diff)
+			}
+			// The conflicting keys are reported.
+			var gotConflicts []string
+			if v, ok := fnResponseEvent.CustomMetadata[agent.StateConflictsMetadataKey]; ok {
+				gotConflicts = v.([]string)
+			}
+			if diff := cmp.Diff(tc.wantConflicts, gotConflicts); diff != "" {
+				t.Errorf("state conflicts mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,463 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/agenttool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+// runWithPlugins runs the agent with the plugins for the message, and
+// returns the texts of the events.
+func runWithPlugins(t *testing.T, a agent.Agent, plugins []*plugin.Plugin, msg string) ([]string, error) {
+	t.Helper()
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s"}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := runner.New(runner.Config{AppName: "app", Agent: a, SessionService: sessionService, Plugins: plugins})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for ev, err := range r.Run(t.Context(), "user", "s", genai.NewContentFromText(msg, genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			return texts, err
+		}
+		if ev.Content != nil && len(ev.Content.Parts) > 0 && ev.Content.Parts[0].Text != "" {
+			texts = append(texts, ev.Content.Parts[0].Text)
+		}
+	}
+	return texts, nil
+}
+
+func TestPlugins_Order(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		log = append(log, s)
+	}
+	first, err := plugin.New(plugin.Config{
+		Name: "first",
+		OnUserMessageCallback: func(ctx agent.InvocationContext, msg *genai.Content) (*genai.Content, error) {
+			record("on_user_message " + ctx.Agent().Name())
+			return nil, nil
+		},
+		BeforeRunCallback: func(ctx agent.InvocationContext) (*genai.Content, error) {
+			record("before_run " + ctx.Agent().Name())
+			return nil, nil
+		},
+		AfterRunCallback: func(ctx agent.InvocationContext) {
+			record("after_run " + ctx.Agent().Name())
+		},
+		OnEventCallback: func(ctx agent.InvocationContext, event *session.Event) (*session.Event, error) {
+			record("on_event " + event.Author)
+			return nil, nil
+		},
+		BeforeAgentCallback: func(ctx agent.CallbackContext) (*genai.Content, error) {
+			record("before_agent " + ctx.AgentName())
+			return nil, nil
+		},
+		AfterAgentCallback: func(ctx agent.CallbackContext) (*genai.Content, error) {
+			record("after_agent " + ctx.AgentName())
+			return nil, nil
+		},
+		BeforeModelCallback: func(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+			record("before_model " + ctx.AgentName())
+			return nil, nil
+		},
+		AfterModelCallback: func(ctx agent.CallbackContext, resp *model.LLMResponse) (*model.LLMResponse, error) {
+			record("after_model " + ctx.AgentName())
+			return nil, nil
+		},
+		BeforeToolCallback: func(ctx tool.Context, tool tool.Tool, args map[string]any) (map[string]any, error) {
+			record("before_tool " + tool.Name())
+			return nil, nil
+		},
+		AfterToolCallback: func(ctx tool.Context, tool tool.Tool, args, result map[string]any) (map[string]any, error) {
+			record("after_tool " + tool.Name())
+			return nil, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := plugin.New(plugin.Config{
+		Name: "second",
+		BeforeModelCallback: func(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+			record("second before_model " + ctx.AgentName())
+			return nil, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	helper, err := llmagent.New(llmagent.Config{
+		Name:  "helper",
+		Model: &FakeLLM{},
+		BeforeModelCallbacks: []llmagent.BeforeModelCallback{func(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+			record("agent before_model " + ctx.AgentName())
+			return nil, nil
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	root, err := llmagent.New(llmagent.Config{
+		Name: "root",
+		Model: &FakeLLM{GenerateContentFunc: func(context.Context, *model.LLMRequest, bool) (model.LLMResponse, error) {
+			calls++
+			if calls == 1 {
+				return model.LLMResponse{Content: genai.NewContentFromFunctionCall("helper", map[string]any{"request": "help"}, genai.RoleModel)}, nil
+			}
+			return model.LLMResponse{Content: genai.NewContentFromText("done", genai.RoleModel)}, nil
+		}},
+		Tools: []tool.Tool{agenttool.New(helper, nil)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runWithPlugins(t, root, []*plugin.Plugin{first, second}, "hi"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := []string{
+		"on_user_message root",
+		"before_run root",
+		"before_agent root",
+		"before_model root",
+		"second before_model root",
+		"after_model root",
+		"on_event root",
+		"before_tool helper",
+		// The agent tool runs the helper with the plugins.
+		"on_user_message helper",
+		"before_run helper",
+		"before_agent helper",
+		"before_model helper",
+		"second before_model helper",
+		"agent before_model helper",
+		"after_model helper",
+		"on_event helper",
+		"after_agent helper",
+		"after_run helper",
+		"after_tool helper",
+		"on_event root",
+		"before_model root",
+		"second before_model root",
+		"after_model root",
+		"on_event root",
+		"after_agent root",
+		"after_run root",
+	}
+	if diff := cmp.Diff(want, log); diff != "" {
+		t.Errorf("plugin callbacks mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlugins_ShortCircuit(t *testing.T) {
+	type Args struct {
+		Country string `json:"country"`
+	}
+	lookup, err := functiontool.New(functiontool.Config{Name: "lookup", Description: "looks up"}, func(_ tool.Context, args Args) map[string]any {
+		return map[string]any{"capital": "Paris"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  plugin.Config
+		// modelReplies are the successive replies of the model, a nil one
+		// fails the call.
+		modelReplies []*genai.Content
+		want         []string
+		wantCalls    int
+	}{
+		{
+			name: "user message replaced",
+			cfg: plugin.Config{OnUserMessageCallback: func(ctx agent.InvocationContext, msg *genai.Content) (*genai.Content, error) {
+				return genai.NewContentFromText("[redacted]", genai.RoleUser), nil
+			}},
+			modelReplies: []*genai.Content{genai.NewContentFromText("ok", genai.RoleModel)},
+			want:         []string{"ok"},
+			wantCalls:    1,
+		},
+		{
+			name: "run skipped",
+			cfg: plugin.Config{BeforeRunCallback: func(ctx agent.InvocationContext) (*genai.Content, error) {
+				return genai.NewContentFromText("under maintenance", genai.RoleModel), nil
+			}},
+			want: []string{"under maintenance"},
+		},
+		{
+			name: "event replaced",
+			cfg: plugin.Config{OnEventCallback: func(ctx agent.InvocationContext, event *session.Event) (*session.Event, error) {
+				modified := *event
+				modified.LLMResponse.Content = genai.NewContentFromText("modified", genai.RoleModel)
+				return &modified, nil
+			}},
+			modelReplies: []*genai.Content{genai.NewContentFromText("ok", genai.RoleModel)},
+			want:         []string{"modified"},
+			wantCalls:    1,
+		},
+		{
+			name: "model call skipped",
+			cfg: plugin.Config{BeforeModelCallback: func(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+				return &model.LLMResponse{Content: genai.NewContentFromText("cached", genai.RoleModel)}, nil
+			}},
+			want: []string{"cached"},
+		},
+		{
+			name: "model response replaced",
+			cfg: plugin.Config{AfterModelCallback: func(ctx agent.CallbackContext, resp *model.LLMResponse) (*model.LLMResponse, error) {
+				return &model.LLMResponse{Content: genai.NewContentFromText("replaced", genai.RoleModel)}, nil
+			}},
+			modelReplies: []*genai.Content{genai.NewContentFromText("ok", genai.RoleModel)},
+			want:         []string{"replaced"},
+			wantCalls:    1,
+		},
+		{
+			name: "model error recovered",
+			cfg: plugin.Config{OnModelErrorCallback: func(ctx agent.CallbackContext, req *model.LLMRequest, err error) (*model.LLMResponse, error) {
+				return &model.LLMResponse{Content: genai.NewContentFromText("fallback: "+err.Error(), genai.RoleModel)}, nil
+			}},
+			modelReplies: []*genai.Content{nil},
+			want:         []string{"fallback: unavailable"},
+			wantCalls:    1,
+		},
+		{
+			name: "tool call skipped",
+			cfg: plugin.Config{BeforeToolCallback: func(ctx tool.Context, tool tool.Tool, args map[string]any) (map[string]any, error) {
+				return map[string]any{"capital": "cached"}, nil
+			}},
+			modelReplies: []*genai.Content{
+				genai.NewContentFromFunctionCall("lookup", map[string]any{"country": "France"}, genai.RoleModel),
+				genai.NewContentFromText("ok", genai.RoleModel),
+			},
+			want:      []string{"ok"},
+			wantCalls: 2,
+		},
+		{
+			name: "tool error recovered",
+			cfg: plugin.Config{OnToolErrorCallback: func(ctx tool.Context, tool tool.Tool, args map[string]any, err error) (map[string]any, error) {
+				return map[string]any{"capital": "unknown"}, nil
+			}},
+			modelReplies: []*genai.Content{
+				// Invalid arguments fail the tool call.
+				genai.NewContentFromFunctionCall("lookup", map[string]any{"country": 1}, genai.RoleModel),
+				genai.NewContentFromText("ok", genai.RoleModel),
+			},
+			want:      []string{"ok"},
+			wantCalls: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []*model.LLMRequest
+			fakeModel := &FakeLLM{GenerateContentFunc: func(_ context.Context, req *model.LLMRequest, _ bool) (model.LLMResponse, error) {
+				requests = append(requests, req)
+				reply := tc.modelReplies[len(requests)-1]
+				if reply == nil {
+					return model.LLMResponse{}, errors.New("unavailable")
+				}
+				return model.LLMResponse{Content: reply}, nil
+			}}
+			var agentCallbacks int
+			a, err := llmagent.New(llmagent.Config{
+				Name:  "agent",
+				Model: fakeModel,
+				Tools: []tool.Tool{lookup},
+				BeforeModelCallbacks: []llmagent.BeforeModelCallback{func(agent.CallbackContext, *model.LLMRequest) (*model.LLMResponse, error) {
+					agentCallbacks++
+					return nil, nil
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.cfg.Name = "plugin"
+			p, err := plugin.New(tc.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := runWithPlugins(t, a, []*plugin.Plugin{p}, "France?")
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Run() texts mismatch (-want +got):\n%s", diff)
+			}
+			if len(requests) != tc.wantCalls {
+				t.Fatalf("model calls = %d, want %d", len(requests), tc.wantCalls)
+			}
+			// The plugins short-circuiting the model call skip the agent
+			// callbacks.
+			if agentCallbacks != tc.wantCalls {
+				t.Errorf("agent callbacks = %d, want %d", agentCallbacks, tc.wantCalls)
+			}
+			if tc.name == "user message replaced" {
+				if text := requests[0].Contents[0].Parts[0].Text; text != "[redacted]" {
+					t.Errorf("model request message = %q, want the replaced one", text)
+				}
+			}
+			if len(requests) > 1 {
+				fr := requests[1].Contents[len(requests[1].Contents)-1].Parts[0].FunctionResponse
+				if fr == nil || fr.Response["error"] != nil {
+					t.Errorf("function response = %+v, want the result of the plugin", fr)
+				}
+			}
+		})
+	}
+}
+
+func TestPlugins_ToolError(t *testing.T) {
+	type Args struct{}
+	failing, err := functiontool.New(functiontool.Config{Name: "failing", Description: "fails"}, func(_ tool.Context, _ Args) map[string]any {
+		panic("backend down")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	toolErr := `tool "failing" panicked: backend down`
+	tests := []struct {
+		name string
+		// recover is the result of the OnToolErrorCallback of the plugin.
+		recover map[string]any
+		want    []string
+		// wantResponse is the function response sent to the model.
+		wantResponse map[string]any
+	}{
+		{
+			name: "error",
+			want: []string{
+				"plugin on_tool_error: " + toolErr,
+				"agent after_tool: " + toolErr,
+			},
+			wantResponse: map[string]any{"error": `tool "failing" failed: ` + toolErr},
+		},
+		{
+			name:    "error recovered by the plugin",
+			recover: map[string]any{"status": "cached"},
+			want: []string{
+				"plugin on_tool_error: " + toolErr,
+				"plugin after_tool: map[status:cached]",
+				"agent after_tool: map[status:cached]",
+			},
+			wantResponse: map[string]any{"status": "cached"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var log []string
+			p, err := plugin.New(plugin.Config{
+				Name: "plugin",
+				OnToolErrorCallback: func(ctx tool.Context, tool tool.Tool, args map[string]any, err error) (map[string]any, error) {
+					log = append(log, "plugin on_tool_error: "+err.Error())
+					return tc.recover, nil
+				},
+				AfterToolCallback: func(ctx tool.Context, tool tool.Tool, args, result map[string]any) (map[string]any, error) {
+					log = append(log, fmt.Sprint("plugin after_tool: ", result))
+					return nil, nil
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var requests []*model.LLMRequest
+			a, err := llmagent.New(llmagent.Config{
+				Name: "agent",
+				Model: &FakeLLM{GenerateContentFunc: func(_ context.Context, req *model.LLMRequest, _ bool) (model.LLMResponse, error) {
+					requests = append(requests, req)
+					if len(requests) == 1 {
+						return model.LLMResponse{Content: genai.NewContentFromFunctionCall("failing", map[string]any{}, genai.RoleModel)}, nil
+					}
+					return model.LLMResponse{Content: genai.NewContentFromText("ok", genai.RoleModel)}, nil
+				}},
+				Tools: []tool.Tool{failing},
+				AfterToolCallbacks: []llmagent.AfterToolCallback{func(ctx tool.Context, tool tool.Tool, args, result map[string]any, err error) (map[string]any, error) {
+					if err != nil {
+						log = append(log, "agent after_tool: "+err.Error())
+					} else {
+						log = append(log, fmt.Sprint("agent after_tool: ", result))
+					}
+					return nil, nil
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := runWithPlugins(t, a, []*plugin.Plugin{p}, "go"); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, log); diff != "" {
+				t.Errorf("tool callbacks mismatch (-want +got):\n%s", diff)
+			}
+			if len(requests) != 2 {
+				t.Fatalf("model calls = %d, want 2", len(requests))
+			}
+			fr := requests[1].Contents[len(requests[1].Contents)-1].Parts[0].FunctionResponse
+			if fr == nil {
+				t.Fatalf("last content of the second request has no function response")
+			}
+			// The error of the response is not a string until it is encoded.
+			if got, want := fmt.Sprint(fr.Response), fmt.Sprint(tc.wantResponse); got != want {
+				t.Errorf("function response = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestPlugins_Duplicate(t *testing.T) {
+	p, err := plugin.New(plugin.Config{Name: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := llmagent.New(llmagent.Config{Name: "agent", Model: &FakeLLM{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = runner.New(runner.Config{AppName: "app", Agent: a, SessionService: session.InMemoryService(), Plugins: []*plugin.Plugin{p, p}})
+	if err == nil {
+		t.Error("runner.New() with duplicate plugins succeeded, want an error")
+	}
+	if _, err := plugin.New(plugin.Config{}); err == nil {
+		t.Error("plugin.New() without name succeeded, want an error")
+	}
+}
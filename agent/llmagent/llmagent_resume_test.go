@@ -0,0 +1,289 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
+	"google.golang.org/adk/agent/workflowagents/parallelagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+func TestResume(t *testing.T) {
+	type Args struct {
+		Key string `json:"key"`
+	}
+	type Result struct{}
+
+	// stepCalls counts the calls of the step tool by key. The calls with a
+	// key of blocked signal started and wait for release to be closed.
+	var (
+		mu        sync.Mutex
+		stepCalls map[string]int
+		blocked   string
+		started   chan struct{}
+		release   chan struct{}
+	)
+	step, err := functiontool.New(functiontool.Config{Name: "step", Description: "runs a step"}, func(_ tool.Context, args Args) Result {
+		mu.Lock()
+		stepCalls[args.Key]++
+		wait := args.Key == blocked
+		mu.Unlock()
+		if wait {
+			started <- struct{}{}
+			<-release
+		}
+		return Result{}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newAgent := func(t *testing.T, name string, responses ...*genai.Content) (agent.Agent, *testutil.MockModel) {
+		t.Helper()
+		m := &testutil.MockModel{Responses: responses}
+		a, err := llmagent.New(llmagent.Config{Name: name, Model: m, Tools: []tool.Tool{step}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a, m
+	}
+	callStep := func(key string) *genai.Content {
+		return genai.NewContentFromFunctionCall("step", map[string]any{"key": key}, genai.RoleModel)
+	}
+	text := func(s string) *genai.Content {
+		return genai.NewContentFromText(s, genai.RoleModel)
+	}
+	hasFunctionCall := func(ev *session.Event, author string) bool {
+		return ev.Author == author && ev.Content != nil && len(ev.Content.Parts) > 0 && ev.Content.Parts[0].FunctionCall != nil
+	}
+
+	tests := []struct {
+		name string
+		// newRoot returns the root agent and the models of its sub-agents.
+		newRoot func(t *testing.T) (agent.Agent, map[string]*testutil.MockModel)
+		// blocked is the key of the step call that blocks during the run.
+		blocked string
+		// stop reports whether the run is interrupted after the event. It is
+		// called with all the events so far.
+		stop func(events []*session.Event) bool
+		// resumeCfg is the run config of the resumed invocation.
+		resumeCfg      agent.RunConfig
+		wantTexts      []string
+		wantStepCalls  map[string]int
+		wantModelCalls map[string]int
+		// wantErrorCode is the error code of the last event of the resumed
+		// invocation, if it exceeds a limit of resumeCfg.
+		wantErrorCode string
+	}{
+		{
+			name: "sequential agent with a pending function call",
+			newRoot: func(t *testing.T) (agent.Agent, map[string]*testutil.MockModel) {
+				first, firstModel := newAgent(t, "first", callStep("first"), text("first done"))
+				second, secondModel := newAgent(t, "second", text("second done"))
+				root, err := sequentialagent.New(sequentialagent.Config{
+					AgentConfig: agent.Config{Name: "pipeline", SubAgents: []agent.Agent{first, second}},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return root, map[string]*testutil.MockModel{"first": firstModel, "second": secondModel}
+			},
+			stop: func(events []*session.Event) bool {
+				return hasFunctionCall(events[len(events)-1], "first")
+			},
+			wantTexts:      []string{"first done", "second done"},
+			wantStepCalls:  map[string]int{"first": 1},
+			wantModelCalls: map[string]int{"first": 2, "second": 1},
+		},
+		{
+			name: "resumed with limits",
+			newRoot: func(t *testing.T) (agent.Agent, map[string]*testutil.MockModel) {
+				first, firstModel := newAgent(t, "first", callStep("first"), text("first done"))
+				second, secondModel := newAgent(t, "second", text("second done"))
+				root, err := sequentialagent.New(sequentialagent.Config{
+					AgentConfig: agent.Config{Name: "pipeline", SubAgents: []agent.Agent{first, second}},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return root, map[string]*testutil.MockModel{"first": firstModel, "second": secondModel}
+			},
+			stop: func(events []*session.Event) bool {
+				return hasFunctionCall(events[len(events)-1], "first")
+			},
+			resumeCfg:      agent.RunConfig{MaxLLMCalls: 1},
+			wantTexts:      []string{"first done"},
+			wantStepCalls:  map[string]int{"first": 1},
+			wantModelCalls: map[string]int{"first": 2},
+			wantErrorCode:  agent.ErrorCodeMaxLLMCallsExceeded,
+		},
+		{
+			name: "loop agent",
+			newRoot: func(t *testing.T) (agent.Agent, map[string]*testutil.MockModel) {
+				worker, workerModel := newAgent(t, "worker", text("iteration 1"), text("iteration 2"), text("iteration 3"))
+				root, err := loopagent.New(loopagent.Config{
+					AgentConfig:   agent.Config{Name: "loop", SubAgents: []agent.Agent{worker}},
+					MaxIterations: 3,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return root, map[string]*testutil.MockModel{"worker": workerModel}
+			},
+			// Interrupted once the third iteration started.
+			stop: func(events []*session.Event) bool {
+				ev := events[len(events)-1]
+				return ev.Author == "loop" && ev.Actions.AgentState["iteration"] == 2.0
+			},
+			wantTexts:      []string{"iteration 3"},
+			wantModelCalls: map[string]int{"worker": 3},
+		},
+		{
+			name: "parallel agent",
+			newRoot: func(t *testing.T) (agent.Agent, map[string]*testutil.MockModel) {
+				fast, fastModel := newAgent(t, "fast", text("fast done"))
+				slow, slowModel := newAgent(t, "slow", callStep("slow"), text("slow done"))
+				root, err := parallelagent.New(parallelagent.Config{
+					AgentConfig: agent.Config{Name: "fanout", SubAgents: []agent.Agent{fast, slow}},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return root, map[string]*testutil.MockModel{"fast": fastModel, "slow": slowModel}
+			},
+			blocked: "slow",
+			// Interrupted once the fast branch completed, while the slow one
+			// waits for its function call.
+			stop: func(events []*session.Event) bool {
+				var fastCompleted, slowCalled bool
+				for _, ev := range events {
+					if completed, ok := ev.Actions.AgentState["completed"].([]any); ok && ev.Author == "fanout" && len(completed) > 0 {
+						fastCompleted = true
+					}
+					slowCalled = slowCalled || hasFunctionCall(ev, "slow")
+				}
+				return fastCompleted && slowCalled
+			},
+			wantTexts: []string{"slow done"},
+			// The call interrupted during the run is made again.
+			wantStepCalls:  map[string]int{"slow": 1},
+			wantModelCalls: map[string]int{"fast": 1, "slow": 2},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stepCalls = make(map[string]int)
+			blocked = tc.blocked
+			started = make(chan struct{}, 1)
+			release = make(chan struct{})
+
+			root, models := tc.newRoot(t)
+			sessionService := session.InMemoryService()
+			r, err := runner.New(runner.Config{AppName: "app", Agent: root, SessionService: sessionService, Resumable: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+				t.Fatal(err)
+			}
+
+			var events []*session.Event
+			for ev, err := range r.Run(t.Context(), "user", "session", genai.NewContentFromText("go", genai.RoleUser), agent.RunConfig{}) {
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				events = append(events, ev)
+				if tc.stop(events) {
+					break
+				}
+			}
+			if !tc.stop(events) {
+				t.Fatal("Run() completed before the interruption")
+			}
+			invocationID := events[0].InvocationID
+			// Let the interrupted call return, its result is lost.
+			if tc.blocked != "" {
+				<-started
+			}
+			mu.Lock()
+			stepCalls = make(map[string]int)
+			blocked = ""
+			mu.Unlock()
+			close(release)
+
+			var texts []string
+			var errorCode string
+			for ev, err := range r.Resume(t.Context(), "user", "session", invocationID, tc.resumeCfg) {
+				if err != nil {
+					t.Fatalf("Resume() error = %v", err)
+				}
+				errorCode = ev.ErrorCode
+				if ev.InvocationID != invocationID {
+					t.Errorf("Resume() event invocation ID = %q, want %q", ev.InvocationID, invocationID)
+				}
+				if ev.Content != nil && len(ev.Content.Parts) > 0 && ev.Content.Parts[0].Text != "" {
+					texts = append(texts, ev.Content.Parts[0].Text)
+				}
+			}
+			if diff := cmp.Diff(tc.wantTexts, texts); diff != "" {
+				t.Errorf("Resume() texts mismatch (-want +got):\n%s", diff)
+			}
+			mu.Lock()
+			if tc.wantStepCalls == nil {
+				tc.wantStepCalls = map[string]int{}
+			}
+			if diff := cmp.Diff(tc.wantStepCalls, stepCalls); diff != "" {
+				t.Errorf("step calls mismatch (-want +got):\n%s", diff)
+			}
+			mu.Unlock()
+			for name, m := range models {
+				if got := len(m.Requests); got != tc.wantModelCalls[name] {
+					t.Errorf("model calls of %q = %d, want %d", name, got, tc.wantModelCalls[name])
+				}
+			}
+			if errorCode != tc.wantErrorCode {
+				t.Errorf("Resume() last event error code = %q, want %q", errorCode, tc.wantErrorCode)
+			}
+			if tc.wantErrorCode != "" {
+				return
+			}
+
+			// The invocation is completed.
+			for ev, err := range r.Resume(t.Context(), "user", "session", invocationID, agent.RunConfig{}) {
+				t.Errorf("Resume() of a completed invocation yielded (%v, %v)", ev, err)
+			}
+			resp, err := sessionService.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "session"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := resp.Session.Events().At(resp.Session.Events().Len() - 1)
+			if !slices.Contains([]string{root.Name()}, last.Author) || !last.Actions.EndOfAgent {
+				t.Errorf("last event = (%q, EndOfAgent %v), want the end of %q", last.Author, last.Actions.EndOfAgent, root.Name())
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package llmagent_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"iter"
@@ -24,14 +25,18 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/adktest/httprr"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
-	"google.golang.org/adk/internal/httprr"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/codeexecutor"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/tool/functiontool"
 
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/planner"
+	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/genai"
@@ -932,6 +937,271 @@ func TestAgentTransfer(t *testing.T) {
 	//   - test_auto_to_loop
 }
 
+type fakeCodeExecutor struct {
+	inputs []*codeexecutor.ExecutionInput
+}
+
+func (e *fakeCodeExecutor) Execute(ctx context.Context, input *codeexecutor.ExecutionInput) (*codeexecutor.ExecutionResult, error) {
+	e.inputs = append(e.inputs, input)
+	return &codeexecutor.ExecutionResult{
+		Stdout:      "4",
+		OutputFiles: []*codeexecutor.File{{Name: "result.txt", MIMEType: "text/plain", Content: []byte("4")}},
+	}, nil
+}
+
+func TestCodeExecutor(t *testing.T) {
+	ctx := t.Context()
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromText("Let me compute it.\n```tool_code\nprint(2 + 2)\n```\nignored", genai.RoleModel),
+			genai.NewContentFromText("The answer is 4.", genai.RoleModel),
+		},
+	}
+	executor := &fakeCodeExecutor{}
+	a, err := llmagent.New(llmagent.Config{
+		Name:         "coder",
+		Model:        mockModel,
+		CodeExecutor: executor,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionService := session.InMemoryService()
+	artifactService := artifact.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: a, SessionService: sessionService, ArtifactService: artifactService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []*genai.Part
+	var artifactDelta map[string]int64
+	for ev, err := range r.Run(ctx, "user", "session", genai.NewContentFromText("what is 2 + 2?", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		got = append(got, ev.Content.Parts...)
+		if ev.Actions.ArtifactDelta != nil {
+			artifactDelta = ev.Actions.ArtifactDelta
+		}
+	}
+
+	want := []*genai.Part{
+		genai.NewPartFromText("Let me compute it.\n"),
+		genai.NewPartFromExecutableCode("print(2 + 2)", genai.LanguagePython),
+		genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, "Code execution result:\n4\nSaved artifacts:\n`result.txt`"),
+		genai.NewPartFromText("The answer is 4."),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Run() parts mismatch (-want +got):\n%s", diff)
+	}
+	if len(executor.inputs) != 1 || executor.inputs[0].Code != "print(2 + 2)" {
+		t.Errorf("executor inputs = %v, want a single execution of the code", executor.inputs)
+	}
+	if diff := cmp.Diff(map[string]int64{"result.txt": 1}, artifactDelta); diff != "" {
+		t.Errorf("ArtifactDelta mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := artifactService.Load(ctx, &artifact.LoadRequest{AppName: "app", UserID: "user", SessionID: "session", FileName: "result.txt"}); err != nil {
+		t.Errorf("output file was not saved as artifact: %v", err)
+	}
+
+	// The code and its result are sent back to the model as text.
+	if len(mockModel.Requests) != 2 {
+		t.Fatalf("model called %d times, want 2", len(mockModel.Requests))
+	}
+	var history []string
+	for _, c := range mockModel.Requests[1].Contents {
+		for _, p := range c.Parts {
+			history = append(history, c.Role+": "+p.Text)
+		}
+	}
+	wantHistory := []string{
+		"user: what is 2 + 2?",
+		"model: Let me compute it.\n",
+		"model: ```tool_code\nprint(2 + 2)\n```",
+		"user: ```tool_output\nCode execution result:\n4\nSaved artifacts:\n`result.txt`\n```",
+	}
+	if diff := cmp.Diff(wantHistory, history); diff != "" {
+		t.Errorf("second request history mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuiltInCodeExecutor(t *testing.T) {
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{genai.NewContentFromText("4", genai.RoleModel)},
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:         "coder",
+		Model:        mockModel,
+		CodeExecutor: codeexecutor.BuiltIn{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "what is 2 + 2?")); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := []*genai.Tool{{CodeExecution: &genai.ToolCodeExecution{}}}
+	if diff := cmp.Diff(want, mockModel.Requests[0].Config.Tools); diff != "" {
+		t.Errorf("request tools mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlanner(t *testing.T) {
+	mockModel := &testutil.MockModel{
+		Responses: []*genai.Content{
+			genai.NewContentFromText("/*PLANNING*/ answer directly /*FINAL_ANSWER*/ 4", genai.RoleModel),
+			genai.NewContentFromText("/*FINAL_ANSWER*/ 6", genai.RoleModel),
+		},
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:    "planner_agent",
+		Model:   mockModel,
+		Planner: planner.NewPlanReAct(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+
+	var got []*genai.Part
+	for _, msg := range []string{"2 + 2?", "3 + 3?"} {
+		parts, err := testutil.CollectParts(runner.Run(t, "session", msg))
+		if err != nil {
+			t.Fatalf("Run(%q) error = %v", msg, err)
+		}
+		got = append(got, parts...)
+	}
+	want := []*genai.Part{
+		{Text: "/*PLANNING*/ answer directly /*FINAL_ANSWER*/", Thought: true},
+		{Text: " 4"},
+		{Text: "/*FINAL_ANSWER*/", Thought: true},
+		{Text: " 6"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Run() parts mismatch (-want +got):\n%s", diff)
+	}
+
+	// The planning instruction is added and the thoughts are not sent back.
+	req := mockModel.Requests[1]
+	if !strings.Contains(req.Config.SystemInstruction.Parts[0].Text, planner.FinalAnswerTag) {
+		t.Errorf("system instruction does not contain the planning instruction")
+	}
+	var history []string
+	for _, c := range req.Contents {
+		for _, p := range c.Parts {
+			history = append(history, c.Role+": "+p.Text)
+		}
+	}
+	wantHistory := []string{"user: 2 + 2?", "model:  4", "user: 3 + 3?"}
+	if diff := cmp.Diff(wantHistory, history); diff != "" {
+		t.Errorf("second request history mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOutputContinuation(t *testing.T) {
+	tests := []struct {
+		name             string
+		maxContinuations int
+		failOnTruncated  bool
+		chunks           []string
+		wantText         string
+		wantErr          error
+		wantRequests     int
+	}{
+		{
+			name:             "continued",
+			maxContinuations: 2,
+			chunks:           []string{"Hello, ", "wor", "ld!"},
+			wantText:         "Hello, world!",
+			wantRequests:     3,
+		},
+		{
+			name:         "no continuation",
+			chunks:       []string{"Hel", "lo"},
+			wantText:     "Hel",
+			wantRequests: 1,
+		},
+		{
+			name:            "no continuation, fail on truncated output",
+			failOnTruncated: true,
+			chunks:          []string{"Hel", "lo"},
+			wantText:        "Hel",
+			wantErr:         model.ErrOutputTruncated,
+			wantRequests:    1,
+		},
+		{
+			name:             "continuations exhausted",
+			maxContinuations: 1,
+			chunks:           []string{"a", "b", "c"},
+			wantText:         "ab",
+			wantErr:          model.ErrOutputTruncated,
+			wantRequests:     2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []*model.LLMRequest
+			fakeModel := &FakeLLM{GenerateContentFunc: func(ctx context.Context, req *model.LLMRequest, stream bool) (model.LLMResponse, error) {
+				requests = append(requests, req)
+				i := len(requests) - 1
+				finishReason := genai.FinishReasonMaxTokens
+				if i == len(tc.chunks)-1 {
+					finishReason = genai.FinishReasonStop
+				}
+				return model.LLMResponse{
+					Content:      genai.NewContentFromText(tc.chunks[i], genai.RoleModel),
+					FinishReason: finishReason,
+				}, nil
+			}}
+			a, err := llmagent.New(llmagent.Config{
+				Name:                   "report_agent",
+				Model:                  fakeModel,
+				MaxOutputContinuations: tc.maxContinuations,
+				FailOnTruncatedOutput:  tc.failOnTruncated,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runner := testutil.NewTestAgentRunner(t, a)
+
+			var texts []string
+			var gotErr error
+			for ev, err := range runner.Run(t, "session", "write a report") {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				texts = append(texts, ev.Content.Parts[0].Text)
+			}
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Errorf("Run() error = %v, want %v", gotErr, tc.wantErr)
+			}
+			// The chunks are stitched into a single event.
+			if diff := cmp.Diff([]string{tc.wantText}, texts); diff != "" {
+				t.Errorf("Run() texts mismatch (-want +got):\n%s", diff)
+			}
+			if len(requests) != tc.wantRequests {
+				t.Fatalf("model requests = %d, want %d", len(requests), tc.wantRequests)
+			}
+			if len(requests) > 1 {
+				// The model is asked to continue the truncated text.
+				contents := requests[len(requests)-1].Contents
+				prev := contents[len(contents)-2]
+				if prev.Role != genai.RoleModel || prev.Parts[0].Text != strings.Join(tc.chunks[:len(requests)-1], "") {
+					t.Errorf("continuation request previous content = %+v, want the truncated text", prev.Parts[0])
+				}
+				if last := contents[len(contents)-1]; last.Role != genai.RoleUser {
+					t.Errorf("continuation request last content role = %q, want %q", last.Role, genai.RoleUser)
+				}
+			}
+		})
+	}
+}
+
 func newGeminiModel(t *testing.T, modelName string, transport http.RoundTripper) model.LLM {
 	apiKey := "fakeKey"
 	if transport == nil { // use httprr
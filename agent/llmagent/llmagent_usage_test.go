@@ -0,0 +1,190 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/resilient"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/adk/usage"
+	"google.golang.org/genai"
+)
+
+func TestUsage(t *testing.T) {
+	type Args struct{}
+	type Result struct{}
+	lookup, err := functiontool.New(functiontool.Config{Name: "lookup", Description: "looks up"}, func(tool.Context, Args) Result {
+		return Result{}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each invocation calls the tool, then answers.
+	var calls int
+	fakeModel := &FakeLLM{GenerateContentFunc: func(context.Context, *model.LLMRequest, bool) (model.LLMResponse, error) {
+		calls++
+		content := genai.NewContentFromFunctionCall("lookup", nil, genai.RoleModel)
+		if calls%2 == 0 {
+			content = genai.NewContentFromText("done", genai.RoleModel)
+		}
+		return model.LLMResponse{
+			Content: content,
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:        1000,
+				CachedContentTokenCount: 400,
+				CandidatesTokenCount:    100,
+				ThoughtsTokenCount:      50,
+				TotalTokenCount:         1150,
+			},
+		}, nil
+	}}
+	a, err := llmagent.New(llmagent.Config{Name: "agent", Model: fakeModel, Tools: []tool.Tool{lookup}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:        "app",
+		Agent:          a,
+		SessionService: sessionService,
+		Usage: &usage.Config{Pricing: usage.Pricing{
+			"fake":     {Prompt: 1, CachedPrompt: 0.5, Output: 2},
+			"fake-llm": {Prompt: 2, CachedPrompt: 1, Output: 4},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each model call costs 600 uncached prompt, 400 cached and 150 output
+	// tokens at the price of "fake-llm", the exact match.
+	call := usage.Usage{PromptTokens: 1000, CachedTokens: 400, CandidatesTokens: 100, ThoughtsTokens: 50, TotalTokens: 1150, LLMCalls: 1, Cost: 0.0022}
+	reportOf := func(n int64) usage.Report {
+		u := usage.Usage{
+			PromptTokens: n * call.PromptTokens, CachedTokens: n * call.CachedTokens, CandidatesTokens: n * call.CandidatesTokens,
+			ThoughtsTokens: n * call.ThoughtsTokens, TotalTokens: n * call.TotalTokens, LLMCalls: n, Cost: float64(n) * call.Cost,
+		}
+		return usage.Report{Total: u, Agents: map[string]usage.Usage{"agent": u}, Models: map[string]usage.Usage{"fake-llm": u}}
+	}
+	opts := cmpopts.EquateApprox(0, 1e-9)
+
+	for i, sessionID := range []string{"s1", "s2"} {
+		if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: sessionID}); err != nil {
+			t.Fatal(err)
+		}
+		var last *session.Event
+		for ev, err := range r.Run(t.Context(), "user", sessionID, genai.NewContentFromText("go", genai.RoleUser), agent.RunConfig{}) {
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			last = ev
+		}
+
+		got, err := usage.FromEvent(last)
+		if err != nil || got == nil {
+			t.Fatalf("usage.FromEvent(last event) = (%v, %v), want the usage summary", got, err)
+		}
+		if diff := cmp.Diff(reportOf(2), *got, opts); diff != "" {
+			t.Errorf("invocation usage mismatch (-want +got):\n%s", diff)
+		}
+		if last.Author != "agent" {
+			t.Errorf("usage summary author = %q, want %q", last.Author, "agent")
+		}
+
+		resp, err := sessionService.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: sessionID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessionUsage, err := usage.FromState(resp.Session.State(), usage.SessionStateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(reportOf(2), *sessionUsage, opts); diff != "" {
+			t.Errorf("session usage mismatch (-want +got):\n%s", diff)
+		}
+		userUsage, err := usage.FromState(resp.Session.State(), usage.UserStateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(reportOf(2*int64(i+1)), *userUsage, opts); diff != "" {
+			t.Errorf("user usage mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestUsage_FallbackModel(t *testing.T) {
+	primary := &FakeLLM{GenerateContentFunc: func(context.Context, *model.LLMRequest, bool) (model.LLMResponse, error) {
+		return model.LLMResponse{}, genai.APIError{Code: http.StatusServiceUnavailable}
+	}}
+	fallback := &namedLLM{name: "fallback", FakeLLM: &FakeLLM{GenerateContentFunc: func(context.Context, *model.LLMRequest, bool) (model.LLMResponse, error) {
+		return model.LLMResponse{
+			Content:       genai.NewContentFromText("done", genai.RoleModel),
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 1000, CandidatesTokenCount: 100, TotalTokenCount: 1100},
+		}, nil
+	}}}
+	llm, err := resilient.New(resilient.Config{Models: []model.LLM{primary, fallback}, Retry: resilient.RetryPolicy{MaxAttempts: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := llmagent.New(llmagent.Config{Name: "agent", Model: llm})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:        "app",
+		Agent:          a,
+		SessionService: sessionService,
+		Usage: &usage.Config{Pricing: usage.Pricing{
+			"fake-llm": {Prompt: 100, Output: 100},
+			"fallback": {Prompt: 1, Output: 2},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s"}); err != nil {
+		t.Fatal(err)
+	}
+	var last *session.Event
+	for ev, err := range r.Run(t.Context(), "user", "s", genai.NewContentFromText("go", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		last = ev
+	}
+
+	got, err := usage.FromEvent(last)
+	if err != nil || got == nil {
+		t.Fatalf("usage.FromEvent(last event) = (%v, %v), want the usage summary", got, err)
+	}
+	// The call is priced as a call of the fallback model that served it.
+	u := usage.Usage{PromptTokens: 1000, CandidatesTokens: 100, TotalTokens: 1100, LLMCalls: 1, Cost: 0.0012}
+	want := usage.Report{Total: u, Agents: map[string]usage.Usage{"agent": u}, Models: map[string]usage.Usage{"fallback": u}}
+	if diff := cmp.Diff(want, *got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("invocation usage mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// WithOutput returns cfg configured for the agent to reply with a JSON value
+// of type T, saved under cfg.OutputKey as a T.
+//
+// The response JSON schema of the model is inferred from T, like the
+// function tools do for their arguments and results. The replies are
+// validated against it and decoded into T with OutputValidator; the model is
+// asked to repair an invalid reply up to cfg.MaxOutputRepairs times.
+//
+// Use OutputFromEvent or OutputFromState to retrieve the output.
+func WithOutput[T any](cfg Config) (Config, error) {
+	if cfg.OutputSchema != nil {
+		return Config{}, fmt.Errorf("agent %q: OutputSchema cannot be used with a typed output", cfg.Name)
+	}
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to infer the output schema of agent %q: %w", cfg.Name, err)
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to resolve the output schema of agent %q: %w", cfg.Name, err)
+	}
+
+	genCfg := &genai.GenerateContentConfig{}
+	if cfg.GenerateContentConfig != nil {
+		c := *cfg.GenerateContentConfig
+		genCfg = &c
+	}
+	genCfg.ResponseMIMEType = "application/json"
+	genCfg.ResponseJsonSchema = schema
+	cfg.GenerateContentConfig = genCfg
+
+	cfg.OutputValidator = func(output string) (any, error) {
+		var v any
+		if err := json.Unmarshal([]byte(trimCodeFence(output)), &v); err != nil {
+			return nil, fmt.Errorf("the response is not valid JSON: %w", err)
+		}
+		if err := resolved.Validate(v); err != nil {
+			return nil, err
+		}
+		return decodeOutput[T](v)
+	}
+	return cfg, nil
+}
+
+// OutputFromEvent returns the typed output of an agent configured with
+// WithOutput, if the event holds it under key.
+func OutputFromEvent[T any](event *session.Event, key string) (T, bool, error) {
+	v, ok := event.Actions.StateDelta[key]
+	if !ok {
+		var zero T
+		return zero, false, nil
+	}
+	out, err := decodeOutput[T](v)
+	return out, err == nil, err
+}
+
+// OutputFromState returns the typed output of an agent configured with
+// WithOutput, saved in the state under key. It returns
+// session.ErrStateKeyNotExist if there is no output yet.
+func OutputFromState[T any](state session.ReadonlyState, key string) (T, error) {
+	v, err := state.Get(key)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return decodeOutput[T](v)
+}
+
+// decodeOutput returns the output held in v, either a T or its JSON
+// representation, e.g. once the state is stored.
+func decodeOutput[T any](v any) (T, error) {
+	if out, ok := v.(T); ok {
+		return out, nil
+	}
+	var out T
+	b, err := json.Marshal(v)
+	if err == nil {
+		err = json.Unmarshal(b, &out)
+	}
+	if err != nil {
+		return out, fmt.Errorf("failed to decode the output as %T: %w", out, err)
+	}
+	return out, nil
+}
+
+// trimCodeFence returns the text without the markdown code fence the model
+// may wrap its JSON reply in.
+func trimCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") || len(text) < 6 {
+		return text
+	}
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "```"), "```")
+	text = strings.TrimPrefix(text, "json")
+	return strings.TrimSpace(text)
+}
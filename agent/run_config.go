@@ -14,6 +14,8 @@
 
 package agent
 
+import "time"
+
 // StreamingMode defines the streaming mode for agent execution.
 type StreamingMode string
 
@@ -23,8 +25,19 @@ const (
 	// StreamingModeSSE enables server-sent events streaming, one-way, where
 	// LLM response parts are streamed immediately as they are generated.
 	StreamingModeSSE StreamingMode = "sse"
+	// StreamingModeBidi enables bidirectional streaming, where user input and
+	// LLM responses flow concurrently over a live connection.
+	//
+	// It is used by runner.RunLive and requires a model implementing
+	// model.LiveLLM.
+	StreamingModeBidi StreamingMode = "bidi"
 )
 
+// StateConflictsMetadataKey is the key of the custom metadata of a merged
+// function response event listing, as a sorted []string, the state keys that
+// the function calls of a model response set to different values.
+const StateConflictsMetadataKey = "adk_state_conflicts"
+
 // RunConfig controls runtime behavior of an agent.
 type RunConfig struct {
 	// StreamingMode defines the streaming mode for an agent.
@@ -32,4 +45,47 @@ type RunConfig struct {
 	// If true, ADK runner will save each part of the user input that is a blob
 	// (e.g., images, files) as an artifact.
 	SaveInputBlobsAsArtifacts bool
+	// MaxConcurrentToolCalls limits how many function calls of a single
+	// model response run concurrently. Zero means no limit; 1 runs the calls
+	// one at a time.
+	//
+	// The function responses are merged into a single event. If calls set a
+	// state key to different values, the value of the last call is kept and
+	// the key is reported under StateConflictsMetadataKey.
+	MaxConcurrentToolCalls int
+
+	// MaxLLMCalls limits the number of calls to the model during the
+	// invocation, across all the agents it runs, including transfers and
+	// sub-agents. Zero means no limit.
+	MaxLLMCalls int
+	// MaxToolCalls limits the number of function calls executed during the
+	// invocation, across all the agents it runs. Zero means no limit.
+	MaxToolCalls int
+	// MaxDuration limits the wall-clock duration of the invocation. Zero
+	// means no limit.
+	MaxDuration time.Duration
+}
+
+// Error codes of the event emitted when an invocation exceeds one of the
+// limits of its RunConfig. The event ends the invocation.
+const (
+	ErrorCodeMaxLLMCallsExceeded  = "MAX_LLM_CALLS_EXCEEDED"
+	ErrorCodeMaxToolCallsExceeded = "MAX_TOOL_CALLS_EXCEEDED"
+	ErrorCodeMaxDurationExceeded  = "MAX_DURATION_EXCEEDED"
+)
+
+// LimitExceededError reports that an invocation exceeded one of the limits
+// of its RunConfig.
+//
+// The runner does not return it as an error: it emits an event with the
+// same error code and message, and ends the invocation.
+type LimitExceededError struct {
+	// Code is one of the ErrorCode*Exceeded constants.
+	Code string
+	// Message describes the exceeded limit.
+	Message string
+}
+
+func (e *LimitExceededError) Error() string {
+	return e.Message
 }
@@ -22,6 +22,7 @@ import (
 
 	"google.golang.org/adk/agent"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/agent/checkpoint"
 	"google.golang.org/adk/session"
 )
 
@@ -72,34 +73,55 @@ type loopAgent struct {
 	maxIterations uint
 }
 
-func (a *loopAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
-	count := a.maxIterations
+// loopState is the checkpoint of a loop agent: the number of completed
+// iterations and the index of the sub-agent it runs.
+type loopState struct {
+	Iteration uint `json:"iteration"`
+	SubAgent  int  `json:"sub_agent"`
+}
 
+func (a *loopAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	return func(yield func(*session.Event, error) bool) {
-		for {
-			shouldExit := false
-			for _, subAgent := range ctx.Agent().SubAgents() {
-				for event, err := range subAgent.Run(ctx) {
+		// A resumed invocation continues from the last checkpoint.
+		checkpoints := checkpoint.FromContext(ctx)
+		var pos loopState
+		if _, err := checkpoints.Take(ctx.Agent().Name(), &pos); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		subAgents := ctx.Agent().SubAgents()
+		for ; a.maxIterations == 0 || pos.Iteration < a.maxIterations; pos.Iteration++ {
+			for ; pos.SubAgent < len(subAgents); pos.SubAgent++ {
+				if checkpoints != nil {
+					event, err := checkpoint.NewEvent(ctx, pos)
+					if !yield(event, err) || err != nil {
+						return
+					}
+				}
+
+				shouldExit := false
+				for event, err := range subAgents[pos.SubAgent].Run(ctx) {
 					// TODO: ensure consistency -- if there's an error, return and close iterator, verify everywhere in ADK.
 					if !yield(event, err) {
 						return
 					}
 
-					if event.Actions.Escalate {
+					if event != nil && event.Actions.Escalate {
 						shouldExit = true
 					}
 				}
 				if shouldExit {
+					if checkpoints != nil {
+						yield(checkpoint.NewEndEvent(ctx), nil)
+					}
 					return
 				}
 			}
-
-			if count > 0 {
-				count--
-				if count == 0 {
-					return
-				}
-			}
+			pos.SubAgent = 0
+		}
+		if checkpoints != nil {
+			yield(checkpoint.NewEndEvent(ctx), nil)
 		}
 	}
 }
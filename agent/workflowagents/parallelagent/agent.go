@@ -18,10 +18,12 @@ package parallelagent
 import (
 	"fmt"
 	"iter"
+	"slices"
 
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/adk/agent"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/agent/checkpoint"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/session"
 )
@@ -63,9 +65,24 @@ func New(cfg Config) (agent.Agent, error) {
 	return parallelAgent, nil
 }
 
+// parallelState is the checkpoint of a parallel agent: the sub-agents that
+// completed their run.
+type parallelState struct {
+	Completed []string `json:"completed"`
+}
+
 func run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	curAgent := ctx.Agent()
 
+	// A resumed invocation only runs the sub-agents that did not complete.
+	checkpoints := checkpoint.FromContext(ctx)
+	var state parallelState
+	if _, err := checkpoints.Take(curAgent.Name(), &state); err != nil {
+		return func(yield func(*session.Event, error) bool) {
+			yield(nil, err)
+		}
+	}
+
 	var (
 		errGroup, errGroupCtx = errgroup.WithContext(ctx)
 		doneChan              = make(chan bool)
@@ -73,6 +90,9 @@ func run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	)
 
 	for _, sa := range ctx.Agent().SubAgents() {
+		if slices.Contains(state.Completed, sa.Name()) {
+			continue
+		}
 		branch := fmt.Sprintf("%s.%s", curAgent.Name(), sa.Name())
 		if ctx.Branch() != "" {
 			branch = fmt.Sprintf("%s.%s", ctx.Branch(), branch)
@@ -80,18 +100,25 @@ func run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 		subAgent := sa
 		errGroup.Go(func() error {
 			subCtx := icontext.NewInvocationContext(errGroupCtx, icontext.InvocationContextParams{
-				Artifacts:   ctx.Artifacts(),
-				Memory:      ctx.Memory(),
-				Session:     ctx.Session(),
-				Branch:      branch,
-				Agent:       subAgent,
-				UserContent: ctx.UserContent(),
-				RunConfig:   ctx.RunConfig(),
+				InvocationID: ctx.InvocationID(),
+				Artifacts:    ctx.Artifacts(),
+				Memory:       ctx.Memory(),
+				Session:      ctx.Session(),
+				Branch:       branch,
+				Agent:        subAgent,
+				UserContent:  ctx.UserContent(),
+				RunConfig:    ctx.RunConfig(),
 			})
 
 			if err := runSubAgent(subCtx, subAgent, resultsChan, doneChan); err != nil {
 				return fmt.Errorf("failed to run sub-agent %q: %w", subAgent.Name(), err)
 			}
+			if checkpoints != nil {
+				select {
+				case <-doneChan:
+				case resultsChan <- result{completed: subAgent.Name()}:
+				}
+			}
 
 			return nil
 		})
@@ -105,11 +132,32 @@ func run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	return func(yield func(*session.Event, error) bool) {
 		defer close(doneChan)
 
+		if checkpoints != nil {
+			event, err := checkpoint.NewEvent(ctx, state)
+			if !yield(event, err) || err != nil {
+				return
+			}
+		}
+		failed := false
 		for res := range resultsChan {
+			if res.completed != "" {
+				state.Completed = append(state.Completed, res.completed)
+				event, err := checkpoint.NewEvent(ctx, state)
+				if !yield(event, err) || err != nil {
+					return
+				}
+				continue
+			}
 			if !yield(res.event, res.err) {
-				break
+				return
+			}
+			if res.err != nil {
+				failed = true
 			}
 		}
+		if checkpoints != nil && !failed {
+			yield(checkpoint.NewEndEvent(ctx), nil)
+		}
 	}
 }
 
@@ -141,4 +189,6 @@ func runSubAgent(ctx agent.InvocationContext, agent agent.Agent, results chan<-
 type result struct {
 	event *session.Event
 	err   error
+	// completed is the name of the sub-agent that completed its run.
+	completed string
 }
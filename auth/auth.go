@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth defines how tools declare the authentication they require and
+// how credentials are requested from the client.
+//
+// A tool that needs a credential calls tool.Context.Credential with its
+// auth config. If no credential is available yet, the tool calls
+// tool.Context.RequestCredential and returns. The agent then emits a
+// function call named RequestCredentialFunctionName, whose arguments are
+// described by RequestCredentialArgs, and ends the invocation.
+//
+// The client obtains the credential, e.g. by sending the user through the
+// OAuth2 consent page at the auth URI, and replies with a function response
+// to that call holding the auth config updated with the exchanged
+// credential. In the next invocation, the tool is called again and the
+// credential is available via tool.Context.Credential. The credential is
+// kept in the user state, so the later invocations and sessions of the user
+// reuse it without another consent.
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// RequestCredentialFunctionName is the name of the function call emitted
+// by the agent to request a credential from the client.
+const RequestCredentialFunctionName = "adk_request_credential"
+
+// SchemeType is the type of an authentication scheme.
+type SchemeType string
+
+const (
+	// SchemeTypeAPIKey is an API key sent in a header, query parameter or
+	// cookie.
+	SchemeTypeAPIKey SchemeType = "apiKey"
+	// SchemeTypeHTTPBearer is a bearer token sent in the Authorization
+	// header.
+	SchemeTypeHTTPBearer SchemeType = "httpBearer"
+	// SchemeTypeOAuth2AuthorizationCode is the OAuth2 authorization code
+	// flow. It requires the user to grant access.
+	SchemeTypeOAuth2AuthorizationCode SchemeType = "oauth2AuthorizationCode"
+	// SchemeTypeOAuth2ClientCredentials is the OAuth2 client credentials
+	// flow. The access token is obtained without user interaction.
+	SchemeTypeOAuth2ClientCredentials SchemeType = "oauth2ClientCredentials"
+)
+
+// Scheme describes how a tool authenticates to the API it calls.
+type Scheme struct {
+	// Type of the scheme.
+	Type SchemeType `json:"type"`
+
+	// In is where the API key is sent: "header", "query" or "cookie".
+	// Only for SchemeTypeAPIKey.
+	In string `json:"in,omitempty"`
+	// Name of the header, query parameter or cookie holding the API key.
+	// Only for SchemeTypeAPIKey.
+	Name string `json:"name,omitempty"`
+
+	// AuthorizationURL is the URL of the OAuth2 consent page.
+	// Only for SchemeTypeOAuth2AuthorizationCode.
+	AuthorizationURL string `json:"authorizationUrl,omitempty"`
+	// TokenURL is the URL of the OAuth2 token endpoint.
+	TokenURL string `json:"tokenUrl,omitempty"`
+	// Scopes requested for OAuth2 schemes.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// Validate checks that the scheme has the fields required by its type.
+func (s *Scheme) Validate() error {
+	if s == nil {
+		return fmt.Errorf("auth scheme is required")
+	}
+	switch s.Type {
+	case SchemeTypeAPIKey:
+		if s.Name == "" {
+			return fmt.Errorf("api key scheme requires a name")
+		}
+	case SchemeTypeHTTPBearer:
+	case SchemeTypeOAuth2AuthorizationCode:
+		if s.AuthorizationURL == "" || s.TokenURL == "" {
+			return fmt.Errorf("oauth2 authorization code scheme requires an authorization URL and a token URL")
+		}
+	case SchemeTypeOAuth2ClientCredentials:
+		if s.TokenURL == "" {
+			return fmt.Errorf("oauth2 client credentials scheme requires a token URL")
+		}
+	default:
+		return fmt.Errorf("unsupported auth scheme type %q", s.Type)
+	}
+	return nil
+}
+
+// Credential holds the secrets used to authenticate.
+type Credential struct {
+	// APIKey for SchemeTypeAPIKey.
+	APIKey string `json:"apiKey,omitempty"`
+	// Token is the bearer token. For OAuth2 schemes, it is the access token
+	// once obtained.
+	Token string `json:"token,omitempty"`
+	// OAuth2 holds the client configuration and the state of the OAuth2
+	// flow.
+	OAuth2 *OAuth2 `json:"oauth2,omitempty"`
+}
+
+// OAuth2 holds the client configuration and the state of an OAuth2 flow.
+type OAuth2 struct {
+	ClientID     string `json:"clientId,omitempty"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+	RedirectURI  string `json:"redirectUri,omitempty"`
+
+	// AuthURI is the URI the user must visit to grant access. It is set by
+	// ADK in the credential request.
+	AuthURI string `json:"authUri,omitempty"`
+	// State is the OAuth2 state parameter included in AuthURI.
+	State string `json:"state,omitempty"`
+	// AuthResponseURI is the redirect URI, including the authorization
+	// code, the user was sent to after granting access. It is set by the
+	// client in the credential response.
+	AuthResponseURI string `json:"authResponseUri,omitempty"`
+	// AuthCode is the authorization code. The client sets either AuthCode
+	// or AuthResponseURI.
+	AuthCode string `json:"authCode,omitempty"`
+
+	AccessToken  string    `json:"accessToken,omitempty"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"`
+}
+
+// Config is the authentication required by a tool.
+type Config struct {
+	// Scheme is how the tool authenticates.
+	Scheme *Scheme `json:"authScheme"`
+	// RawCredential is the credential configured by the developer, e.g. an
+	// API key or the OAuth2 client ID and secret.
+	RawCredential *Credential `json:"rawAuthCredential,omitempty"`
+	// ExchangedCredential is the credential provided by the client in
+	// response to a credential request.
+	ExchangedCredential *Credential `json:"exchangedAuthCredential,omitempty"`
+	// CredentialKey identifies the credential within the session. If empty,
+	// it is derived from the scheme and the raw credential.
+	CredentialKey string `json:"credentialKey,omitempty"`
+}
+
+// Key returns the key identifying the credential within the session.
+func (c *Config) Key() string {
+	if c.CredentialKey != "" {
+		return c.CredentialKey
+	}
+	h := fnv.New64a()
+	b, _ := json.Marshal(c.Scheme)
+	h.Write(b)
+	if c.RawCredential != nil && c.RawCredential.OAuth2 != nil {
+		h.Write([]byte(c.RawCredential.OAuth2.ClientID))
+	}
+	var typ SchemeType
+	if c.Scheme != nil {
+		typ = c.Scheme.Type
+	}
+	return fmt.Sprintf("adk_%s_%x", typ, h.Sum64())
+}
+
+// RequestCredentialArgs are the arguments of the function call requesting
+// a credential.
+type RequestCredentialArgs struct {
+	// FunctionCallID is the ID of the tool call that requires the
+	// credential.
+	FunctionCallID string `json:"function_call_id"`
+	// AuthConfig is the auth config of the tool. The client replies with
+	// this config, with ExchangedCredential set.
+	AuthConfig *Config `json:"auth_config"`
+}
@@ -17,6 +17,7 @@ package main
 
 import (
 	_ "google.golang.org/adk/cmd/adkgo/internal/deploy/cloudrun"
+	_ "google.golang.org/adk/cmd/adkgo/internal/eval"
 	"google.golang.org/adk/cmd/adkgo/internal/root"
 )
 
@@ -0,0 +1,156 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eval handles command line parameters and execution logic for the
+// evaluation of agents.
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/adk/adktest/httprr"
+	"google.golang.org/adk/agent/agentconfig"
+	"google.golang.org/adk/cmd/adkgo/internal/root"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/gemini"
+)
+
+type evalFlags struct {
+	criteriaPath string
+	outputPath   string
+	httprrPath   string
+	record       bool
+}
+
+var flags evalFlags
+
+// evalCmd represents the eval command
+var evalCmd = &cobra.Command{
+	Use:   "eval <agent_config> <eval_set>...",
+	Short: "Evaluates an agent against eval sets.",
+	Long: `Runs the eval cases of the eval sets against the agent defined in the agent config file, and scores them.
+	The results are summarized and optionally written as a JSON report. The command fails if an eval case fails.
+	With --httprr, the model requests are replayed from the file, so that the evaluation runs offline, or recorded with --record.
+	`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return flags.run(cmd.Context(), args[0], args[1:])
+	},
+}
+
+// init creates flags and adds subcommand to parent
+func init() {
+	root.RootCmd.AddCommand(evalCmd)
+
+	evalCmd.Flags().StringVar(&flags.criteriaPath, "criteria", "", "Path to a JSON file of criteria, defaults to the exact tool trajectory and a response match score of 0.8")
+	evalCmd.Flags().StringVarP(&flags.outputPath, "output", "o", "", "Path of the JSON report of the results")
+	evalCmd.Flags().StringVar(&flags.httprrPath, "httprr", "", "Path to an HTTP record/replay file of the model requests")
+	evalCmd.Flags().BoolVar(&flags.record, "record", false, "Record the model requests in the --httprr file instead of replaying them")
+}
+
+func (f *evalFlags) run(ctx context.Context, agentConfigPath string, evalSetPaths []string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	loadCfg := agentconfig.Config{}
+	if f.httprrPath != "" {
+		mode := httprr.ModeReplay
+		if f.record {
+			mode = httprr.ModeRecord
+		}
+		rr, err := httprr.OpenMode(f.httprrPath, http.DefaultTransport, mode)
+		if err != nil {
+			return fmt.Errorf("failed to open %v: %w", f.httprrPath, err)
+		}
+		defer rr.Close()
+		clientCfg := httprr.NewGeminiClientConfig(rr)
+		loadCfg.NewModel = func(ctx context.Context, name string) (model.LLM, error) {
+			return gemini.NewModel(ctx, name, clientCfg)
+		}
+	} else if f.record {
+		return fmt.Errorf("--record requires --httprr")
+	}
+	a, err := agentconfig.Load(ctx, agentConfigPath, loadCfg)
+	if err != nil {
+		return err
+	}
+
+	cfg := eval.Config{Agent: a}
+	if f.criteriaPath != "" {
+		if cfg.Criteria, err = eval.LoadCriteria(f.criteriaPath); err != nil {
+			return err
+		}
+	}
+
+	var (
+		results []*eval.Result
+		failed  int
+	)
+	for _, path := range evalSetPaths {
+		set, err := eval.LoadEvalSet(path)
+		if err != nil {
+			return err
+		}
+		result, err := eval.Evaluate(ctx, cfg, set)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate %v: %w", path, err)
+		}
+		results = append(results, result)
+		failed += printResult(result)
+	}
+
+	if f.outputPath != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode the report: %w", err)
+		}
+		if err := os.WriteFile(f.outputPath, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write the report: %w", err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d eval cases failed", failed)
+	}
+	return nil
+}
+
+// printResult prints the summary of result, and returns the number of
+// failed eval cases.
+func printResult(result *eval.Result) int {
+	failed := 0
+	fmt.Printf("Eval set %s:\n", result.EvalSetID)
+	for _, c := range result.EvalCaseResults {
+		if c.FinalEvalStatus != eval.StatusPassed {
+			failed++
+		}
+		fmt.Printf("  %s: %s", c.EvalID, c.FinalEvalStatus)
+		for _, m := range c.OverallEvalMetricResults {
+			if m.EvalStatus == eval.StatusNotEvaluated {
+				continue
+			}
+			fmt.Printf(" %s=%.2f/%.2f", m.MetricName, m.Score, m.Threshold)
+		}
+		if c.Error != "" {
+			fmt.Printf(" error: %s", c.Error)
+		}
+		fmt.Println()
+	}
+	return failed
+}
@@ -16,8 +16,12 @@
 package adk
 
 import (
+	"context"
+
 	"github.com/a2aproject/a2a-go/a2asrv"
+	"google.golang.org/adk/agent/agentconfig"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/eval"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/server/restapi/services"
 	"google.golang.org/adk/session"
@@ -30,4 +34,21 @@ type Config struct {
 	MemoryService   memory.Service
 	AgentLoader     services.AgentLoader
 	A2AOptions      []a2asrv.RequestHandlerOption
+	// EvalStore persists the eval sets and results of the web UI. It
+	// defaults to an in-memory store.
+	EvalStore eval.Store
+}
+
+// NewAgentConfigLoader loads the agent trees defined in the agent config
+// files at paths, see package agentconfig, and returns a loader of their root
+// agents. The agent of the first file is the root agent of the loader.
+func NewAgentConfigLoader(ctx context.Context, cfg agentconfig.Config, paths ...string) (services.AgentLoader, error) {
+	agents, err := agentconfig.LoadAll(ctx, cfg, paths...)
+	if err != nil {
+		return nil, err
+	}
+	if len(agents) == 1 {
+		return services.NewSingleAgentLoader(agents[0]), nil
+	}
+	return services.NewMultiAgentLoader(agents[0], agents[1:]...)
 }
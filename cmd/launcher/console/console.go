@@ -18,10 +18,12 @@ package console
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/cmd/launcher"
@@ -30,6 +32,7 @@ import (
 	"google.golang.org/adk/internal/cli/util"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/toolconfirmation"
 	"google.golang.org/genai"
 )
 
@@ -90,15 +93,20 @@ func (l *consoleLauncher) Run(ctx context.Context, config *adk.Config) error {
 
 	reader := bufio.NewReader(os.Stdin)
 
+	// userMsg is the next message to send, if not read from the user, e.g.
+	// the confirmations of tool calls.
+	var userMsg *genai.Content
 	for {
-		fmt.Print("\nUser -> ")
+		if userMsg == nil {
+			fmt.Print("\nUser -> ")
 
-		userInput, err := reader.ReadString('\n')
-		if err != nil {
-			log.Fatal(err)
-		}
+			userInput, err := reader.ReadString('\n')
+			if err != nil {
+				log.Fatal(err)
+			}
 
-		userMsg := genai.NewContentFromText(userInput, genai.RoleUser)
+			userMsg = genai.NewContentFromText(userInput, genai.RoleUser)
+		}
 
 		streamingMode := l.config.streamingMode
 		if streamingMode == "" {
@@ -106,6 +114,7 @@ func (l *consoleLauncher) Run(ctx context.Context, config *adk.Config) error {
 		}
 		fmt.Print("\nAgent -> ")
 		prevText := ""
+		var confirmationRequests []*genai.FunctionCall
 		for event, err := range r.Run(ctx, userID, session.ID(), userMsg, agent.RunConfig{
 			StreamingMode: streamingMode,
 		}) {
@@ -119,6 +128,9 @@ func (l *consoleLauncher) Run(ctx context.Context, config *adk.Config) error {
 				text := ""
 				for _, p := range event.LLMResponse.Content.Parts {
 					text += p.Text
+					if p.FunctionCall != nil && p.FunctionCall.Name == toolconfirmation.FunctionName {
+						confirmationRequests = append(confirmationRequests, p.FunctionCall)
+					}
 				}
 
 				if streamingMode != agent.StreamingModeSSE {
@@ -141,7 +153,54 @@ func (l *consoleLauncher) Run(ctx context.Context, config *adk.Config) error {
 				prevText = ""
 			}
 		}
+
+		userMsg = nil
+		if len(confirmationRequests) > 0 {
+			userMsg, err = confirmToolCalls(reader, confirmationRequests)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+// confirmToolCalls asks the user to confirm the tool calls of the
+// confirmation requests, and returns the message holding the confirmations.
+func confirmToolCalls(reader *bufio.Reader, requests []*genai.FunctionCall) (*genai.Content, error) {
+	content := &genai.Content{Role: genai.RoleUser}
+	for _, fc := range requests {
+		var req toolconfirmation.RequestArgs
+		b, err := json.Marshal(fc.Args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read confirmation request: %w", err)
+		}
+		if err := json.Unmarshal(b, &req); err != nil {
+			return nil, fmt.Errorf("failed to read confirmation request: %w", err)
+		}
+		if req.ToolConfirmation != nil && req.ToolConfirmation.Hint != "" {
+			fmt.Printf("\n%s", req.ToolConfirmation.Hint)
+		}
+		if call := req.OriginalFunctionCall; call != nil {
+			args, _ := json.Marshal(call.Args)
+			fmt.Printf("\nConfirm %s(%s)? [y/N] ", call.Name, args)
+		} else {
+			fmt.Print("\nConfirm? [y/N] ")
+		}
+
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		confirmed := answer == "y" || answer == "yes"
+
+		content.Parts = append(content.Parts, &genai.Part{FunctionResponse: &genai.FunctionResponse{
+			ID:       fc.ID,
+			Name:     toolconfirmation.FunctionName,
+			Response: map[string]any{"confirmed": confirmed},
+		}})
 	}
+	return content, nil
 }
 
 // Parse implements launcher.SubLauncher. After parsing console-specific
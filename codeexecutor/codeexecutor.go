@@ -0,0 +1,86 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codeexecutor defines the interface for executing code generated by
+// the model, and provides the built-in executors.
+//
+// A code executor is set on an LLM agent via llmagent.Config.CodeExecutor.
+// When the model replies with a code block, the agent runs it with the
+// executor, saves the generated files as artifacts and sends the result back
+// to the model.
+package codeexecutor
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// CodeExecutor executes code generated by the model.
+type CodeExecutor interface {
+	// Execute runs the code and returns its result.
+	//
+	// A failure of the code itself (e.g. a non-zero exit code or a timeout)
+	// is reported in ExecutionResult.Stderr. Errors are reserved for failures
+	// of the executor.
+	Execute(ctx context.Context, input *ExecutionInput) (*ExecutionResult, error)
+}
+
+// ExecutionInput is the input of a code execution.
+type ExecutionInput struct {
+	// Code to execute.
+	Code string
+	// Language of the code. Empty means the executor's default language.
+	Language genai.Language
+	// InputFiles are made available to the code.
+	InputFiles []*File
+	// ExecutionID identifies the execution. Executions of the same
+	// invocation share the ID.
+	ExecutionID string
+}
+
+// ExecutionResult is the result of a code execution.
+type ExecutionResult struct {
+	// Stdout is the standard output of the code.
+	Stdout string
+	// Stderr is the standard error of the code.
+	Stderr string
+	// OutputFiles are the files generated by the code.
+	OutputFiles []*File
+}
+
+// File is a file passed to or generated by the code.
+type File struct {
+	// Name of the file, relative to the working directory of the code.
+	Name string
+	// MIMEType of the file content.
+	MIMEType string
+	// Content of the file.
+	Content []byte
+}
+
+// BuiltIn is a code executor that uses the model's native code execution
+// tool, e.g. Gemini code execution.
+//
+// The code is executed by the model itself, therefore Execute is never
+// called by ADK.
+type BuiltIn struct{}
+
+// Execute implements CodeExecutor.
+func (BuiltIn) Execute(ctx context.Context, input *ExecutionInput) (*ExecutionResult, error) {
+	return nil, fmt.Errorf("built-in code executor runs code in the model")
+}
+
+var _ CodeExecutor = BuiltIn{}
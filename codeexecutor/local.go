@@ -0,0 +1,237 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeexecutor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// LocalConfig is the configuration of the local code executor.
+type LocalConfig struct {
+	// Interpreter is the command used to run the code. The path of the file
+	// containing the code is appended as the last argument.
+	// Defaults to ["python3"].
+	Interpreter []string
+	// FileName is the name of the file the code is written to.
+	// Defaults to "main.py".
+	FileName string
+	// WorkDir is the working directory of the code. If empty, a new
+	// temporary directory is created for each execution and removed
+	// afterwards.
+	WorkDir string
+	// Timeout limits the duration of a single execution. Zero means no
+	// limit other than the one of the context.
+	Timeout time.Duration
+	// Env is the environment of the code, in the form "key=value".
+	// If nil, the environment of the current process is used.
+	Env []string
+}
+
+// NewLocal returns a code executor that runs the code in a subprocess on the
+// local machine.
+//
+// The code runs with the privileges of the current process and is not
+// sandboxed. Use it only with trusted models and inputs.
+func NewLocal(cfg LocalConfig) (CodeExecutor, error) {
+	if len(cfg.Interpreter) == 0 {
+		cfg.Interpreter = []string{"python3"}
+	}
+	if cfg.FileName == "" {
+		cfg.FileName = "main.py"
+	}
+	if cfg.Timeout < 0 {
+		return nil, fmt.Errorf("timeout must not be negative, got %v", cfg.Timeout)
+	}
+	if cfg.WorkDir != "" {
+		info, err := os.Stat(cfg.WorkDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat work dir: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("work dir %q is not a directory", cfg.WorkDir)
+		}
+	}
+	return &localExecutor{cfg: cfg}, nil
+}
+
+// waitDelay is how long to wait for the output of the code once it has been
+// killed.
+const waitDelay = time.Second
+
+type localExecutor struct {
+	cfg LocalConfig
+}
+
+// Execute implements CodeExecutor.
+func (e *localExecutor) Execute(ctx context.Context, input *ExecutionInput) (*ExecutionResult, error) {
+	dir := e.cfg.WorkDir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "adk-code-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create work dir: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	}
+
+	for _, f := range input.InputFiles {
+		if err := writeFile(dir, f.Name, f.Content); err != nil {
+			return nil, fmt.Errorf("failed to write input file %q: %w", f.Name, err)
+		}
+	}
+	before, err := snapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	codePath := filepath.Join(dir, e.cfg.FileName)
+	if err := os.WriteFile(codePath, []byte(input.Code), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write code: %w", err)
+	}
+	if e.cfg.WorkDir != "" {
+		defer os.Remove(codePath)
+	}
+
+	if e.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.cfg.Timeout)
+		defer cancel()
+	}
+	args := append(e.cfg.Interpreter[1:len(e.cfg.Interpreter):len(e.cfg.Interpreter)], codePath)
+	cmd := exec.CommandContext(ctx, e.cfg.Interpreter[0], args...)
+	cmd.Dir = dir
+	cmd.Env = e.cfg.Env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Subprocesses started by the code may keep the output pipes open after
+	// the interpreter is killed. Do not wait for them forever.
+	cmd.WaitDelay = waitDelay
+
+	runErr := cmd.Run()
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			fmt.Fprintf(&stderr, "\ncode execution timed out after %v", e.cfg.Timeout)
+		case errors.As(runErr, &exitErr):
+			if stderr.Len() == 0 {
+				fmt.Fprintf(&stderr, "code execution failed: %v", exitErr)
+			}
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		default:
+			return nil, fmt.Errorf("failed to run code: %w", runErr)
+		}
+	}
+
+	outputs, err := changedFiles(dir, before, e.cfg.FileName)
+	if err != nil {
+		return nil, err
+	}
+	return &ExecutionResult{
+		Stdout:      stdout.String(),
+		Stderr:      stderr.String(),
+		OutputFiles: outputs,
+	}, nil
+}
+
+func writeFile(dir, name string, content []byte) error {
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("file name must be a relative path inside the work dir")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o600)
+}
+
+// snapshot returns the modification time of all regular files in dir, keyed
+// by their slash-separated path relative to dir.
+func snapshot(dir string) (map[string]time.Time, error) {
+	files := make(map[string]time.Time)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = info.ModTime()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in work dir: %w", err)
+	}
+	return files, nil
+}
+
+// changedFiles returns the files in dir, except codeFile, that are new or
+// were modified since the snapshot was taken.
+func changedFiles(dir string, before map[string]time.Time, codeFile string) ([]*File, error) {
+	var files []*File
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if name == codeFile {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if mtime, ok := before[name]; ok && !info.ModTime().After(mtime) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, &File{Name: name, MIMEType: mimeType(name, content), Content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect output files: %w", err)
+	}
+	return files, nil
+}
+
+func mimeType(name string, content []byte) string {
+	if t := mime.TypeByExtension(filepath.Ext(name)); t != "" {
+		return t
+	}
+	return http.DetectContentType(content)
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeexecutor_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/codeexecutor"
+)
+
+func newShellExecutor(t *testing.T, cfg codeexecutor.LocalConfig) codeexecutor.CodeExecutor {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	cfg.Interpreter = []string{"sh"}
+	cfg.FileName = "main.sh"
+	e, err := codeexecutor.NewLocal(cfg)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	return e
+}
+
+func TestLocal_Execute(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       string
+		inputFiles []*codeexecutor.File
+		want       *codeexecutor.ExecutionResult
+	}{
+		{
+			name: "stdout",
+			code: "echo hello",
+			want: &codeexecutor.ExecutionResult{Stdout: "hello\n"},
+		},
+		{
+			name: "stderr",
+			code: "echo oops >&2; exit 1",
+			want: &codeexecutor.ExecutionResult{Stderr: "oops\n"},
+		},
+		{
+			name: "exit code without stderr",
+			code: "exit 3",
+			want: &codeexecutor.ExecutionResult{Stderr: "code execution failed: exit status 3"},
+		},
+		{
+			name:       "input and output files",
+			code:       "tr a-z A-Z < in.txt > out.txt",
+			inputFiles: []*codeexecutor.File{{Name: "in.txt", Content: []byte("abc")}},
+			want: &codeexecutor.ExecutionResult{
+				OutputFiles: []*codeexecutor.File{{Name: "out.txt", MIMEType: "text/plain; charset=utf-8", Content: []byte("ABC")}},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newShellExecutor(t, codeexecutor.LocalConfig{})
+			got, err := e.Execute(t.Context(), &codeexecutor.ExecutionInput{Code: tc.code, InputFiles: tc.inputFiles})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Execute() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLocal_Timeout(t *testing.T) {
+	e := newShellExecutor(t, codeexecutor.LocalConfig{Timeout: 100 * time.Millisecond})
+	start := time.Now()
+	got, err := e.Execute(t.Context(), &codeexecutor.ExecutionInput{Code: "sleep 10"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Execute() took %v, want it to be stopped by the timeout", elapsed)
+	}
+	if !strings.Contains(got.Stderr, "timed out") {
+		t.Errorf("Execute() stderr = %q, want timeout message", got.Stderr)
+	}
+}
+
+func TestLocal_WorkDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	e := newShellExecutor(t, codeexecutor.LocalConfig{WorkDir: dir})
+
+	got, err := e.Execute(t.Context(), &codeexecutor.ExecutionInput{Code: "cat existing.txt; echo new > new.txt"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := &codeexecutor.ExecutionResult{
+		Stdout:      "old",
+		OutputFiles: []*codeexecutor.File{{Name: "new.txt", MIMEType: "text/plain; charset=utf-8", Content: []byte("new\n")}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Execute() mismatch (-want +got):\n%s", diff)
+	}
+	// Files persist in the configured work dir, but the code file is removed.
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); err != nil {
+		t.Errorf("output file was not kept in work dir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.sh")); !os.IsNotExist(err) {
+		t.Errorf("code file was not removed from work dir: %v", err)
+	}
+}
+
+func TestNewLocal_InvalidConfig(t *testing.T) {
+	for _, cfg := range []codeexecutor.LocalConfig{
+		{Timeout: -time.Second},
+		{WorkDir: filepath.Join(t.TempDir(), "missing")},
+	} {
+		if _, err := codeexecutor.NewLocal(cfg); err == nil {
+			t.Errorf("NewLocal(%+v) succeeded, want error", cfg)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package database provides an [eval.Store] keeping the eval sets and the
+// results in a relational database, via the GORM library used by the
+// database session service.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/eval"
+	"gorm.io/gorm"
+)
+
+// storageEvalSet corresponds to the 'eval_sets' table.
+type storageEvalSet struct {
+	AppName string `gorm:"primaryKey;"`
+	ID      string `gorm:"primaryKey;"`
+	// Data is the JSON encoding of the eval set.
+	Data       string
+	CreateTime time.Time
+	UpdateTime time.Time
+}
+
+// TableName explicitly sets the table name for the storageEvalSet struct.
+func (storageEvalSet) TableName() string {
+	return "eval_sets"
+}
+
+// storageResult corresponds to the 'eval_results' table.
+type storageResult struct {
+	AppName   string `gorm:"primaryKey;"`
+	ID        string `gorm:"primaryKey;"`
+	EvalSetID string
+	// Data is the JSON encoding of the result.
+	Data       string
+	CreateTime time.Time
+}
+
+// TableName explicitly sets the table name for the storageResult struct.
+func (storageResult) TableName() string {
+	return "eval_results"
+}
+
+// NewStore returns an [eval.Store] backed by a relational database (e.g.,
+// PostgreSQL, Spanner, SQLite), possibly the one of the database session
+// service.
+//
+// It requires a [gorm.Dialector] to specify the database connection and
+// accepts optional [gorm.Option] values for further GORM configuration. The
+// 'eval_sets' and 'eval_results' tables are created or migrated if needed.
+func NewStore(dialector gorm.Dialector, opts ...gorm.Option) (eval.Store, error) {
+	db, err := gorm.Open(dialector, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating database eval store: %w", err)
+	}
+	if err := db.AutoMigrate(&storageEvalSet{}, &storageResult{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate eval tables: %w", err)
+	}
+	return &databaseStore{db: db}, nil
+}
+
+type databaseStore struct {
+	db *gorm.DB
+}
+
+// GetEvalSet implements eval.Store.
+func (s *databaseStore) GetEvalSet(ctx context.Context, appName, evalSetID string) (*eval.EvalSet, error) {
+	var stored storageEvalSet
+	err := s.db.WithContext(ctx).Where(&storageEvalSet{AppName: appName, ID: evalSetID}).First(&stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eval set: %w", err)
+	}
+	var set eval.EvalSet
+	if err := json.Unmarshal([]byte(stored.Data), &set); err != nil {
+		return nil, fmt.Errorf("failed to decode eval set: %w", err)
+	}
+	return &set, nil
+}
+
+// ListEvalSets implements eval.Store.
+func (s *databaseStore) ListEvalSets(ctx context.Context, appName string) ([]string, error) {
+	ids := []string{}
+	err := s.db.WithContext(ctx).Model(&storageEvalSet{}).
+		Where("app_name = ?", appName).
+		Order("id").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list eval sets: %w", err)
+	}
+	return ids, nil
+}
+
+// SaveEvalSet implements eval.Store.
+func (s *databaseStore) SaveEvalSet(ctx context.Context, appName string, set *eval.EvalSet) error {
+	b, err := json.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("failed to encode eval set: %w", err)
+	}
+	now := time.Now()
+	stored := &storageEvalSet{AppName: appName, ID: set.EvalSetID, Data: string(b), CreateTime: now, UpdateTime: now}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing storageEvalSet
+		err := tx.Where(&storageEvalSet{AppName: appName, ID: set.EvalSetID}).First(&existing).Error
+		switch {
+		case err == nil:
+			stored.CreateTime = existing.CreateTime
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		return tx.Save(stored).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save eval set: %w", err)
+	}
+	return nil
+}
+
+// GetResult implements eval.Store.
+func (s *databaseStore) GetResult(ctx context.Context, appName, resultID string) (*eval.Result, error) {
+	var stored storageResult
+	err := s.db.WithContext(ctx).Where(&storageResult{AppName: appName, ID: resultID}).First(&stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eval result: %w", err)
+	}
+	var result eval.Result
+	if err := json.Unmarshal([]byte(stored.Data), &result); err != nil {
+		return nil, fmt.Errorf("failed to decode eval result: %w", err)
+	}
+	return &result, nil
+}
+
+// ListResults implements eval.Store.
+func (s *databaseStore) ListResults(ctx context.Context, appName string) ([]string, error) {
+	ids := []string{}
+	err := s.db.WithContext(ctx).Model(&storageResult{}).
+		Where("app_name = ?", appName).
+		Order("id").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list eval results: %w", err)
+	}
+	return ids, nil
+}
+
+// SaveResult implements eval.Store.
+func (s *databaseStore) SaveResult(ctx context.Context, appName string, result *eval.Result) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode eval result: %w", err)
+	}
+	stored := &storageResult{
+		AppName:    appName,
+		ID:         result.EvalSetResultID,
+		EvalSetID:  result.EvalSetID,
+		Data:       string(b),
+		CreateTime: time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Save(stored).Error; err != nil {
+		return fmt.Errorf("failed to save eval result: %w", err)
+	}
+	return nil
+}
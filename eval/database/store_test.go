@@ -0,0 +1,92 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/eval"
+	"google.golang.org/genai"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDatabaseStore(t *testing.T) {
+	ctx := t.Context()
+	store, err := NewStore(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	set := &eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{{
+		EvalID:       "case",
+		Conversation: []*eval.Invocation{{UserContent: genai.NewContentFromText("hi", genai.RoleUser)}},
+	}}}
+	result := &eval.Result{EvalSetResultID: "app_set_1", EvalSetID: "set", EvalCaseResults: []*eval.CaseResult{
+		{EvalID: "case", FinalEvalStatus: eval.StatusPassed},
+	}}
+
+	if got, err := store.GetEvalSet(ctx, "app", "set"); err != nil || got != nil {
+		t.Errorf("GetEvalSet() of a missing set = (%v, %v), want (nil, nil)", got, err)
+	}
+	if err := store.SaveEvalSet(ctx, "app", &eval.EvalSet{EvalSetID: "set"}); err != nil {
+		t.Fatalf("SaveEvalSet() error = %v", err)
+	}
+	// SaveEvalSet replaces the eval set.
+	if err := store.SaveEvalSet(ctx, "app", set); err != nil {
+		t.Fatalf("SaveEvalSet() error = %v", err)
+	}
+	if err := store.SaveEvalSet(ctx, "app", &eval.EvalSet{EvalSetID: "a"}); err != nil {
+		t.Fatalf("SaveEvalSet() error = %v", err)
+	}
+	if err := store.SaveEvalSet(ctx, "other_app", &eval.EvalSet{EvalSetID: "other"}); err != nil {
+		t.Fatalf("SaveEvalSet() error = %v", err)
+	}
+	got, err := store.GetEvalSet(ctx, "app", "set")
+	if err != nil {
+		t.Fatalf("GetEvalSet() error = %v", err)
+	}
+	if diff := cmp.Diff(set, got); diff != "" {
+		t.Errorf("GetEvalSet() mismatch (-want +got):\n%s", diff)
+	}
+	ids, err := store.ListEvalSets(ctx, "app")
+	if err != nil {
+		t.Fatalf("ListEvalSets() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "set"}, ids); diff != "" {
+		t.Errorf("ListEvalSets() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got, err := store.GetResult(ctx, "app", "app_set_1"); err != nil || got != nil {
+		t.Errorf("GetResult() of a missing result = (%v, %v), want (nil, nil)", got, err)
+	}
+	if err := store.SaveResult(ctx, "app", result); err != nil {
+		t.Fatalf("SaveResult() error = %v", err)
+	}
+	gotResult, err := store.GetResult(ctx, "app", "app_set_1")
+	if err != nil {
+		t.Fatalf("GetResult() error = %v", err)
+	}
+	if diff := cmp.Diff(result, gotResult); diff != "" {
+		t.Errorf("GetResult() mismatch (-want +got):\n%s", diff)
+	}
+	ids, err = store.ListResults(ctx, "app")
+	if err != nil {
+		t.Fatalf("ListResults() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"app_set_1"}, ids); diff != "" {
+		t.Errorf("ListResults() mismatch (-want +got):\n%s", diff)
+	}
+}
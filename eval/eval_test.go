@@ -0,0 +1,367 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/adktest"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+func newWeatherAgent(t *testing.T, m *adktest.Model) agent.Agent {
+	t.Helper()
+	type Args struct {
+		City string `json:"city"`
+	}
+	getWeather, err := functiontool.New(functiontool.Config{Name: "get_weather", Description: "returns the weather in a city"}, func(_ tool.Context, args Args) map[string]any {
+		return map[string]any{"weather": "sunny"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := llmagent.New(llmagent.Config{Name: "weather", Model: m, Tools: []tool.Tool{getWeather}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestEvaluate(t *testing.T) {
+	set, err := eval.LoadEvalSet(filepath.Join("testdata", "weather.evalset.json"))
+	if err != nil {
+		t.Fatalf("LoadEvalSet() error = %v", err)
+	}
+	m := adktest.NewModel(t,
+		// paris
+		adktest.FunctionCall("get_weather", map[string]any{"city": "Paris"}),
+		adktest.Text("It is sunny in Paris."),
+		adktest.Text("You are welcome!"),
+		// rome: the agent looks up the wrong city.
+		adktest.FunctionCall("get_weather", map[string]any{"city": "Milan"}),
+		adktest.Text("It is sunny in Milan."),
+	)
+
+	result, err := eval.Evaluate(t.Context(), eval.Config{Agent: newWeatherAgent(t, m)}, set)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+
+	type summary struct {
+		ID      string
+		Status  eval.Status
+		Metrics map[string]float64
+	}
+	var got []summary
+	for _, c := range result.EvalCaseResults {
+		s := summary{ID: c.EvalID, Status: c.FinalEvalStatus, Metrics: map[string]float64{}}
+		for _, m := range c.OverallEvalMetricResults {
+			s.Metrics[m.MetricName] = math.Round(m.Score*100) / 100
+		}
+		got = append(got, s)
+	}
+	want := []summary{
+		{ID: "paris", Status: eval.StatusPassed, Metrics: map[string]float64{
+			eval.MetricToolTrajectory: 1,
+			eval.MetricResponseMatch:  1,
+		}},
+		{ID: "rome", Status: eval.StatusFailed, Metrics: map[string]float64{
+			eval.MetricToolTrajectory: 0,
+			eval.MetricResponseMatch:  0.8,
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Evaluate() results mismatch (-want +got):\n%s", diff)
+	}
+	if result.Passed() {
+		t.Errorf("Passed() = true, want false")
+	}
+	if got := result.EvalCaseResults[1].UserID; got != "ada" {
+		t.Errorf("UserID = %q, want the user of the session input", got)
+	}
+	if _, err := json.Marshal(result); err != nil {
+		t.Errorf("failed to marshal the result: %v", err)
+	}
+}
+
+func TestEvaluate_Criteria(t *testing.T) {
+	set := &eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{{
+		EvalID: "case",
+		Conversation: []*eval.Invocation{{
+			UserContent:      genai.NewContentFromText("weather?", genai.RoleUser),
+			FinalResponse:    genai.NewContentFromText("sunny", genai.RoleModel),
+			IntermediateData: &eval.IntermediateData{ToolUses: []*genai.FunctionCall{{Name: "get_weather", Args: map[string]any{"city": "Paris"}}}},
+		}},
+	}}}
+	tests := []struct {
+		name     string
+		criteria eval.Criteria
+		want     eval.Status
+	}{
+		{
+			name:     "exact",
+			criteria: eval.Criteria{ToolTrajectory: &eval.TrajectoryCriterion{Threshold: 1, Match: eval.MatchExact}},
+			want:     eval.StatusFailed,
+		},
+		{
+			name:     "in order",
+			criteria: eval.Criteria{ToolTrajectory: &eval.TrajectoryCriterion{Threshold: 1, Match: eval.MatchInOrder}},
+			want:     eval.StatusPassed,
+		},
+		{
+			name:     "low response threshold",
+			criteria: eval.Criteria{ResponseMatch: &eval.ResponseCriterion{Threshold: 0.5}},
+			want:     eval.StatusPassed,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := adktest.NewModel(t,
+				adktest.FunctionCall("get_weather", map[string]any{"city": "Rome"}),
+				adktest.FunctionCall("get_weather", map[string]any{"city": "Paris"}),
+				adktest.Text("It is sunny."),
+			)
+			result, err := eval.Evaluate(t.Context(), eval.Config{Agent: newWeatherAgent(t, m), Criteria: tc.criteria}, set)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if got := result.EvalCaseResults[0].FinalEvalStatus; got != tc.want {
+				t.Errorf("FinalEvalStatus = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate_RunError(t *testing.T) {
+	set := &eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{{
+		EvalID:       "case",
+		Conversation: []*eval.Invocation{{UserContent: genai.NewContentFromText("hi", genai.RoleUser)}},
+	}}}
+	m := adktest.NewModel(t, adktest.Error(errors.New("backend unavailable")))
+
+	result, err := eval.Evaluate(t.Context(), eval.Config{Agent: newWeatherAgent(t, m)}, set)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if got := result.EvalCaseResults[0]; got.FinalEvalStatus != eval.StatusFailed || got.Error == "" {
+		t.Errorf("case result = %+v, want a failure with the error of the run", got)
+	}
+}
+
+func TestNewEvalCase(t *testing.T) {
+	m := adktest.NewModel(t,
+		// Recorded session.
+		adktest.FunctionCall("get_weather", map[string]any{"city": "Paris"}),
+		adktest.Text("It is sunny in Paris."),
+		adktest.Text("You are welcome!"),
+		// Evaluation.
+		adktest.FunctionCall("get_weather", map[string]any{"city": "Paris"}),
+		adktest.Text("It is sunny in Paris."),
+		adktest.Text("You are welcome!"),
+	)
+	a := newWeatherAgent(t, m)
+	h := adktest.NewHarness(t, runner.Config{Agent: a})
+	h.CreateSession("s", map[string]any{"unit": "celsius"})
+	h.Run("s", "What is the weather in Paris?")
+	h.Run("s", "Thanks!")
+
+	c := eval.NewEvalCase("paris", h.Session("s"))
+
+	type turn struct {
+		User, Response string
+		ToolUses       []string
+	}
+	var got []turn
+	for _, inv := range c.Conversation {
+		tr := turn{User: inv.UserContent.Parts[0].Text}
+		if inv.FinalResponse != nil {
+			tr.Response = inv.FinalResponse.Parts[0].Text
+		}
+		for _, fc := range inv.IntermediateData.ToolUses {
+			tr.ToolUses = append(tr.ToolUses, fc.Name)
+		}
+		got = append(got, tr)
+	}
+	want := []turn{
+		{User: "What is the weather in Paris?", Response: "It is sunny in Paris.", ToolUses: []string{"get_weather"}},
+		{User: "Thanks!", Response: "You are welcome!"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewEvalCase() conversation mismatch (-want +got):\n%s", diff)
+	}
+	wantInput := &eval.SessionInput{AppName: "test_app", UserID: adktest.UserID, State: map[string]any{"unit": "celsius"}}
+	if diff := cmp.Diff(wantInput, c.SessionInput); diff != "" {
+		t.Errorf("NewEvalCase() session input mismatch (-want +got):\n%s", diff)
+	}
+
+	// The agent passes its own eval case.
+	result, err := eval.Evaluate(t.Context(), eval.Config{Agent: a}, &eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{c}})
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if !result.Passed() {
+		t.Errorf("Evaluate() of the recorded case failed: %+v", result.EvalCaseResults[0].OverallEvalMetricResults)
+	}
+}
+
+func TestNewEvalCase_FrameworkCalls(t *testing.T) {
+	var turns []adktest.Turn
+	for range 2 { // Recorded session, then evaluation.
+		turns = append(turns,
+			adktest.FunctionCall("transfer_to_agent", map[string]any{"agent_name": "weather"}),
+			adktest.FunctionCall("get_weather", map[string]any{"city": "Paris"}),
+			adktest.Text("It is sunny in Paris."),
+		)
+	}
+	m := adktest.NewModel(t, turns...)
+	root, err := llmagent.New(llmagent.Config{Name: "root", Model: m, SubAgents: []agent.Agent{newWeatherAgent(t, m)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := adktest.NewHarness(t, runner.Config{Agent: root})
+	h.CreateSession("s", nil)
+	h.Run("s", "What is the weather in Paris?")
+
+	// The agent transfer is not a tool call.
+	c := eval.NewEvalCase("paris", h.Session("s"))
+	var got []string
+	for _, fc := range c.Conversation[0].IntermediateData.ToolUses {
+		got = append(got, fc.Name)
+	}
+	if diff := cmp.Diff([]string{"get_weather"}, got); diff != "" {
+		t.Errorf("NewEvalCase() tool uses mismatch (-want +got):\n%s", diff)
+	}
+	result, err := eval.Evaluate(t.Context(), eval.Config{Agent: root}, &eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{c}})
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if !result.Passed() {
+		t.Errorf("Evaluate() of the recorded case failed: %+v", result.EvalCaseResults[0].EvalMetricResultPerInvocation[0].ActualInvocation.IntermediateData)
+	}
+}
+
+func TestTrajectoryScore(t *testing.T) {
+	call := func(name string, city string) *genai.FunctionCall {
+		return &genai.FunctionCall{Name: name, Args: map[string]any{"city": city}}
+	}
+	expected := []*genai.FunctionCall{call("get_weather", "Paris"), call("get_time", "Paris")}
+	tests := []struct {
+		name   string
+		actual []*genai.FunctionCall
+		// expected defaults to the shared expected calls.
+		expected []*genai.FunctionCall
+		match    eval.MatchType
+		want     float64
+	}{
+		{name: "exact", actual: expected, match: eval.MatchExact, want: 1},
+		{name: "exact with other call", actual: append([]*genai.FunctionCall{call("search", "Paris")}, expected...), match: eval.MatchExact, want: 0},
+		{name: "exact with other args", actual: []*genai.FunctionCall{call("get_weather", "Rome"), call("get_time", "Paris")}, match: eval.MatchExact, want: 0},
+		{name: "in order with other call", actual: []*genai.FunctionCall{call("get_weather", "Paris"), call("search", "Paris"), call("get_time", "Paris")}, match: eval.MatchInOrder, want: 1},
+		{name: "in order reversed", actual: []*genai.FunctionCall{call("get_time", "Paris"), call("get_weather", "Paris")}, match: eval.MatchInOrder, want: 0},
+		{name: "any order reversed", actual: []*genai.FunctionCall{call("get_time", "Paris"), call("get_weather", "Paris")}, match: eval.MatchAnyOrder, want: 1},
+		{name: "any order missing call", actual: []*genai.FunctionCall{call("get_time", "Paris")}, match: eval.MatchAnyOrder, want: 0},
+		{
+			name:     "numbers compared in JSON",
+			actual:   []*genai.FunctionCall{{Name: "add", Args: map[string]any{"a": float64(1)}}},
+			expected: []*genai.FunctionCall{{Name: "add", Args: map[string]any{"a": 1}}},
+			match:    eval.MatchExact,
+			want:     1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			want := expected
+			if tc.expected != nil {
+				want = tc.expected
+			}
+			if got := eval.TrajectoryScore(tc.actual, want, tc.match); got != tc.want {
+				t.Errorf("TrajectoryScore() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRouge1(t *testing.T) {
+	tests := []struct {
+		candidate, reference string
+		want                 float64
+	}{
+		{"It is sunny in Paris.", "it is sunny in paris", 1},
+		{"sunny", "It is sunny.", 0.5},
+		{"It is raining.", "Sunny!", 0},
+		{"", "Sunny!", 0},
+	}
+	for _, tc := range tests {
+		if got := eval.Rouge1(tc.candidate, tc.reference); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Rouge1(%q, %q) = %v, want %v", tc.candidate, tc.reference, got, tc.want)
+		}
+	}
+}
+
+func TestEvalSet_Validate(t *testing.T) {
+	user := genai.NewContentFromText("hi", genai.RoleUser)
+	tests := []struct {
+		name string
+		set  eval.EvalSet
+	}{
+		{name: "missing ID", set: eval.EvalSet{}},
+		{name: "missing case ID", set: eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{{}}}},
+		{name: "empty conversation", set: eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{{EvalID: "case"}}}},
+		{name: "duplicate case", set: eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{
+			{EvalID: "case", Conversation: []*eval.Invocation{{UserContent: user}}},
+			{EvalID: "case", Conversation: []*eval.Invocation{{UserContent: user}}},
+		}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.set.Validate(); err == nil {
+				t.Errorf("Validate() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestCriteria_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria eval.Criteria
+		wantErr  bool
+	}{
+		{name: "default", criteria: eval.DefaultCriteria()},
+		{name: "bounds", criteria: eval.Criteria{ToolTrajectory: &eval.TrajectoryCriterion{Threshold: 0}, ResponseMatch: &eval.ResponseCriterion{Threshold: 1}}},
+		{name: "unknown match", criteria: eval.Criteria{ToolTrajectory: &eval.TrajectoryCriterion{Threshold: 1, Match: "SOME"}}, wantErr: true},
+		{name: "trajectory threshold above 1", criteria: eval.Criteria{ToolTrajectory: &eval.TrajectoryCriterion{Threshold: 1.5}}, wantErr: true},
+		{name: "negative response threshold", criteria: eval.Criteria{ResponseMatch: &eval.ResponseCriterion{Threshold: -0.1}}, wantErr: true},
+		{name: "NaN threshold", criteria: eval.Criteria{ResponseMatch: &eval.ResponseCriterion{Threshold: math.NaN()}}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.criteria.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, want error: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
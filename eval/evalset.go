@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eval evaluates agents against eval sets.
+//
+// An eval set is a JSON file of eval cases, each a multi-turn conversation
+// with, for every user message, the expected tool calls of the agent and a
+// reference response. Evaluate runs the cases against an agent, each in a
+// fresh session, and scores the tool trajectories and the responses with
+// the metrics of the Criteria.
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"google.golang.org/genai"
+)
+
+// EvalSet is a set of eval cases.
+type EvalSet struct {
+	EvalSetID   string      `json:"evalSetId"`
+	Name        string      `json:"name,omitempty"`
+	Description string      `json:"description,omitempty"`
+	EvalCases   []*EvalCase `json:"evalCases"`
+	// CreationTimestamp is the creation time, in seconds since the epoch.
+	CreationTimestamp float64 `json:"creationTimestamp,omitempty"`
+}
+
+// EvalCase is a conversation with an agent, and its expected outcome.
+type EvalCase struct {
+	EvalID string `json:"evalId"`
+	// Conversation are the turns of the conversation, in order.
+	Conversation []*Invocation `json:"conversation"`
+	// SessionInput initializes the session of the case.
+	SessionInput *SessionInput `json:"sessionInput,omitempty"`
+}
+
+// Invocation is a turn of a conversation: the message of the user, and the
+// expected tool calls and response of the agent.
+type Invocation struct {
+	InvocationID     string            `json:"invocationId,omitempty"`
+	UserContent      *genai.Content    `json:"userContent"`
+	FinalResponse    *genai.Content    `json:"finalResponse,omitempty"`
+	IntermediateData *IntermediateData `json:"intermediateData,omitempty"`
+}
+
+// IntermediateData are the steps of the agent before its final response.
+type IntermediateData struct {
+	// ToolUses are the tool calls, in order.
+	ToolUses []*genai.FunctionCall `json:"toolUses,omitempty"`
+}
+
+// SessionInput initializes the session of an eval case.
+type SessionInput struct {
+	AppName string         `json:"appName,omitempty"`
+	UserID  string         `json:"userId,omitempty"`
+	State   map[string]any `json:"state,omitempty"`
+}
+
+// LoadEvalSet loads the eval set of the JSON file at path.
+func LoadEvalSet(path string) (*EvalSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eval set: %w", err)
+	}
+	var set EvalSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse eval set %q: %w", path, err)
+	}
+	if err := set.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid eval set %q: %w", path, err)
+	}
+	return &set, nil
+}
+
+// Validate checks that the eval cases have unique IDs and user messages.
+func (s *EvalSet) Validate() error {
+	if s.EvalSetID == "" {
+		return fmt.Errorf("evalSetId is required")
+	}
+	ids := make(map[string]bool)
+	for i, c := range s.EvalCases {
+		if c == nil || c.EvalID == "" {
+			return fmt.Errorf("evalCases[%d]: evalId is required", i)
+		}
+		if ids[c.EvalID] {
+			return fmt.Errorf("duplicate eval case %q", c.EvalID)
+		}
+		ids[c.EvalID] = true
+		if len(c.Conversation) == 0 {
+			return fmt.Errorf("eval case %q: conversation is empty", c.EvalID)
+		}
+		for j, inv := range c.Conversation {
+			if inv == nil || inv.UserContent == nil {
+				return fmt.Errorf("eval case %q: conversation[%d]: userContent is required", c.EvalID, j)
+			}
+		}
+	}
+	return nil
+}
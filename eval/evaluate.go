@@ -0,0 +1,285 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// Config of an evaluation.
+type Config struct {
+	// Agent is the evaluated agent.
+	Agent agent.Agent
+	// AppName is the name of the app of the sessions. It defaults to the
+	// name of the agent.
+	AppName string
+	// SessionService stores the sessions of the eval cases. It defaults to
+	// an in-memory service.
+	SessionService session.Service
+	// ArtifactService is used by the agent. Optional.
+	ArtifactService artifact.Service
+	// MemoryService is used by the agent. Optional.
+	MemoryService memory.Service
+	// Criteria are the evaluated metrics. If no criterion is set,
+	// DefaultCriteria are used.
+	Criteria Criteria
+}
+
+// Status is the outcome of an evaluation.
+type Status string
+
+const (
+	StatusPassed Status = "PASSED"
+	StatusFailed Status = "FAILED"
+	// StatusNotEvaluated is the status of a metric without data, e.g. the
+	// response match of turns without reference responses.
+	StatusNotEvaluated Status = "NOT_EVALUATED"
+)
+
+// Result is the result of the evaluation of an eval set.
+type Result struct {
+	EvalSetResultID string `json:"evalSetResultId"`
+	EvalSetID       string `json:"evalSetId"`
+	// CreationTimestamp is the time of the evaluation, in seconds since the
+	// epoch.
+	CreationTimestamp float64       `json:"creationTimestamp"`
+	EvalCaseResults   []*CaseResult `json:"evalCaseResults"`
+}
+
+// Passed reports whether all the eval cases passed.
+func (r *Result) Passed() bool {
+	for _, c := range r.EvalCaseResults {
+		if c.FinalEvalStatus != StatusPassed {
+			return false
+		}
+	}
+	return true
+}
+
+// CaseResult is the result of an eval case.
+type CaseResult struct {
+	EvalID          string `json:"evalId"`
+	FinalEvalStatus Status `json:"finalEvalStatus"`
+	// Error is the error which stopped the run of the case, if any.
+	Error string `json:"error,omitempty"`
+	// OverallEvalMetricResults are the scores of the case, averaged over
+	// its turns.
+	OverallEvalMetricResults      []*MetricResult     `json:"overallEvalMetricResults"`
+	EvalMetricResultPerInvocation []*InvocationResult `json:"evalMetricResultPerInvocation"`
+	SessionID                     string              `json:"sessionId"`
+	UserID                        string              `json:"userId"`
+}
+
+// InvocationResult is the result of a turn of an eval case.
+type InvocationResult struct {
+	ExpectedInvocation *Invocation     `json:"expectedInvocation"`
+	ActualInvocation   *Invocation     `json:"actualInvocation"`
+	EvalMetricResults  []*MetricResult `json:"evalMetricResults"`
+}
+
+// MetricResult is the score of a metric.
+type MetricResult struct {
+	MetricName string  `json:"metricName"`
+	Score      float64 `json:"score"`
+	Threshold  float64 `json:"threshold"`
+	EvalStatus Status  `json:"evalStatus"`
+}
+
+// Evaluate runs the eval cases of set against the agent of cfg, each in a
+// new session, and scores them. The errors of the runs of the cases are
+// reported in their results; Evaluate fails only if the evaluation cannot
+// start.
+func Evaluate(ctx context.Context, cfg Config, set *EvalSet) (*Result, error) {
+	if cfg.Agent == nil {
+		return nil, fmt.Errorf("agent is required")
+	}
+	if err := set.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid eval set: %w", err)
+	}
+	if cfg.Criteria.ToolTrajectory == nil && cfg.Criteria.ResponseMatch == nil {
+		cfg.Criteria = DefaultCriteria()
+	}
+	if err := cfg.Criteria.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid criteria: %w", err)
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = cfg.Agent.Name()
+	}
+	if cfg.SessionService == nil {
+		cfg.SessionService = session.InMemoryService()
+	}
+	r, err := runner.New(runner.Config{
+		AppName:         cfg.AppName,
+		Agent:           cfg.Agent,
+		SessionService:  cfg.SessionService,
+		ArtifactService: cfg.ArtifactService,
+		MemoryService:   cfg.MemoryService,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runner: %w", err)
+	}
+
+	now := time.Now()
+	result := &Result{
+		EvalSetResultID:   fmt.Sprintf("%s_%s_%d", cfg.AppName, set.EvalSetID, now.Unix()),
+		EvalSetID:         set.EvalSetID,
+		CreationTimestamp: float64(now.UnixMilli()) / 1000,
+	}
+	for _, c := range set.EvalCases {
+		result.EvalCaseResults = append(result.EvalCaseResults, evaluateCase(ctx, &cfg, r, c))
+	}
+	return result, nil
+}
+
+// evaluateCase runs the eval case c with r and scores it.
+func evaluateCase(ctx context.Context, cfg *Config, r *runner.Runner, c *EvalCase) *CaseResult {
+	result := &CaseResult{EvalID: c.EvalID, UserID: "eval_user"}
+	var state map[string]any
+	if in := c.SessionInput; in != nil {
+		if in.UserID != "" {
+			result.UserID = in.UserID
+		}
+		state = in.State
+	}
+	resp, err := cfg.SessionService.Create(ctx, &session.CreateRequest{
+		AppName: cfg.AppName,
+		UserID:  result.UserID,
+		State:   state,
+	})
+	if err != nil {
+		result.FinalEvalStatus = StatusFailed
+		result.Error = fmt.Sprintf("failed to create session: %v", err)
+		return result
+	}
+	result.SessionID = resp.Session.ID()
+
+	for _, expected := range c.Conversation {
+		actual, err := runInvocation(ctx, r, result.UserID, result.SessionID, expected.UserContent)
+		if err != nil {
+			result.FinalEvalStatus = StatusFailed
+			result.Error = err.Error()
+			return result
+		}
+		result.EvalMetricResultPerInvocation = append(result.EvalMetricResultPerInvocation, &InvocationResult{
+			ExpectedInvocation: expected,
+			ActualInvocation:   actual,
+			EvalMetricResults:  scoreInvocation(&cfg.Criteria, actual, expected),
+		})
+	}
+
+	result.FinalEvalStatus = StatusPassed
+	for _, name := range []string{MetricToolTrajectory, MetricResponseMatch} {
+		overall := averageMetric(name, result.EvalMetricResultPerInvocation)
+		if overall == nil {
+			continue
+		}
+		result.OverallEvalMetricResults = append(result.OverallEvalMetricResults, overall)
+		if overall.EvalStatus == StatusFailed {
+			result.FinalEvalStatus = StatusFailed
+		}
+	}
+	return result
+}
+
+// runInvocation runs the agent with the user message content, and returns
+// the turn it produced.
+func runInvocation(ctx context.Context, r *runner.Runner, userID, sessionID string, content *genai.Content) (*Invocation, error) {
+	actual := &Invocation{UserContent: content, IntermediateData: &IntermediateData{}}
+	for ev, err := range r.Run(ctx, userID, sessionID, content, agent.RunConfig{}) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to run the agent: %w", err)
+		}
+		if ev.Partial || ev.Content == nil {
+			continue
+		}
+		actual.InvocationID = ev.InvocationID
+		actual.IntermediateData.ToolUses = appendToolUses(actual.IntermediateData.ToolUses, ev.Content)
+		if ev.IsFinalResponse() && text(ev.Content) != "" {
+			actual.FinalResponse = ev.Content
+		}
+	}
+	return actual, nil
+}
+
+// scoreInvocation returns the scores of the actual turn against the
+// expected one.
+func scoreInvocation(criteria *Criteria, actual, expected *Invocation) []*MetricResult {
+	var results []*MetricResult
+	if c := criteria.ToolTrajectory; c != nil {
+		var want []*genai.FunctionCall
+		if expected.IntermediateData != nil {
+			want = expected.IntermediateData.ToolUses
+		}
+		score := TrajectoryScore(actual.IntermediateData.ToolUses, want, c.Match)
+		results = append(results, newMetricResult(MetricToolTrajectory, score, c.Threshold))
+	}
+	if c := criteria.ResponseMatch; c != nil {
+		if expected.FinalResponse == nil {
+			results = append(results, &MetricResult{MetricName: MetricResponseMatch, Threshold: c.Threshold, EvalStatus: StatusNotEvaluated})
+		} else {
+			score := Rouge1(text(actual.FinalResponse), text(expected.FinalResponse))
+			results = append(results, newMetricResult(MetricResponseMatch, score, c.Threshold))
+		}
+	}
+	return results
+}
+
+// averageMetric returns the average of the evaluated scores of the metric
+// name over the turns, or nil if the metric is not evaluated.
+func averageMetric(name string, invocations []*InvocationResult) *MetricResult {
+	var (
+		sum       float64
+		n         int
+		threshold float64
+		found     bool
+	)
+	for _, inv := range invocations {
+		for _, m := range inv.EvalMetricResults {
+			if m.MetricName != name {
+				continue
+			}
+			found = true
+			threshold = m.Threshold
+			if m.EvalStatus != StatusNotEvaluated {
+				sum += m.Score
+				n++
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	if n == 0 {
+		return &MetricResult{MetricName: name, Threshold: threshold, EvalStatus: StatusNotEvaluated}
+	}
+	return newMetricResult(name, sum/float64(n), threshold)
+}
+
+func newMetricResult(name string, score, threshold float64) *MetricResult {
+	status := StatusPassed
+	if score < threshold {
+		status = StatusFailed
+	}
+	return &MetricResult{MetricName: name, Score: score, Threshold: threshold, EvalStatus: status}
+}
@@ -0,0 +1,234 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"google.golang.org/genai"
+)
+
+// Names of the metrics.
+const (
+	// MetricToolTrajectory is the average, over the turns, of the match of
+	// the tool calls of the agent with the expected ones, see
+	// TrajectoryScore.
+	MetricToolTrajectory = "tool_trajectory_avg_score"
+	// MetricResponseMatch is the average, over the turns, of the ROUGE-1
+	// similarity of the final response of the agent with the reference
+	// one, see Rouge1.
+	MetricResponseMatch = "response_match_score"
+)
+
+// MatchType is how the tool calls of the agent are matched against the
+// expected ones.
+type MatchType string
+
+const (
+	// MatchExact requires the expected calls, in order, and no others.
+	MatchExact MatchType = "EXACT"
+	// MatchInOrder requires the expected calls, in order, but allows other
+	// calls in between.
+	MatchInOrder MatchType = "IN_ORDER"
+	// MatchAnyOrder requires the expected calls, in any order, but allows
+	// other calls.
+	MatchAnyOrder MatchType = "ANY_ORDER"
+)
+
+// Criteria are the metrics evaluated and their thresholds, between 0 and 1.
+// A metric passes if its score is at least its threshold.
+type Criteria struct {
+	// ToolTrajectory enables MetricToolTrajectory.
+	ToolTrajectory *TrajectoryCriterion `json:"toolTrajectory,omitempty"`
+	// ResponseMatch enables MetricResponseMatch.
+	ResponseMatch *ResponseCriterion `json:"responseMatch,omitempty"`
+}
+
+// TrajectoryCriterion configures MetricToolTrajectory.
+type TrajectoryCriterion struct {
+	Threshold float64 `json:"threshold"`
+	// Match defaults to MatchExact.
+	Match MatchType `json:"match,omitempty"`
+}
+
+// ResponseCriterion configures MetricResponseMatch.
+type ResponseCriterion struct {
+	Threshold float64 `json:"threshold"`
+}
+
+// DefaultCriteria returns the criteria requiring the exact tool calls and a
+// response match score of 0.8.
+func DefaultCriteria() Criteria {
+	return Criteria{
+		ToolTrajectory: &TrajectoryCriterion{Threshold: 1, Match: MatchExact},
+		ResponseMatch:  &ResponseCriterion{Threshold: 0.8},
+	}
+}
+
+// LoadCriteria loads the criteria of the JSON file at path.
+func LoadCriteria(path string) (Criteria, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Criteria{}, fmt.Errorf("failed to read criteria: %w", err)
+	}
+	var c Criteria
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Criteria{}, fmt.Errorf("failed to parse criteria %q: %w", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return Criteria{}, fmt.Errorf("invalid criteria %q: %w", path, err)
+	}
+	return c, nil
+}
+
+// Validate checks that the tool trajectory match is known and that the
+// thresholds are between 0 and 1.
+func (c *Criteria) Validate() error {
+	if t := c.ToolTrajectory; t != nil {
+		switch t.Match {
+		case "", MatchExact, MatchInOrder, MatchAnyOrder:
+		default:
+			return fmt.Errorf("unknown tool trajectory match %q", t.Match)
+		}
+		if err := validateThreshold(MetricToolTrajectory, t.Threshold); err != nil {
+			return err
+		}
+	}
+	if r := c.ResponseMatch; r != nil {
+		if err := validateThreshold(MetricResponseMatch, r.Threshold); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateThreshold fails if the threshold of the metric is not a score,
+// between 0 and 1: the metric would always or never pass.
+func validateThreshold(metric string, threshold float64) error {
+	if !(threshold >= 0 && threshold <= 1) {
+		return fmt.Errorf("threshold %v of %s is not between 0 and 1", threshold, metric)
+	}
+	return nil
+}
+
+// TrajectoryScore returns 1 if the actual tool calls match the expected
+// ones according to match, and 0 otherwise. Two calls match if they have
+// the same name and arguments; their IDs are ignored.
+func TrajectoryScore(actual, expected []*genai.FunctionCall, match MatchType) float64 {
+	same := func(a, b *genai.FunctionCall) bool {
+		return a.Name == b.Name && marshalArgs(a.Args) == marshalArgs(b.Args)
+	}
+	ok := false
+	switch match {
+	case "", MatchExact:
+		ok = len(actual) == len(expected)
+		for i := 0; ok && i < len(actual); i++ {
+			ok = same(actual[i], expected[i])
+		}
+	case MatchInOrder:
+		i := 0
+		for _, a := range actual {
+			if i < len(expected) && same(a, expected[i]) {
+				i++
+			}
+		}
+		ok = i == len(expected)
+	case MatchAnyOrder:
+		used := make([]bool, len(actual))
+		ok = true
+		for _, e := range expected {
+			found := false
+			for i, a := range actual {
+				if !used[i] && same(a, e) {
+					used[i], found = true, true
+					break
+				}
+			}
+			if !found {
+				ok = false
+				break
+			}
+		}
+	}
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+// marshalArgs returns the JSON encoding of args, so that e.g. the integers
+// of an eval set match the float64 of a model response.
+func marshalArgs(args map[string]any) string {
+	if len(args) == 0 {
+		return "{}"
+	}
+	b, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprint(args)
+	}
+	return string(b)
+}
+
+// Rouge1 returns the ROUGE-1 F-measure of candidate against reference: the
+// harmonic mean of the precision and the recall of their common words. The
+// words are compared in lower case, without stemming.
+func Rouge1(candidate, reference string) float64 {
+	cand, ref := tokenize(candidate), tokenize(reference)
+	if len(cand) == 0 || len(ref) == 0 {
+		return 0
+	}
+	counts := make(map[string]int)
+	for _, w := range ref {
+		counts[w]++
+	}
+	overlap := 0
+	for _, w := range cand {
+		if counts[w] > 0 {
+			counts[w]--
+			overlap++
+		}
+	}
+	if overlap == 0 {
+		return 0
+	}
+	precision := float64(overlap) / float64(len(cand))
+	recall := float64(overlap) / float64(len(ref))
+	return 2 * precision * recall / (precision + recall)
+}
+
+// tokenize returns the lower case words of s.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// text returns the concatenated text parts of c, excluding the thoughts.
+func text(c *genai.Content) string {
+	if c == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, p := range c.Parts {
+		if !p.Thought {
+			b.WriteString(p.Text)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"maps"
+
+	"google.golang.org/adk/auth"
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/toolconfirmation"
+	"google.golang.org/genai"
+)
+
+// NewEvalCase returns the eval case evalID recording the conversation of
+// the session s: each message of the user starts a turn, whose expected
+// tool calls and final response are those of the agent in s. The calls of
+// the framework, e.g. agent transfers, are not tool calls. The session
+// input of the case has the app, the user and the state of s.
+func NewEvalCase(evalID string, s session.Session) *EvalCase {
+	c := &EvalCase{
+		EvalID: evalID,
+		SessionInput: &SessionInput{
+			AppName: s.AppName(),
+			UserID:  s.UserID(),
+			State:   maps.Collect(s.State().All()),
+		},
+	}
+	var current *Invocation
+	for ev := range s.Events().All() {
+		if ev.Partial || ev.Content == nil {
+			continue
+		}
+		// The function responses of the user, e.g. tool confirmations,
+		// continue the turn.
+		if ev.Author == "user" && !hasFunctionResponse(ev) {
+			current = &Invocation{
+				InvocationID:     ev.InvocationID,
+				UserContent:      ev.Content,
+				IntermediateData: &IntermediateData{},
+			}
+			c.Conversation = append(c.Conversation, current)
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		current.IntermediateData.ToolUses = appendToolUses(current.IntermediateData.ToolUses, ev.Content)
+		if ev.IsFinalResponse() && text(ev.Content) != "" {
+			current.FinalResponse = ev.Content
+		}
+	}
+	return c
+}
+
+func hasFunctionResponse(ev *session.Event) bool {
+	for _, p := range ev.Content.Parts {
+		if p.FunctionResponse != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// appendToolUses appends the tool calls of the content to uses. The calls
+// of the framework, i.e. agent transfers and requests of tool confirmations
+// or credentials, are skipped: they depend on the conversation rather than
+// on the tools used by the agent.
+func appendToolUses(uses []*genai.FunctionCall, content *genai.Content) []*genai.FunctionCall {
+	for _, p := range content.Parts {
+		if p.FunctionCall == nil {
+			continue
+		}
+		switch p.FunctionCall.Name {
+		case llminternal.TransferToAgentFunctionName, toolconfirmation.FunctionName, auth.RequestCredentialFunctionName:
+			continue
+		}
+		uses = append(uses, p.FunctionCall)
+	}
+	return uses
+}
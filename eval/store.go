@@ -0,0 +1,288 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/adk/internal/fileutil"
+)
+
+// Store persists the eval sets and the results of their evaluations, by
+// app.
+type Store interface {
+	// GetEvalSet returns the eval set of the app, or nil if it does not
+	// exist.
+	GetEvalSet(ctx context.Context, appName, evalSetID string) (*EvalSet, error)
+	// ListEvalSets returns the sorted IDs of the eval sets of the app.
+	ListEvalSets(ctx context.Context, appName string) ([]string, error)
+	// SaveEvalSet creates or replaces the eval set of the app.
+	SaveEvalSet(ctx context.Context, appName string, set *EvalSet) error
+
+	// GetResult returns the result of the app, or nil if it does not exist.
+	GetResult(ctx context.Context, appName, resultID string) (*Result, error)
+	// ListResults returns the sorted IDs of the results of the app.
+	ListResults(ctx context.Context, appName string) ([]string, error)
+	// SaveResult creates or replaces the result of the app.
+	SaveResult(ctx context.Context, appName string, result *Result) error
+}
+
+// NewMemoryStore returns a [Store] keeping the eval sets and the results in
+// memory.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		sets:    make(map[memoryKey][]byte),
+		results: make(map[memoryKey][]byte),
+	}
+}
+
+type memoryKey struct {
+	appName, id string
+}
+
+type memoryStore struct {
+	mu sync.Mutex
+	// sets and results hold the JSON encodings, so that the values returned
+	// can be modified.
+	sets    map[memoryKey][]byte
+	results map[memoryKey][]byte
+}
+
+func (s *memoryStore) GetEvalSet(_ context.Context, appName, evalSetID string) (*EvalSet, error) {
+	s.mu.Lock()
+	data, ok := s.sets[memoryKey{appName, evalSetID}]
+	s.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	return decode[EvalSet](data, "eval set")
+}
+
+func (s *memoryStore) ListEvalSets(_ context.Context, appName string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return listKeys(s.sets, appName), nil
+}
+
+func (s *memoryStore) SaveEvalSet(_ context.Context, appName string, set *EvalSet) error {
+	data, err := json.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("failed to encode eval set: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sets[memoryKey{appName, set.EvalSetID}] = data
+	return nil
+}
+
+func (s *memoryStore) GetResult(_ context.Context, appName, resultID string) (*Result, error) {
+	s.mu.Lock()
+	data, ok := s.results[memoryKey{appName, resultID}]
+	s.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	return decode[Result](data, "eval result")
+}
+
+func (s *memoryStore) ListResults(_ context.Context, appName string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return listKeys(s.results, appName), nil
+}
+
+func (s *memoryStore) SaveResult(_ context.Context, appName string, result *Result) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode eval result: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[memoryKey{appName, result.EvalSetResultID}] = data
+	return nil
+}
+
+func listKeys(m map[memoryKey][]byte, appName string) []string {
+	ids := []string{}
+	for k := range m {
+		if k.appName == appName {
+			ids = append(ids, k.id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Suffixes of the files of the local directory store.
+const (
+	evalSetFileSuffix = ".evalset.json"
+	resultFileSuffix  = ".evalset_result.json"
+)
+
+// NewFileStore returns a [Store] keeping the eval sets and the results in
+// JSON files of the directory, created if needed: the eval sets in
+// <dir>/<app>/<eval set ID>.evalset.json, loadable with LoadEvalSet, and the
+// results in <dir>/<app>/eval_results/<result ID>.evalset_result.json.
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create eval directory: %w", err)
+	}
+	return &fileStore{dir: dir}, nil
+}
+
+type fileStore struct {
+	dir string
+}
+
+// checkNames fails if one of the names is not a plain file name, so that
+// the files stay in the directory.
+func checkNames(names ...string) error {
+	for _, n := range names {
+		if n == "" || n == "." || n == ".." || strings.ContainsAny(n, `/\`) {
+			return fmt.Errorf("invalid name %q", n)
+		}
+	}
+	return nil
+}
+
+func (s *fileStore) evalSetPath(appName, evalSetID string) (string, error) {
+	if err := checkNames(appName, evalSetID); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, appName, evalSetID+evalSetFileSuffix), nil
+}
+
+func (s *fileStore) resultsDir(appName string) (string, error) {
+	if err := checkNames(appName); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, appName, "eval_results"), nil
+}
+
+func (s *fileStore) resultPath(appName, resultID string) (string, error) {
+	dir, err := s.resultsDir(appName)
+	if err != nil {
+		return "", err
+	}
+	if err := checkNames(resultID); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, resultID+resultFileSuffix), nil
+}
+
+func (s *fileStore) GetEvalSet(_ context.Context, appName, evalSetID string) (*EvalSet, error) {
+	path, err := s.evalSetPath(appName, evalSetID)
+	if err != nil {
+		return nil, err
+	}
+	return readFile[EvalSet](path, "eval set")
+}
+
+func (s *fileStore) ListEvalSets(_ context.Context, appName string) ([]string, error) {
+	if err := checkNames(appName); err != nil {
+		return nil, err
+	}
+	return listFiles(filepath.Join(s.dir, appName), evalSetFileSuffix)
+}
+
+func (s *fileStore) SaveEvalSet(_ context.Context, appName string, set *EvalSet) error {
+	path, err := s.evalSetPath(appName, set.EvalSetID)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, set, "eval set")
+}
+
+func (s *fileStore) GetResult(_ context.Context, appName, resultID string) (*Result, error) {
+	path, err := s.resultPath(appName, resultID)
+	if err != nil {
+		return nil, err
+	}
+	return readFile[Result](path, "eval result")
+}
+
+func (s *fileStore) ListResults(_ context.Context, appName string) ([]string, error) {
+	dir, err := s.resultsDir(appName)
+	if err != nil {
+		return nil, err
+	}
+	return listFiles(dir, resultFileSuffix)
+}
+
+func (s *fileStore) SaveResult(_ context.Context, appName string, result *Result) error {
+	path, err := s.resultPath(appName, result.EvalSetResultID)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, result, "eval result")
+}
+
+func readFile[T any](path, kind string) (*T, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", kind, err)
+	}
+	return decode[T](data, kind)
+}
+
+func writeFile(path string, v any, kind string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", kind, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", kind, err)
+	}
+	if err := fileutil.WriteAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", kind, err)
+	}
+	return nil
+}
+
+// listFiles returns the sorted names, without the suffix, of the files of
+// dir with the suffix.
+func listFiles(dir, suffix string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	ids := []string{}
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), suffix); ok && !e.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func decode[T any](data []byte, kind string) (*T, error) {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", kind, err)
+	}
+	return &v, nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eval_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/eval"
+	"google.golang.org/genai"
+)
+
+func TestStore(t *testing.T) {
+	tests := []struct {
+		name     string
+		newStore func(t *testing.T) eval.Store
+	}{
+		{
+			name:     "memory",
+			newStore: func(t *testing.T) eval.Store { return eval.NewMemoryStore() },
+		},
+		{
+			name: "file",
+			newStore: func(t *testing.T) eval.Store {
+				s, err := eval.NewFileStore(t.TempDir())
+				if err != nil {
+					t.Fatalf("NewFileStore() error = %v", err)
+				}
+				return s
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testStore(t, tc.newStore(t))
+		})
+	}
+}
+
+func testStore(t *testing.T, s eval.Store) {
+	ctx := t.Context()
+	set := &eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{{
+		EvalID:       "case",
+		Conversation: []*eval.Invocation{{UserContent: genai.NewContentFromText("hi", genai.RoleUser)}},
+	}}}
+	result := &eval.Result{EvalSetResultID: "app_set_1", EvalSetID: "set", EvalCaseResults: []*eval.CaseResult{
+		{EvalID: "case", FinalEvalStatus: eval.StatusPassed},
+	}}
+
+	if got, err := s.GetEvalSet(ctx, "app", "set"); err != nil || got != nil {
+		t.Errorf("GetEvalSet() of a missing set = (%v, %v), want (nil, nil)", got, err)
+	}
+	if got, err := s.GetResult(ctx, "app", "app_set_1"); err != nil || got != nil {
+		t.Errorf("GetResult() of a missing result = (%v, %v), want (nil, nil)", got, err)
+	}
+	for _, id := range []string{"b", "a"} {
+		if err := s.SaveEvalSet(ctx, "app", &eval.EvalSet{EvalSetID: id}); err != nil {
+			t.Fatalf("SaveEvalSet(%q) error = %v", id, err)
+		}
+	}
+	if err := s.SaveEvalSet(ctx, "app", set); err != nil {
+		t.Fatalf("SaveEvalSet() error = %v", err)
+	}
+	if err := s.SaveEvalSet(ctx, "other_app", &eval.EvalSet{EvalSetID: "other"}); err != nil {
+		t.Fatalf("SaveEvalSet() error = %v", err)
+	}
+	if err := s.SaveResult(ctx, "app", result); err != nil {
+		t.Fatalf("SaveResult() error = %v", err)
+	}
+
+	got, err := s.GetEvalSet(ctx, "app", "set")
+	if err != nil {
+		t.Fatalf("GetEvalSet() error = %v", err)
+	}
+	if diff := cmp.Diff(set, got); diff != "" {
+		t.Errorf("GetEvalSet() mismatch (-want +got):\n%s", diff)
+	}
+	ids, err := s.ListEvalSets(ctx, "app")
+	if err != nil {
+		t.Fatalf("ListEvalSets() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "set"}, ids); diff != "" {
+		t.Errorf("ListEvalSets() mismatch (-want +got):\n%s", diff)
+	}
+
+	gotResult, err := s.GetResult(ctx, "app", "app_set_1")
+	if err != nil {
+		t.Fatalf("GetResult() error = %v", err)
+	}
+	if diff := cmp.Diff(result, gotResult); diff != "" {
+		t.Errorf("GetResult() mismatch (-want +got):\n%s", diff)
+	}
+	ids, err = s.ListResults(ctx, "app")
+	if err != nil {
+		t.Fatalf("ListResults() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"app_set_1"}, ids); diff != "" {
+		t.Errorf("ListResults() mismatch (-want +got):\n%s", diff)
+	}
+	if ids, err := s.ListResults(ctx, "other_app"); err != nil || len(ids) != 0 {
+		t.Errorf("ListResults() of another app = (%v, %v), want no results", ids, err)
+	}
+
+	// The returned values are copies.
+	got.EvalCases = nil
+	if got, err := s.GetEvalSet(ctx, "app", "set"); err != nil || len(got.EvalCases) != 1 {
+		t.Errorf("GetEvalSet() after modifying the returned set = (%v, %v), want the saved set", got, err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := eval.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	set := &eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{{
+		EvalID:       "case",
+		Conversation: []*eval.Invocation{{UserContent: genai.NewContentFromText("hi", genai.RoleUser)}},
+	}}}
+	if err := s.SaveEvalSet(t.Context(), "app", set); err != nil {
+		t.Fatalf("SaveEvalSet() error = %v", err)
+	}
+	// The eval sets are files of the eval set format.
+	if _, err := eval.LoadEvalSet(filepath.Join(dir, "app", "set.evalset.json")); err != nil {
+		t.Errorf("LoadEvalSet() error = %v", err)
+	}
+
+	for _, id := range []string{"../set", "..", ""} {
+		if err := s.SaveEvalSet(t.Context(), "app", &eval.EvalSet{EvalSetID: id}); err == nil {
+			t.Errorf("SaveEvalSet(%q) succeeded, want an error", id)
+		}
+	}
+	if _, err := s.ListEvalSets(t.Context(), "../app"); err == nil {
+		t.Errorf("ListEvalSets() of an app outside of the directory succeeded, want an error")
+	}
+}
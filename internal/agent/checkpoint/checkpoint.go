@@ -0,0 +1,142 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package checkpoint records the progress of the workflow agents in the
+// session events, so that an interrupted invocation can be resumed.
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+)
+
+// State holds the checkpoints of a resumable invocation.
+//
+// A nil *State means that the invocation is not resumable: no checkpoint is
+// recorded.
+type State struct {
+	resumed bool
+
+	mu     sync.Mutex
+	states map[string]map[string]any
+}
+
+// New returns the state of a new resumable invocation.
+func New() *State {
+	return &State{states: make(map[string]map[string]any)}
+}
+
+// FromEvents rebuilds the state of the invocation from its events, in the
+// order they were recorded.
+//
+// The checkpoint of an agent is its last recorded one, unless the agent
+// recorded its end afterwards, e.g. a loop agent that completed an iteration
+// of its parent loop agent.
+func FromEvents(events []*session.Event) *State {
+	s := New()
+	s.resumed = true
+	for _, ev := range events {
+		if ev.Actions.AgentState != nil {
+			s.states[ev.Author] = ev.Actions.AgentState
+		}
+		if ev.Actions.EndOfAgent {
+			delete(s.states, ev.Author)
+		}
+	}
+	return s
+}
+
+// Resumed reports whether the invocation is resumed from its events.
+func (s *State) Resumed() bool {
+	return s != nil && s.resumed
+}
+
+// Take decodes the checkpoint of the agent into v and removes it, so that
+// the agent resumes from it only once. It reports whether the agent has a
+// checkpoint.
+func (s *State) Take(agentName string, v any) (bool, error) {
+	if s == nil {
+		return false, nil
+	}
+	s.mu.Lock()
+	state, ok := s.states[agentName]
+	delete(s.states, agentName)
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	b, err := json.Marshal(state)
+	if err == nil {
+		err = json.Unmarshal(b, v)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to decode checkpoint of agent %q: %w", agentName, err)
+	}
+	return true, nil
+}
+
+// NewEvent returns the event recording the checkpoint of the agent of ctx.
+func NewEvent(ctx agent.InvocationContext, v any) (*session.Event, error) {
+	var state map[string]any
+	b, err := json.Marshal(v)
+	if err == nil {
+		err = json.Unmarshal(b, &state)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode checkpoint of agent %q: %w", ctx.Agent().Name(), err)
+	}
+	ev := newEvent(ctx)
+	ev.Actions.AgentState = state
+	return ev, nil
+}
+
+// NewEndEvent returns the event recording that the agent of ctx completed
+// its run.
+func NewEndEvent(ctx agent.InvocationContext) *session.Event {
+	ev := newEvent(ctx)
+	ev.Actions.EndOfAgent = true
+	return ev
+}
+
+func newEvent(ctx agent.InvocationContext) *session.Event {
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	return ev
+}
+
+// ToContext returns a copy of ctx holding the checkpoint state. A nil state
+// disables the checkpoints, e.g. for the invocations of an agent tool.
+func ToContext(ctx context.Context, s *State) context.Context {
+	return context.WithValue(ctx, stateCtxKey, s)
+}
+
+// FromContext returns the checkpoint state of the invocation, or nil if it
+// is not resumable.
+func FromContext(ctx context.Context) *State {
+	s, ok := ctx.Value(stateCtxKey).(*State)
+	if !ok {
+		return nil
+	}
+	return s
+}
+
+type ctxKey int
+
+const stateCtxKey ctxKey = 0
@@ -0,0 +1,67 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoint_test
+
+import (
+	"testing"
+
+	"google.golang.org/adk/internal/agent/checkpoint"
+	"google.golang.org/adk/session"
+)
+
+func TestFromEvents(t *testing.T) {
+	type loopState struct {
+		Iteration int `json:"iteration"`
+		SubAgent  int `json:"sub_agent"`
+	}
+	event := func(author string, state map[string]any, end bool) *session.Event {
+		return &session.Event{Author: author, Actions: session.EventActions{AgentState: state, EndOfAgent: end}}
+	}
+	s := checkpoint.FromEvents([]*session.Event{
+		{Author: "user"},
+		event("outer", map[string]any{"iteration": 0.0, "sub_agent": 0.0}, false),
+		event("inner", map[string]any{"iteration": 4.0, "sub_agent": 1.0}, false),
+		// The inner loop completed an iteration of the outer loop.
+		event("inner", nil, true),
+		event("outer", map[string]any{"iteration": 1.0, "sub_agent": 0.0}, false),
+		event("worker", nil, false),
+	})
+	if !s.Resumed() {
+		t.Errorf("Resumed() = false, want true")
+	}
+
+	var got loopState
+	if ok, err := s.Take("outer", &got); err != nil || !ok {
+		t.Fatalf("Take(outer) = (%v, %v), want (true, nil)", ok, err)
+	}
+	if want := (loopState{Iteration: 1}); got != want {
+		t.Errorf("Take(outer) state = %+v, want %+v", got, want)
+	}
+	// A checkpoint is resumed from only once.
+	if ok, err := s.Take("outer", &got); err != nil || ok {
+		t.Errorf("second Take(outer) = (%v, %v), want (false, nil)", ok, err)
+	}
+	if ok, err := s.Take("inner", &got); err != nil || ok {
+		t.Errorf("Take(inner) = (%v, %v), want (false, nil)", ok, err)
+	}
+
+	var disabled *checkpoint.State
+	if ok, err := disabled.Take("outer", &got); err != nil || ok {
+		t.Errorf("nil State Take() = (%v, %v), want (false, nil)", ok, err)
+	}
+	if disabled.Resumed() || checkpoint.New().Resumed() {
+		t.Errorf("Resumed() = true for an invocation that is not resumed")
+	}
+}
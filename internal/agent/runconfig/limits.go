@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"google.golang.org/adk/agent"
+)
+
+// Limits enforces the invocation limits of an agent.RunConfig. It is shared
+// by all the agents run by the invocation.
+//
+// The limits of the parent invocation, e.g. the one calling an agent tool,
+// also apply: the calls are counted against both.
+//
+// A nil *Limits enforces no limit.
+type Limits struct {
+	parent       *Limits
+	maxLLMCalls  int64
+	maxToolCalls int64
+
+	llmCalls  atomic.Int64
+	toolCalls atomic.Int64
+}
+
+// NewLimits returns the limits of an invocation run with cfg.
+func NewLimits(parent *Limits, cfg *agent.RunConfig) *Limits {
+	return &Limits{
+		parent:       parent,
+		maxLLMCalls:  int64(cfg.MaxLLMCalls),
+		maxToolCalls: int64(cfg.MaxToolCalls),
+	}
+}
+
+// WithDeadline returns a copy of ctx that is cancelled once the MaxDuration
+// of cfg elapses. The cause of the cancellation is an
+// *agent.LimitExceededError.
+func WithDeadline(ctx context.Context, cfg *agent.RunConfig) (context.Context, context.CancelFunc) {
+	if cfg.MaxDuration <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, cfg.MaxDuration, &agent.LimitExceededError{
+		Code:    agent.ErrorCodeMaxDurationExceeded,
+		Message: fmt.Sprintf("invocation exceeded the maximum duration of %v", cfg.MaxDuration),
+	})
+}
+
+// CountLLMCall records a call to the model. It returns an
+// *agent.LimitExceededError if the call exceeds the limits.
+func (l *Limits) CountLLMCall(ctx context.Context) error {
+	if err := LimitExceeded(ctx, nil); err != nil {
+		return err
+	}
+	for cur := l; cur != nil; cur = cur.parent {
+		if n := cur.llmCalls.Add(1); cur.maxLLMCalls > 0 && n > cur.maxLLMCalls {
+			return &agent.LimitExceededError{
+				Code:    agent.ErrorCodeMaxLLMCallsExceeded,
+				Message: fmt.Sprintf("invocation exceeded the maximum number of LLM calls (%d)", cur.maxLLMCalls),
+			}
+		}
+	}
+	return nil
+}
+
+// CountToolCalls records n function calls. It returns an
+// *agent.LimitExceededError if the calls exceed the limits.
+func (l *Limits) CountToolCalls(ctx context.Context, n int) error {
+	if err := LimitExceeded(ctx, nil); err != nil {
+		return err
+	}
+	for cur := l; cur != nil; cur = cur.parent {
+		if total := cur.toolCalls.Add(int64(n)); cur.maxToolCalls > 0 && total > cur.maxToolCalls {
+			return &agent.LimitExceededError{
+				Code:    agent.ErrorCodeMaxToolCallsExceeded,
+				Message: fmt.Sprintf("invocation exceeded the maximum number of tool calls (%d)", cur.maxToolCalls),
+			}
+		}
+	}
+	return nil
+}
+
+// LimitExceeded returns the *agent.LimitExceededError behind err, or behind
+// the cancellation of ctx. It returns nil if neither is due to a limit.
+func LimitExceeded(ctx context.Context, err error) *agent.LimitExceededError {
+	var limitErr *agent.LimitExceededError
+	if err != nil && errors.As(err, &limitErr) {
+		return limitErr
+	}
+	if cause := context.Cause(ctx); cause != nil && errors.As(cause, &limitErr) {
+		return limitErr
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runconfig_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/agent/runconfig"
+)
+
+func TestLimits(t *testing.T) {
+	ctx := t.Context()
+	parent := runconfig.NewLimits(nil, &agent.RunConfig{MaxLLMCalls: 3, MaxToolCalls: 4})
+	child := runconfig.NewLimits(parent, &agent.RunConfig{MaxToolCalls: 10})
+
+	// Calls of the child invocation count against the parent limits.
+	for range 2 {
+		if err := child.CountLLMCall(ctx); err != nil {
+			t.Fatalf("child.CountLLMCall() error = %v", err)
+		}
+	}
+	if err := parent.CountLLMCall(ctx); err != nil {
+		t.Fatalf("parent.CountLLMCall() error = %v", err)
+	}
+	err := child.CountLLMCall(ctx)
+	if limitErr := runconfig.LimitExceeded(ctx, err); limitErr == nil || limitErr.Code != agent.ErrorCodeMaxLLMCallsExceeded {
+		t.Errorf("child.CountLLMCall() error = %v, want %s", err, agent.ErrorCodeMaxLLMCallsExceeded)
+	}
+
+	if err := child.CountToolCalls(ctx, 4); err != nil {
+		t.Fatalf("child.CountToolCalls() error = %v", err)
+	}
+	err = child.CountToolCalls(ctx, 1)
+	if limitErr := runconfig.LimitExceeded(ctx, err); limitErr == nil || limitErr.Code != agent.ErrorCodeMaxToolCallsExceeded {
+		t.Errorf("child.CountToolCalls() error = %v, want %s", err, agent.ErrorCodeMaxToolCallsExceeded)
+	}
+
+	var unlimited *runconfig.Limits
+	if err := unlimited.CountLLMCall(ctx); err != nil {
+		t.Errorf("nil Limits CountLLMCall() error = %v", err)
+	}
+}
+
+func TestWithDeadline(t *testing.T) {
+	ctx, cancel := runconfig.WithDeadline(t.Context(), &agent.RunConfig{MaxDuration: time.Millisecond})
+	defer cancel()
+	<-ctx.Done()
+
+	if limitErr := runconfig.LimitExceeded(ctx, context.DeadlineExceeded); limitErr == nil || limitErr.Code != agent.ErrorCodeMaxDurationExceeded {
+		t.Errorf("LimitExceeded() = %v, want %s", limitErr, agent.ErrorCodeMaxDurationExceeded)
+	}
+	var limits *runconfig.Limits
+	if err := limits.CountLLMCall(ctx); err == nil {
+		t.Errorf("CountLLMCall() after the deadline succeeded, want error")
+	}
+
+	ctx, cancel = runconfig.WithDeadline(t.Context(), &agent.RunConfig{})
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Errorf("WithDeadline() without MaxDuration set a deadline")
+	}
+}
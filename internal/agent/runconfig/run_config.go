@@ -14,7 +14,11 @@
 
 package runconfig
 
-import "context"
+import (
+	"context"
+
+	"google.golang.org/adk/agent"
+)
 
 type StreamingMode string
 
@@ -26,6 +30,10 @@ const (
 
 type RunConfig struct {
 	StreamingMode StreamingMode
+	// LiveRequestQueue is set in bidi streaming mode.
+	LiveRequestQueue *agent.LiveRequestQueue
+	// Limits enforces the invocation limits.
+	Limits *Limits
 }
 
 func ToContext(ctx context.Context, cfg *RunConfig) context.Context {
@@ -0,0 +1,316 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth implements the credential request and exchange logic used by
+// the tool authentication flow.
+//
+// reference: adk-python src/google/adk/auth/auth_handler.py
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"google.golang.org/adk/auth"
+	"google.golang.org/adk/session"
+)
+
+// StateKey returns the session state key of the credential for the config.
+//
+// Credentials are kept in user state, so they are persisted by the session
+// service and reused by the later invocations and sessions of the user
+// without another consent.
+func StateKey(cfg *auth.Config) string {
+	return session.KeyPrefixUser + cfg.Key()
+}
+
+// ResponseStateKey returns the temporary session state key of the client
+// response to the credential request for the config. The response is
+// exchanged for the credential on the next call of the tool, with the
+// secrets of the tool's own config.
+func ResponseStateKey(cfg *auth.Config) string {
+	return session.KeyPrefixTemp + cfg.Key()
+}
+
+// Response returns the credential provided by the client in resp, its
+// response to the credential request req. Only the fields set by the client
+// are taken from resp: the rest of the config, e.g. the token URL, comes
+// from req, which resp must match.
+func Response(req, resp *auth.Config) (*auth.Credential, error) {
+	if req == nil || req.Scheme == nil {
+		return nil, fmt.Errorf("credential request has no auth scheme")
+	}
+	if resp == nil || resp.ExchangedCredential == nil {
+		return nil, fmt.Errorf("credential response has no exchanged credential")
+	}
+	if resp.Key() != req.Key() {
+		return nil, fmt.Errorf("credential response key %q does not match the request key %q", resp.Key(), req.Key())
+	}
+	var tokenURL string
+	if resp.Scheme != nil {
+		tokenURL = resp.Scheme.TokenURL
+	}
+	if tokenURL != req.Scheme.TokenURL {
+		return nil, fmt.Errorf("credential response token URL %q does not match the request", tokenURL)
+	}
+	ex := resp.ExchangedCredential
+	cred := &auth.Credential{APIKey: ex.APIKey, Token: ex.Token}
+	if o := ex.OAuth2; o != nil {
+		cred.OAuth2 = &auth.OAuth2{
+			AuthResponseURI: o.AuthResponseURI,
+			AuthCode:        o.AuthCode,
+			AccessToken:     o.AccessToken,
+			RefreshToken:    o.RefreshToken,
+			Expiry:          o.Expiry,
+		}
+		// The state checked against the auth response is the one sent in
+		// the request.
+		if r := req.ExchangedCredential; r != nil && r.OAuth2 != nil {
+			cred.OAuth2.State = r.OAuth2.State
+		}
+	}
+	return cred, nil
+}
+
+// CredentialFromState returns the credential held in the state value v,
+// either a *auth.Credential or its JSON representation, e.g. once the state
+// is stored by the session service.
+func CredentialFromState(v any) (*auth.Credential, error) {
+	if cred, ok := v.(*auth.Credential); ok {
+		return cred, nil
+	}
+	var cred auth.Credential
+	b, err := json.Marshal(v)
+	if err == nil {
+		err = json.Unmarshal(b, &cred)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the stored credential: %w", err)
+	}
+	return &cred, nil
+}
+
+// Clone returns a deep copy of the config.
+func Clone(cfg *auth.Config) (*auth.Config, error) {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal auth config: %w", err)
+	}
+	var ret auth.Config
+	if err := json.Unmarshal(b, &ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal auth config: %w", err)
+	}
+	return &ret, nil
+}
+
+// NewRequest returns the auth config sent to the client to request a
+// credential. For the OAuth2 authorization code flow, it includes the URI
+// the user must visit to grant access. The secrets of the raw credential,
+// e.g. the OAuth2 client secret, are removed: the request is stored in the
+// session and sent to the client.
+func NewRequest(cfg *auth.Config) (*auth.Config, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("auth config is required")
+	}
+	if err := cfg.Scheme.Validate(); err != nil {
+		return nil, err
+	}
+	req, err := Clone(cfg)
+	if err != nil {
+		return nil, err
+	}
+	req.CredentialKey = cfg.Key()
+	stripSecrets(req.RawCredential)
+	stripSecrets(req.ExchangedCredential)
+	if req.Scheme.Type != auth.SchemeTypeOAuth2AuthorizationCode {
+		return req, nil
+	}
+
+	if req.RawCredential == nil || req.RawCredential.OAuth2 == nil || req.RawCredential.OAuth2.ClientID == "" {
+		return nil, fmt.Errorf("oauth2 authorization code flow requires a raw credential with a client ID")
+	}
+	if req.ExchangedCredential != nil && req.ExchangedCredential.OAuth2 != nil && req.ExchangedCredential.OAuth2.AuthURI != "" {
+		return req, nil
+	}
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+	exchanged := *req.RawCredential
+	o := *exchanged.OAuth2
+	o.State = state
+	o.AuthURI = oauth2Config(req).AuthCodeURL(state, oauth2.AccessTypeOffline)
+	exchanged.OAuth2 = &o
+	req.ExchangedCredential = &exchanged
+	return req, nil
+}
+
+// stripSecrets removes the secrets from the credential.
+func stripSecrets(cred *auth.Credential) {
+	if cred == nil {
+		return
+	}
+	cred.APIKey, cred.Token = "", ""
+	if o := cred.OAuth2; o != nil {
+		o.ClientSecret, o.AccessToken, o.RefreshToken = "", "", ""
+	}
+}
+
+// Exchange returns the credential ready to be used by the tool, based on the
+// credential provided by the client or, for flows without user
+// interaction, the raw credential. OAuth2 authorization codes and client
+// credentials are exchanged for an access token.
+func Exchange(ctx context.Context, cfg *auth.Config) (*auth.Credential, error) {
+	if err := cfg.Scheme.Validate(); err != nil {
+		return nil, err
+	}
+	cred := cfg.ExchangedCredential
+	switch cfg.Scheme.Type {
+	case auth.SchemeTypeAPIKey:
+		if cred == nil || cred.APIKey == "" {
+			return nil, fmt.Errorf("api key is missing")
+		}
+		return &auth.Credential{APIKey: cred.APIKey}, nil
+
+	case auth.SchemeTypeHTTPBearer:
+		if cred == nil || cred.Token == "" {
+			return nil, fmt.Errorf("bearer token is missing")
+		}
+		return &auth.Credential{Token: cred.Token}, nil
+
+	case auth.SchemeTypeOAuth2AuthorizationCode:
+		if cred == nil || cred.OAuth2 == nil {
+			return nil, fmt.Errorf("oauth2 authorization response is missing")
+		}
+		if cred.OAuth2.AccessToken != "" {
+			return tokenCredential(cred.OAuth2, nil), nil
+		}
+		code, err := authCode(cred.OAuth2)
+		if err != nil {
+			return nil, err
+		}
+		tok, err := oauth2Config(cfg).Exchange(ctx, code)
+		if err != nil {
+			return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+		}
+		return tokenCredential(cred.OAuth2, tok), nil
+
+	case auth.SchemeTypeOAuth2ClientCredentials:
+		if cred == nil || cred.OAuth2 == nil {
+			cred = cfg.RawCredential
+		}
+		if cred == nil || cred.OAuth2 == nil {
+			return nil, fmt.Errorf("oauth2 client credentials are missing")
+		}
+		if cred.OAuth2.AccessToken != "" {
+			return tokenCredential(cred.OAuth2, nil), nil
+		}
+		cc := &clientcredentials.Config{
+			ClientID:     cred.OAuth2.ClientID,
+			ClientSecret: cred.OAuth2.ClientSecret,
+			TokenURL:     cfg.Scheme.TokenURL,
+			Scopes:       cfg.Scheme.Scopes,
+		}
+		tok, err := cc.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token with client credentials: %w", err)
+		}
+		return tokenCredential(cred.OAuth2, tok), nil
+	}
+	return nil, fmt.Errorf("unsupported auth scheme type %q", cfg.Scheme.Type)
+}
+
+// NeedsUserInteraction reports whether the credential for the config must be
+// requested from the client.
+func NeedsUserInteraction(cfg *auth.Config) bool {
+	if cfg.Scheme == nil {
+		return true
+	}
+	raw := cfg.RawCredential
+	switch cfg.Scheme.Type {
+	case auth.SchemeTypeAPIKey:
+		return raw == nil || raw.APIKey == ""
+	case auth.SchemeTypeHTTPBearer:
+		return raw == nil || raw.Token == ""
+	case auth.SchemeTypeOAuth2ClientCredentials:
+		return raw == nil || raw.OAuth2 == nil
+	}
+	return true
+}
+
+func oauth2Config(cfg *auth.Config) *oauth2.Config {
+	c := &oauth2.Config{
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  cfg.Scheme.AuthorizationURL,
+			TokenURL: cfg.Scheme.TokenURL,
+		},
+		Scopes: cfg.Scheme.Scopes,
+	}
+	if raw := cfg.RawCredential; raw != nil && raw.OAuth2 != nil {
+		c.ClientID = raw.OAuth2.ClientID
+		c.ClientSecret = raw.OAuth2.ClientSecret
+		c.RedirectURL = raw.OAuth2.RedirectURI
+	}
+	return c
+}
+
+// authCode returns the authorization code from the client response.
+func authCode(o *auth.OAuth2) (string, error) {
+	if o.AuthCode != "" {
+		return o.AuthCode, nil
+	}
+	if o.AuthResponseURI == "" {
+		return "", fmt.Errorf("oauth2 authorization response has neither an auth code nor an auth response URI")
+	}
+	u, err := url.Parse(o.AuthResponseURI)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse auth response URI: %w", err)
+	}
+	q := u.Query()
+	if e := q.Get("error"); e != "" {
+		return "", fmt.Errorf("authorization failed: %s", e)
+	}
+	if o.State != "" && q.Get("state") != o.State {
+		return "", fmt.Errorf("auth response state does not match the request")
+	}
+	code := q.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("auth response URI has no code")
+	}
+	return code, nil
+}
+
+func tokenCredential(o *auth.OAuth2, tok *oauth2.Token) *auth.Credential {
+	ret := *o
+	if tok != nil {
+		ret.AccessToken = tok.AccessToken
+		ret.RefreshToken = tok.RefreshToken
+		ret.Expiry = tok.Expiry
+	}
+	return &auth.Credential{Token: ret.AccessToken, OAuth2: &ret}
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate oauth2 state: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
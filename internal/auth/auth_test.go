@@ -0,0 +1,261 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/adk/auth"
+	authinternal "google.golang.org/adk/internal/auth"
+)
+
+func TestExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var token string
+		switch r.Form.Get("grant_type") {
+		case "client_credentials":
+			token = "client-token"
+		case "authorization_code":
+			token = "code-token-" + r.Form.Get("code")
+		default:
+			http.Error(w, "invalid grant", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer"}`, token)
+	}))
+	defer server.Close()
+
+	client := &auth.OAuth2{ClientID: "id", ClientSecret: "secret"}
+	authCodeScheme := &auth.Scheme{
+		Type:             auth.SchemeTypeOAuth2AuthorizationCode,
+		AuthorizationURL: server.URL + "/authorize",
+		TokenURL:         server.URL + "/token",
+	}
+	tests := []struct {
+		name    string
+		cfg     *auth.Config
+		want    *auth.Credential
+		wantErr bool
+	}{
+		{
+			name: "api key",
+			cfg: &auth.Config{
+				Scheme:              &auth.Scheme{Type: auth.SchemeTypeAPIKey, In: "header", Name: "X-Key"},
+				ExchangedCredential: &auth.Credential{APIKey: "key"},
+			},
+			want: &auth.Credential{APIKey: "key"},
+		},
+		{
+			name:    "missing api key",
+			cfg:     &auth.Config{Scheme: &auth.Scheme{Type: auth.SchemeTypeAPIKey, Name: "X-Key"}},
+			wantErr: true,
+		},
+		{
+			name: "bearer",
+			cfg: &auth.Config{
+				Scheme:              &auth.Scheme{Type: auth.SchemeTypeHTTPBearer},
+				ExchangedCredential: &auth.Credential{Token: "token"},
+			},
+			want: &auth.Credential{Token: "token"},
+		},
+		{
+			name: "client credentials",
+			cfg: &auth.Config{
+				Scheme:        &auth.Scheme{Type: auth.SchemeTypeOAuth2ClientCredentials, TokenURL: server.URL + "/token"},
+				RawCredential: &auth.Credential{OAuth2: client},
+			},
+			want: &auth.Credential{Token: "client-token", OAuth2: &auth.OAuth2{ClientID: "id", ClientSecret: "secret", AccessToken: "client-token"}},
+		},
+		{
+			name: "authorization code from response URI",
+			cfg: &auth.Config{
+				Scheme:        authCodeScheme,
+				RawCredential: &auth.Credential{OAuth2: client},
+				ExchangedCredential: &auth.Credential{OAuth2: &auth.OAuth2{
+					State:           "s1",
+					AuthResponseURI: "https://example.com/cb?code=c1&state=s1",
+				}},
+			},
+			want: &auth.Credential{Token: "code-token-c1", OAuth2: &auth.OAuth2{
+				State:           "s1",
+				AuthResponseURI: "https://example.com/cb?code=c1&state=s1",
+				AccessToken:     "code-token-c1",
+			}},
+		},
+		{
+			name: "authorization code with mismatching state",
+			cfg: &auth.Config{
+				Scheme:        authCodeScheme,
+				RawCredential: &auth.Credential{OAuth2: client},
+				ExchangedCredential: &auth.Credential{OAuth2: &auth.OAuth2{
+					State:           "s1",
+					AuthResponseURI: "https://example.com/cb?code=c1&state=other",
+				}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := authinternal.Exchange(t.Context(), tc.cfg)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Exchange() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(auth.OAuth2{}, "Expiry")); diff != "" {
+				t.Errorf("Exchange() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	cfg := &auth.Config{
+		Scheme: &auth.Scheme{
+			Type:             auth.SchemeTypeOAuth2AuthorizationCode,
+			AuthorizationURL: "https://example.com/authorize",
+			TokenURL:         "https://example.com/token",
+		},
+		RawCredential: &auth.Credential{OAuth2: &auth.OAuth2{ClientID: "id", ClientSecret: "secret"}},
+	}
+	req, err := authinternal.NewRequest(cfg)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	if req.CredentialKey != cfg.Key() {
+		t.Errorf("NewRequest() credential key = %q, want %q", req.CredentialKey, cfg.Key())
+	}
+	if o := req.ExchangedCredential.OAuth2; o.State == "" || o.AuthURI == "" {
+		t.Errorf("NewRequest() did not generate the auth URI: %+v", o)
+	}
+	if req.RawCredential.OAuth2.ClientSecret != "" || req.ExchangedCredential.OAuth2.ClientSecret != "" {
+		t.Errorf("NewRequest() kept the client secret: %+v", req)
+	}
+	if cfg.ExchangedCredential != nil || cfg.RawCredential.OAuth2.ClientSecret != "secret" {
+		t.Errorf("NewRequest() modified the config")
+	}
+
+	if _, err := authinternal.NewRequest(&auth.Config{Scheme: &auth.Scheme{Type: "unknown"}}); err == nil {
+		t.Errorf("NewRequest() with unknown scheme succeeded, want error")
+	}
+}
+
+func TestResponse(t *testing.T) {
+	scheme := &auth.Scheme{
+		Type:             auth.SchemeTypeOAuth2AuthorizationCode,
+		AuthorizationURL: "https://example.com/authorize",
+		TokenURL:         "https://example.com/token",
+	}
+	req, err := authinternal.NewRequest(&auth.Config{
+		Scheme:        scheme,
+		RawCredential: &auth.Credential{OAuth2: &auth.OAuth2{ClientID: "id"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := req.ExchangedCredential.OAuth2.State
+	tests := []struct {
+		name    string
+		resp    func(resp *auth.Config)
+		want    *auth.Credential
+		wantErr bool
+	}{
+		{
+			name: "auth response",
+			resp: func(resp *auth.Config) {
+				resp.ExchangedCredential.OAuth2.AuthResponseURI = "https://example.com/callback?code=code"
+			},
+			want: &auth.Credential{OAuth2: &auth.OAuth2{
+				AuthResponseURI: "https://example.com/callback?code=code",
+				State:           state,
+			}},
+		},
+		{
+			name: "client fields ignored",
+			resp: func(resp *auth.Config) {
+				o := resp.ExchangedCredential.OAuth2
+				o.AuthCode, o.State, o.ClientID, o.ClientSecret = "code", "other-state", "other-id", "other-secret"
+			},
+			want: &auth.Credential{OAuth2: &auth.OAuth2{AuthCode: "code", State: state}},
+		},
+		{
+			name:    "mismatched key",
+			resp:    func(resp *auth.Config) { resp.CredentialKey = "user:other" },
+			wantErr: true,
+		},
+		{
+			name:    "mismatched token URL",
+			resp:    func(resp *auth.Config) { resp.Scheme.TokenURL = "https://attacker.example.com/token" },
+			wantErr: true,
+		},
+		{
+			name:    "no credential",
+			resp:    func(resp *auth.Config) { resp.ExchangedCredential = nil },
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := authinternal.Clone(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.resp(resp)
+			got, err := authinternal.Response(req, resp)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Response() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Response() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCredentialFromState(t *testing.T) {
+	want := &auth.Credential{OAuth2: &auth.OAuth2{AccessToken: "access-token", RefreshToken: "refresh-token"}}
+	// The session services return the credential as is, or decoded from
+	// JSON once stored.
+	var decoded any
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []any{want, decoded} {
+		got, err := authinternal.CredentialFromState(v)
+		if err != nil {
+			t.Fatalf("CredentialFromState(%T) error = %v", v, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("CredentialFromState(%T) mismatch (-want +got):\n%s", v, diff)
+		}
+	}
+	if _, err := authinternal.CredentialFromState("not a credential"); err == nil {
+		t.Errorf("CredentialFromState() of a string succeeded, want an error")
+	}
+}
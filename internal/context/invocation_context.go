@@ -24,6 +24,9 @@ import (
 )
 
 type InvocationContextParams struct {
+	// InvocationID is the ID of the invocation. A new ID is generated if empty.
+	InvocationID string
+
 	Artifacts agent.Artifacts
 	Memory    agent.Memory
 	Session   session.Session
@@ -37,10 +40,14 @@ type InvocationContextParams struct {
 }
 
 func NewInvocationContext(ctx context.Context, params InvocationContextParams) agent.InvocationContext {
+	invocationID := params.InvocationID
+	if invocationID == "" {
+		invocationID = "e-" + uuid.NewString()
+	}
 	return &InvocationContext{
 		Context:      ctx,
 		params:       params,
-		invocationID: "e-" + uuid.NewString(),
+		invocationID: invocationID,
 	}
 }
 
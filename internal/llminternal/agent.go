@@ -16,7 +16,9 @@ package llminternal
 
 import (
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
 	"google.golang.org/adk/tool"
 	"google.golang.org/genai"
 )
@@ -32,7 +34,9 @@ type State struct {
 	Tools    []tool.Tool
 	Toolsets []tool.Toolset
 
-	IncludeContents string
+	IncludeContents        string
+	HistoryWindow          *HistoryWindow
+	DisableEventCompaction bool
 
 	GenerateContentConfig *genai.GenerateContentConfig
 
@@ -48,6 +52,16 @@ type State struct {
 	OutputSchema *genai.Schema
 
 	OutputKey string
+
+	CodeExecutor codeexecutor.CodeExecutor
+
+	Planner planner.Planner
+
+	MaxOutputContinuations int
+	FailOnTruncatedOutput  bool
+
+	OutputValidator  func(output string) (any, error)
+	MaxOutputRepairs int
 }
 
 type InstructionProvider func(ctx agent.ReadonlyContext) (string, error)
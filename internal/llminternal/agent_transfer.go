@@ -86,6 +86,10 @@ func AgentTransferRequestProcessor(ctx agent.InvocationContext, req *model.LLMRe
 	return appendTools(req, transferToAgentTool)
 }
 
+// TransferToAgentFunctionName is the name of the function call transferring
+// the control to another agent.
+const TransferToAgentFunctionName = "transfer_to_agent"
+
 type TransferToAgentTool struct{}
 
 // Description implements tool.Tool.
@@ -96,7 +100,7 @@ This tool hands off control to another agent when it's more suitable to answer t
 
 // Name implements tool.Tool.
 func (t *TransferToAgentTool) Name() string {
-	return "transfer_to_agent"
+	return TransferToAgentFunctionName
 }
 
 // IsLongRunning implements tool.Tool.
@@ -0,0 +1,147 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
+	authinternal "google.golang.org/adk/internal/auth"
+	"google.golang.org/adk/internal/typeutil"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+)
+
+// authPreprocess resumes the function calls that were waiting for a
+// credential.
+//
+// If the latest event is the client response to credential requests, the
+// tools that requested them are called again. Only the credential fields
+// set by the client are taken from its response: the rest of the auth
+// config comes from the original request, which the response must match.
+// The tools exchange the credential with their own config, which holds the
+// secrets, and store it in the user state. It returns nil if there is
+// nothing to resume.
+//
+// Unlike the other request processors, it is called by the flow directly
+// since it produces an event.
+//
+// reference: adk-python src/google/adk/auth/auth_preprocessor.py
+func (f *Flow) authPreprocess(ctx agent.InvocationContext, tools map[string]tool.Tool) (*session.Event, error) {
+	if ctx.Session() == nil {
+		return nil, nil
+	}
+	events := ctx.Session().Events()
+	if events.Len() == 0 {
+		return nil, nil
+	}
+	last := events.At(events.Len() - 1)
+	if last.Author != "user" {
+		return nil, nil
+	}
+
+	responses := make(map[string]*auth.Config)
+	for _, resp := range utils.FunctionResponses(last.Content) {
+		if resp.Name != auth.RequestCredentialFunctionName {
+			continue
+		}
+		cfg, err := typeutil.ConvertToWithJSONSchema[map[string]any, *auth.Config](resp.Response, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse auth config of credential response %q: %w", resp.ID, err)
+		}
+		responses[resp.ID] = cfg
+	}
+	if len(responses) == 0 {
+		return nil, nil
+	}
+
+	// Find the requests the client responded to and the function calls
+	// that made them.
+	callIDs := make(map[string]bool)
+	for i := events.Len() - 1; i >= 0; i-- {
+		for _, fc := range utils.FunctionCalls(events.At(i).Content) {
+			if fc.Name != auth.RequestCredentialFunctionName || responses[fc.ID] == nil {
+				continue
+			}
+			args, err := typeutil.ConvertToWithJSONSchema[map[string]any, auth.RequestCredentialArgs](fc.Args, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse credential request %q: %w", fc.ID, err)
+			}
+			cred, err := authinternal.Response(args.AuthConfig, responses[fc.ID])
+			if err != nil {
+				return nil, fmt.Errorf("invalid credential response %q: %w", fc.ID, err)
+			}
+			if err := ctx.Session().State().Set(authinternal.ResponseStateKey(args.AuthConfig), cred); err != nil {
+				return nil, fmt.Errorf("failed to store credential response: %w", err)
+			}
+			callIDs[args.FunctionCallID] = true
+		}
+	}
+	if len(callIDs) == 0 {
+		return nil, nil
+	}
+
+	for i := events.Len() - 1; i >= 0; i-- {
+		var calls []*genai.FunctionCall
+		for _, fc := range utils.FunctionCalls(events.At(i).Content) {
+			if callIDs[fc.ID] {
+				calls = append(calls, fc)
+			}
+		}
+		if len(calls) == 0 {
+			continue
+		}
+		return f.callFunctions(ctx, tools, calls, nil)
+	}
+	return nil, nil
+}
+
+// generateAuthEvent returns the event requesting the credentials requested
+// by the tools during the function calls, or nil if there are none.
+//
+// reference: adk-python src/google/adk/flows/llm_flows/functions.py generate_auth_event
+func generateAuthEvent(ctx agent.InvocationContext, fnResponseEvent *session.Event) (*session.Event, error) {
+	requested := fnResponseEvent.Actions.RequestedAuthConfigs
+	if len(requested) == 0 {
+		return nil, nil
+	}
+	content := &genai.Content{Role: genai.RoleModel}
+	for _, id := range slices.Sorted(maps.Keys(requested)) {
+		args, err := typeutil.ConvertToWithJSONSchema[auth.RequestCredentialArgs, map[string]any](auth.RequestCredentialArgs{FunctionCallID: id, AuthConfig: requested[id]}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build credential request: %w", err)
+		}
+		content.Parts = append(content.Parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			Name: auth.RequestCredentialFunctionName,
+			Args: args,
+		}})
+	}
+	utils.PopulateClientFunctionCallID(content)
+
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.LLMResponse = model.LLMResponse{Content: content}
+	for _, fc := range utils.FunctionCalls(content) {
+		ev.LongRunningToolIDs = append(ev.LongRunningToolIDs, fc.ID)
+	}
+	return ev, nil
+}
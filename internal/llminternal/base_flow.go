@@ -18,18 +18,23 @@ import (
 	"fmt"
 	"iter"
 	"maps"
+	"reflect"
 	"slices"
 
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/internal/agent/parentmap"
 	"google.golang.org/adk/internal/agent/runconfig"
 	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/plugininternal"
 	"google.golang.org/adk/internal/telemetry"
 	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/toolconfirmation"
 	"google.golang.org/genai"
 )
 
@@ -41,31 +46,43 @@ type BeforeToolCallback func(ctx tool.Context, tool tool.Tool, args map[string]a
 
 type AfterToolCallback func(ctx tool.Context, tool tool.Tool, args map[string]any, result map[string]any, err error) (map[string]any, error)
 
+type OnModelErrorCallback func(ctx agent.CallbackContext, llmRequest *model.LLMRequest, err error) (*model.LLMResponse, error)
+
+type OnToolErrorCallback func(ctx tool.Context, tool tool.Tool, args map[string]any, err error) (map[string]any, error)
+
 type Flow struct {
 	Model model.LLM
 
-	RequestProcessors    []func(ctx agent.InvocationContext, req *model.LLMRequest) error
-	ResponseProcessors   []func(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error
-	BeforeModelCallbacks []BeforeModelCallback
-	AfterModelCallbacks  []AfterModelCallback
-	BeforeToolCallbacks  []BeforeToolCallback
-	AfterToolCallbacks   []AfterToolCallback
+	RequestProcessors     []func(ctx agent.InvocationContext, req *model.LLMRequest) error
+	ResponseProcessors    []func(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error
+	BeforeModelCallbacks  []BeforeModelCallback
+	AfterModelCallbacks   []AfterModelCallback
+	BeforeToolCallbacks   []BeforeToolCallback
+	AfterToolCallbacks    []AfterToolCallback
+	OnModelErrorCallbacks []OnModelErrorCallback
+	OnToolErrorCallbacks  []OnToolErrorCallback
+	// ToolConfirmationPolicy decides which tool calls must be confirmed by a
+	// human, in addition to the tools implementing tool.ConfirmableTool.
+	ToolConfirmationPolicy tool.ConfirmationPolicy
 }
 
 var (
 	DefaultRequestProcessors = []func(ctx agent.InvocationContext, req *model.LLMRequest) error{
 		basicRequestProcessor,
-		authPreprocessor,
+		// Auth preprocessing is done by Flow.authPreprocess, as it yields events.
 		instructionsRequestProcessor,
 		identityRequestProcessor,
 		ContentsRequestProcessor,
 		// Some implementations of NL Planning mark planning contents as thoughts in the post processor.
-		// Since these need to be unmarked, NL Planning should be after contentsRequestProcessor.
+		// Since these need to be stripped, NL Planning should be after contentsRequestProcessor.
 		nlPlanningRequestProcessor,
 		// Code execution should be after contentsRequestProcessor as it mutates the contents
 		// to optimize data files.
 		codeExecutionRequestProcessor,
 		AgentTransferRequestProcessor,
+		// The output schema processor should be after basicRequestProcessor, as
+		// it replaces the response schema with a tool if needed.
+		outputSchemaRequestProcessor,
 		removeDisplayNameIfExists,
 	}
 	DefaultResponseProcessors = []func(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error{
@@ -75,10 +92,31 @@ var (
 )
 
 func (f *Flow) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	run := f.run
+	if cfg := runconfig.FromContext(ctx); cfg != nil && cfg.StreamingMode == runconfig.StreamingModeBidi {
+		run = f.runLive
+	}
+	return func(yield func(*session.Event, error) bool) {
+		for ev, err := range run(ctx) {
+			// Exceeding an invocation limit ends the invocation with an error event.
+			if err != nil {
+				if limitErr := runconfig.LimitExceeded(ctx, err); limitErr != nil {
+					yield(limitExceededEvent(ctx, limitErr), nil)
+					return
+				}
+			}
+			if !yield(ev, err) {
+				return
+			}
+		}
+	}
+}
+
+func (f *Flow) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	return func(yield func(*session.Event, error) bool) {
-		for {
+		for firstStep := true; ; firstStep = false {
 			var lastEvent *session.Event
-			for ev, err := range f.runOneStep(ctx) {
+			for ev, err := range f.runOneStep(ctx, firstStep) {
 				if err != nil {
 					yield(nil, err)
 					return
@@ -93,16 +131,29 @@ func (f *Flow) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error]
 				return
 			}
 			if lastEvent.LLMResponse.Partial {
-				// We may have reached max token limit during streaming mode.
-				// TODO: handle Partial response in model level. CL 781377328
-				yield(nil, fmt.Errorf("TODO: last event is not final"))
+				// generateContent ends the text streams with a final response,
+				// only a stream of non-text partial responses can end here.
+				yield(nil, fmt.Errorf("model %q stream ended without a final response", f.Model.Name()))
 				return
 			}
 		}
 	}
 }
 
-func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+// limitExceededEvent returns the error event emitted when the invocation
+// exceeds one of its limits.
+func limitExceededEvent(ctx agent.InvocationContext, limitErr *agent.LimitExceededError) *session.Event {
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.LLMResponse = model.LLMResponse{
+		ErrorCode:    limitErr.Code,
+		ErrorMessage: limitErr.Message,
+	}
+	return ev
+}
+
+func (f *Flow) runOneStep(ctx agent.InvocationContext, firstStep bool) iter.Seq2[*session.Event, error] {
 	return func(yield func(*session.Event, error) bool) {
 		req := &model.LLMRequest{}
 
@@ -114,11 +165,36 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 		if ctx.Ended() {
 			return
 		}
+
+		// Resume the function calls waiting for a credential or a
+		// confirmation, if any.
+		tools, err := requestTools(req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		resumedEvent, err := f.authPreprocess(ctx, tools)
+		if err == nil && resumedEvent == nil {
+			resumedEvent, err = f.confirmationPreprocess(ctx, tools)
+		}
+		if err == nil && resumedEvent == nil && firstStep {
+			// Resume the function calls of an interrupted invocation, if any.
+			resumedEvent, err = f.resumePendingFunctionCalls(ctx, tools)
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if resumedEvent != nil {
+			f.yieldFunctionResponseEvent(ctx, resumedEvent, yield)
+			return
+		}
+
 		spans := telemetry.StartTrace(ctx, "call_llm")
 		// Create event to pass to callback state delta
 		stateDelta := make(map[string]any)
 		// Calls the LLM.
-		for resp, err := range f.callLLM(ctx, req, stateDelta) {
+		for resp, err := range f.generateValidContent(ctx, req, stateDelta) {
 			if err != nil {
 				yield(nil, err)
 				return
@@ -134,25 +210,25 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 				continue
 			}
 
-			// TODO: temporarily convert
-			tools := make(map[string]tool.Tool)
-			for k, v := range req.Tools {
-				tool, ok := v.(tool.Tool)
-				if !ok {
-					if !yield(nil, fmt.Errorf("unexpected tool type %T for tool %v", v, k)) {
-						return
-					}
-				}
-				tools[k] = tool
-			}
-
 			// Build the event and yield.
 			modelResponseEvent := f.finalizeModelResponseEvent(ctx, resp, tools, stateDelta)
 			telemetry.TraceLLMCall(spans, ctx, req, modelResponseEvent)
 			if !yield(modelResponseEvent, nil) {
 				return
 			}
-			// TODO: generate and yield an auth event if needed.
+			// Execute the code generated by the model. The result is sent
+			// back to the model in the next step.
+			codeEvent, err := f.handleCodeExecution(ctx, resp)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if codeEvent != nil {
+				if !yield(codeEvent, nil) {
+					return
+				}
+				continue
+			}
 
 			// Handle function calls.
 
@@ -165,7 +241,18 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 				// nothing to yield/process.
 				continue
 			}
-			if !yield(ev, nil) {
+			if !f.yieldFunctionResponseEvent(ctx, ev, yield) {
+				return
+			}
+			// The response set with the set_model_response tool is the final
+			// response of the agent.
+			structuredEvent, err := structuredResponseEvent(ctx, tools, ev)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if structuredEvent != nil {
+				yield(structuredEvent, nil)
 				return
 			}
 
@@ -191,6 +278,38 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 	}
 }
 
+// yieldFunctionResponseEvent yields the function response event, followed by
+// the credential request event if the tools requested credentials and the
+// confirmation request event if tool calls must be confirmed. It returns
+// false if the flow must stop.
+func (f *Flow) yieldFunctionResponseEvent(ctx agent.InvocationContext, ev *session.Event, yield func(*session.Event, error) bool) bool {
+	if !yield(ev, nil) {
+		return false
+	}
+	authEvent, err := generateAuthEvent(ctx, ev)
+	if err != nil {
+		yield(nil, err)
+		return false
+	}
+	confirmationEvent, err := generateConfirmationEvent(ctx, ev)
+	if err != nil {
+		yield(nil, err)
+		return false
+	}
+	if authEvent == nil && confirmationEvent == nil {
+		return true
+	}
+	// The invocation ends until the client provides the credentials or the
+	// confirmations.
+	if authEvent != nil && !yield(authEvent, nil) {
+		return false
+	}
+	if confirmationEvent != nil {
+		yield(confirmationEvent, nil)
+	}
+	return false
+}
+
 func (f *Flow) preprocess(ctx agent.InvocationContext, req *model.LLMRequest) error {
 	llmAgent, ok := ctx.Agent().(Agent)
 	if !ok {
@@ -238,6 +357,13 @@ func toolPreprocess(ctx agent.InvocationContext, req *model.LLMRequest, tools []
 
 func (f *Flow) callLLM(ctx agent.InvocationContext, req *model.LLMRequest, stateDelta map[string]any) iter.Seq2[*model.LLMResponse, error] {
 	return func(yield func(*model.LLMResponse, error) bool) {
+		// The callbacks of the plugins run before the ones of the agent.
+		plugins := plugininternal.FromContext(ctx)
+		pluginResponse, pluginErr := plugins.BeforeModel(icontext.NewCallbackContextWithDelta(ctx, stateDelta), req)
+		if pluginResponse != nil || pluginErr != nil {
+			yield(pluginResponse, pluginErr)
+			return
+		}
 		for _, callback := range f.BeforeModelCallbacks {
 			cctx := icontext.NewCallbackContextWithDelta(ctx, stateDelta)
 			callbackResponse, callbackErr := callback(cctx, req)
@@ -251,10 +377,17 @@ func (f *Flow) callLLM(ctx agent.InvocationContext, req *model.LLMRequest, state
 		// TODO: Set _ADK_AGENT_NAME_LABEL_KEY in req.GenerateConfig.Labels
 		// to help with slicing the billing reports on a per-agent basis.
 
-		// TODO: RunLive mode when invocation_context.run_config.support_cfc is true.
-		useStream := runconfig.FromContext(ctx).StreamingMode == runconfig.StreamingModeSSE
+		cfg := runconfig.FromContext(ctx)
+		if err := cfg.Limits.CountLLMCall(ctx); err != nil {
+			yield(nil, err)
+			return
+		}
+		useStream := cfg.StreamingMode == runconfig.StreamingModeSSE
 
 		for resp, err := range f.Model.GenerateContent(ctx, req, useStream) {
+			if err != nil {
+				resp, err = f.runOnModelErrorCallbacks(ctx, req, stateDelta, err)
+			}
 			callbackResp, callbackErr := f.runAfterModelCallbacks(ctx, resp, stateDelta, err)
 			// TODO: check if we should stop iterator on the first error from stream or continue yielding next results.
 			if callbackErr != nil {
@@ -282,7 +415,28 @@ func (f *Flow) callLLM(ctx agent.InvocationContext, req *model.LLMRequest, state
 	}
 }
 
+// requestTools returns the tools packed into the request by the tool
+// preprocessors, keyed by name.
+// TODO: temporarily convert
+func requestTools(req *model.LLMRequest) (map[string]tool.Tool, error) {
+	tools := make(map[string]tool.Tool)
+	for k, v := range req.Tools {
+		tool, ok := v.(tool.Tool)
+		if !ok {
+			return nil, fmt.Errorf("unexpected tool type %T for tool %v", v, k)
+		}
+		tools[k] = tool
+	}
+	return tools, nil
+}
+
 func (f *Flow) runAfterModelCallbacks(ctx agent.InvocationContext, llmResp *model.LLMResponse, stateDelta map[string]any, llmErr error) (*model.LLMResponse, error) {
+	if llmErr == nil && llmResp != nil {
+		cctx := icontext.NewCallbackContextWithDelta(ctx, stateDelta)
+		if resp, err := plugininternal.FromContext(ctx).AfterModel(cctx, llmResp); resp != nil || err != nil {
+			return resp, err
+		}
+	}
 	for _, callback := range f.AfterModelCallbacks {
 		cctx := icontext.NewCallbackContextWithDelta(ctx, stateDelta)
 		callbackResponse, callbackErr := callback(cctx, llmResp, llmErr)
@@ -295,6 +449,22 @@ func (f *Flow) runAfterModelCallbacks(ctx agent.InvocationContext, llmResp *mode
 	return nil, nil
 }
 
+// runOnModelErrorCallbacks runs the OnModelErrorCallbacks of the plugins,
+// then of the agent, until one returns a response or an error. It returns
+// the model error if none does.
+func (f *Flow) runOnModelErrorCallbacks(ctx agent.InvocationContext, req *model.LLMRequest, stateDelta map[string]any, modelErr error) (*model.LLMResponse, error) {
+	cctx := icontext.NewCallbackContextWithDelta(ctx, stateDelta)
+	if resp, err := plugininternal.FromContext(ctx).OnModelError(cctx, req, modelErr); resp != nil || err != nil {
+		return resp, err
+	}
+	for _, callback := range f.OnModelErrorCallbacks {
+		if resp, err := callback(cctx, req, modelErr); resp != nil || err != nil {
+			return resp, err
+		}
+	}
+	return nil, modelErr
+}
+
 func (f *Flow) postprocess(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error {
 	// apply response processor functions to the response in the configured order.
 	for _, processor := range f.ResponseProcessors {
@@ -355,12 +525,21 @@ func findLongRunningFunctionCallIDs(c *genai.Content, tools map[string]tool.Tool
 // handleFunctionCalls calls the functions and returns the function response event.
 //
 // TODO: accept filters to include/exclude function calls.
-// TODO: check feasibility of running tool.Run concurrently.
 func (f *Flow) handleFunctionCalls(ctx agent.InvocationContext, toolsDict map[string]tool.Tool, resp *model.LLMResponse) (*session.Event, error) {
-	var fnResponseEvents []*session.Event
+	return f.callFunctions(ctx, toolsDict, utils.FunctionCalls(resp.Content), nil)
+}
 
-	fnCalls := utils.FunctionCalls(resp.Content)
-	for _, fnCall := range fnCalls {
+// callFunctions calls the functions and returns the function response event.
+//
+// The calls run concurrently, up to RunConfig.MaxConcurrentToolCalls at a
+// time. A call to a tool implementing tool.SequentialTool waits for the
+// preceding calls to complete and runs on its own. The function responses are
+// merged in the order of the calls, regardless of their completion order.
+//
+// confirmations are the human confirmations of the calls, keyed by call ID.
+func (f *Flow) callFunctions(ctx agent.InvocationContext, toolsDict map[string]tool.Tool, fnCalls []*genai.FunctionCall, confirmations map[string]*toolconfirmation.Confirmation) (*session.Event, error) {
+	funcTools := make([]toolinternal.FunctionTool, len(fnCalls))
+	for i, fnCall := range fnCalls {
 		curTool, ok := toolsDict[fnCall.Name]
 		if !ok {
 			return nil, fmt.Errorf("unknown tool: %q", fnCall.Name)
@@ -369,73 +548,139 @@ func (f *Flow) handleFunctionCalls(ctx agent.InvocationContext, toolsDict map[st
 		if !ok {
 			return nil, fmt.Errorf("tool %q is not a function tool", curTool.Name())
 		}
-		toolCtx := toolinternal.NewToolContext(ctx, fnCall.ID, &session.EventActions{StateDelta: make(map[string]any)})
-		//toolCtx := tool.
-		spans := telemetry.StartTrace(ctx, "execute_tool "+fnCall.Name)
-
-		result := f.callTool(funcTool, fnCall.Args, toolCtx)
-
-		// TODO: agent.canonical_after_tool_callbacks
-		// TODO: handle long-running tool.
-		ev := session.NewEvent(ctx.InvocationID())
-		ev.LLMResponse = model.LLMResponse{
-			Content: &genai.Content{
-				Role: "user",
-				Parts: []*genai.Part{
-					{
-						FunctionResponse: &genai.FunctionResponse{
-							ID:       fnCall.ID,
-							Name:     fnCall.Name,
-							Response: result,
-						},
-					},
-				},
-			},
+		funcTools[i] = funcTool
+	}
+	if cfg := runconfig.FromContext(ctx); cfg != nil {
+		if err := cfg.Limits.CountToolCalls(ctx, len(fnCalls)); err != nil {
+			return nil, err
 		}
-		ev.Author = ctx.Agent().Name()
-		ev.Branch = ctx.Branch()
-		ev.Actions = *toolCtx.Actions()
-		telemetry.TraceToolCall(spans, curTool, fnCall.Args, ev)
-		fnResponseEvents = append(fnResponseEvents, ev)
 	}
-	mergedEvent, err := mergeParallelFunctionResponseEvents(fnResponseEvents)
+
+	limit := 0
+	if cfg := ctx.RunConfig(); cfg != nil {
+		limit = cfg.MaxConcurrentToolCalls
+	}
+	newGroup := func() *errgroup.Group {
+		g := &errgroup.Group{}
+		if limit > 0 {
+			g.SetLimit(limit)
+		}
+		return g
+	}
+
+	fnResponseEvents := make([]*session.Event, len(fnCalls))
+	g := newGroup()
+	for i, fnCall := range fnCalls {
+		if len(fnCalls) == 1 || limit == 1 || isSequential(funcTools[i]) {
+			_ = g.Wait()
+			fnResponseEvents[i] = f.callFunction(ctx, funcTools[i], fnCall, confirmations[fnCall.ID])
+			g = newGroup()
+			continue
+		}
+		g.Go(func() error {
+			fnResponseEvents[i] = f.callFunction(ctx, funcTools[i], fnCall, confirmations[fnCall.ID])
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	mergedEvent, conflicts, err := mergeParallelFunctionResponseEvents(fnResponseEvents)
 	if err != nil {
 		return mergedEvent, err
 	}
+	if len(conflicts) > 0 {
+		if mergedEvent.CustomMetadata == nil {
+			mergedEvent.CustomMetadata = make(map[string]any)
+		}
+		mergedEvent.CustomMetadata[agent.StateConflictsMetadataKey] = conflicts
+	}
 	// this is needed for debug traces of parallel calls
 	spans := telemetry.StartTrace(ctx, "execute_tool (merged)")
 	telemetry.TraceMergedToolCalls(spans, mergedEvent)
 	return mergedEvent, nil
 }
 
-func (f *Flow) callTool(tool toolinternal.FunctionTool, fArgs map[string]any, toolCtx tool.Context) map[string]any {
+// callFunction calls the function and returns the function response event.
+func (f *Flow) callFunction(ctx agent.InvocationContext, funcTool toolinternal.FunctionTool, fnCall *genai.FunctionCall, confirmation *toolconfirmation.Confirmation) *session.Event {
+	toolCtx := toolinternal.NewToolContext(ctx, fnCall.ID, &session.EventActions{StateDelta: make(map[string]any)})
+	spans := telemetry.StartTrace(ctx, "execute_tool "+fnCall.Name)
+
+	result := f.callTool(funcTool, fnCall.Args, toolCtx, confirmation)
+
+	// TODO: agent.canonical_after_tool_callbacks
+	// TODO: handle long-running tool.
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.LLMResponse = model.LLMResponse{
+		Content: &genai.Content{
+			Role: "user",
+			Parts: []*genai.Part{
+				{
+					FunctionResponse: &genai.FunctionResponse{
+						ID:       fnCall.ID,
+						Name:     fnCall.Name,
+						Response: result,
+					},
+				},
+			},
+		},
+	}
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.Actions = *toolCtx.Actions()
+	telemetry.TraceToolCall(spans, funcTool, fnCall.Args, ev)
+	return ev
+}
+
+func isSequential(t tool.Tool) bool {
+	st, ok := t.(tool.SequentialTool)
+	return ok && st.IsSequential()
+}
+
+// callTool calls the tool, unless the call must first be confirmed or was
+// rejected. confirmation is the human confirmation of the call, if any.
+func (f *Flow) callTool(tool toolinternal.FunctionTool, fArgs map[string]any, toolCtx tool.Context, confirmation *toolconfirmation.Confirmation) map[string]any {
 	// If the result is present, it will be used instead of calling the actual tool.
 	result, err := f.invokeBeforeToolCallbacks(tool, fArgs, toolCtx)
 	if err != nil {
 		return map[string]any{"error": fmt.Errorf("BeforeToolCallback failed: %w", err)}
 	}
 	if result == nil {
-		result, err = tool.Run(toolCtx, fArgs)
-		// genai.FunctionResponse expects to use "output" key to specify function output
-		// and "error" key to specify error details (if any). If "output" and "error" keys
-		// are not specified, then whole "response" is treated as function output.
-		// TODO(hakim): revisit the tool's function signature to handle error from user function better.
-		if err != nil {
-			return map[string]any{"error": fmt.Errorf("tool %q failed: %w", tool.Name(), err)}
+		switch {
+		case confirmation != nil && !confirmation.Confirmed:
+			result = map[string]any{"error": confirmationRejectedMessage}
+		case confirmation == nil && f.requestConfirmation(tool, fArgs, toolCtx):
+			result = map[string]any{"error": confirmationRequiredMessage}
+		default:
+			result, err = tool.Run(toolCtx, fArgs)
+			if err != nil {
+				result, err = f.invokeOnToolErrorCallbacks(tool, fArgs, toolCtx, err)
+			}
 		}
 	}
-	afterToolCallbackResult, err := f.invokeAfterToolCallbacks(tool, fArgs, toolCtx, result, err)
-	if err != nil {
-		return map[string]any{"error": fmt.Errorf("AfterToolCallback failed: %w", err)}
+	// The AfterToolCallbacks are called with the error of the tool, if any.
+	afterToolCallbackResult, afterErr := f.invokeAfterToolCallbacks(tool, fArgs, toolCtx, result, err)
+	if afterErr != nil {
+		return map[string]any{"error": fmt.Errorf("AfterToolCallback failed: %w", afterErr)}
 	}
 	// If the result is present, it will replace the result returned by the tool's Run method.
 	if afterToolCallbackResult != nil {
 		return afterToolCallbackResult
 	}
+	// genai.FunctionResponse expects to use "output" key to specify function output
+	// and "error" key to specify error details (if any). If "output" and "error" keys
+	// are not specified, then whole "response" is treated as function output.
+	// TODO(hakim): revisit the tool's function signature to handle error from user function better.
+	if err != nil {
+		return map[string]any{"error": fmt.Errorf("tool %q failed: %w", tool.Name(), err)}
+	}
 	return result
 }
 
 func (f *Flow) invokeBeforeToolCallbacks(tool toolinternal.FunctionTool, fArgs map[string]any, toolCtx tool.Context) (map[string]any, error) {
+	// The callbacks of the plugins run before the ones of the agent.
+	if result, err := plugininternal.FromContext(toolCtx).BeforeTool(toolCtx, tool, fArgs); result != nil || err != nil {
+		return result, err
+	}
 	for _, callback := range f.BeforeToolCallbacks {
 		result, err := callback(toolCtx, tool, fArgs)
 		if err != nil {
@@ -451,6 +696,13 @@ func (f *Flow) invokeBeforeToolCallbacks(tool toolinternal.FunctionTool, fArgs m
 }
 
 func (f *Flow) invokeAfterToolCallbacks(tool toolinternal.FunctionTool, fArgs map[string]any, toolCtx tool.Context, fResult map[string]any, fErr error) (map[string]any, error) {
+	// The AfterToolCallbacks of the plugins only get successful results: the
+	// errors are given to their OnToolErrorCallbacks.
+	if fErr == nil {
+		if result, err := plugininternal.FromContext(toolCtx).AfterTool(toolCtx, tool, fArgs, fResult); result != nil || err != nil {
+			return result, err
+		}
+	}
 	for _, callback := range f.AfterToolCallbacks {
 		result, err := callback(toolCtx, tool, fArgs, fResult, fErr)
 		if err != nil {
@@ -465,21 +717,45 @@ func (f *Flow) invokeAfterToolCallbacks(tool toolinternal.FunctionTool, fArgs ma
 	return nil, nil
 }
 
-func mergeParallelFunctionResponseEvents(events []*session.Event) (*session.Event, error) {
+// invokeOnToolErrorCallbacks runs the OnToolErrorCallbacks of the plugins,
+// then of the agent, until one returns a result or an error. It returns the
+// tool error if none does.
+func (f *Flow) invokeOnToolErrorCallbacks(tool toolinternal.FunctionTool, fArgs map[string]any, toolCtx tool.Context, toolErr error) (map[string]any, error) {
+	if result, err := plugininternal.FromContext(toolCtx).OnToolError(toolCtx, tool, fArgs, toolErr); result != nil || err != nil {
+		return result, err
+	}
+	for _, callback := range f.OnToolErrorCallbacks {
+		if result, err := callback(toolCtx, tool, fArgs, toolErr); result != nil || err != nil {
+			return result, err
+		}
+	}
+	return nil, toolErr
+}
+
+// mergeParallelFunctionResponseEvents merges the function response events of
+// parallel function calls into a single event, in the order of the events.
+//
+// The state deltas are merged key by key. If several events set the same key
+// to different values, the value of the last event is kept and the key is
+// returned as a conflict.
+func mergeParallelFunctionResponseEvents(events []*session.Event) (*session.Event, []string, error) {
 	switch len(events) {
 	case 0:
-		return nil, nil
+		return nil, nil, nil
 	case 1:
-		return events[0], nil
+		return events[0], nil, nil
 	}
 	var parts []*genai.Part
 	var actions *session.EventActions
+	var conflicts []string
 	for _, ev := range events {
 		if ev == nil || ev.LLMResponse.Content == nil {
 			continue
 		}
 		parts = append(parts, ev.LLMResponse.Content.Parts...)
-		actions = mergeEventActions(actions, &ev.Actions)
+		var c []string
+		actions, c = mergeEventActions(actions, &ev.Actions)
+		conflicts = append(conflicts, c...)
 	}
 	// reuse events[0]
 	ev := events[0]
@@ -490,20 +766,19 @@ func mergeParallelFunctionResponseEvents(events []*session.Event) (*session.Even
 		},
 	}
 	ev.Actions = *actions
-	return ev, nil
+	slices.Sort(conflicts)
+	return ev, slices.Compact(conflicts), nil
 }
 
-func mergeEventActions(base, other *session.EventActions) *session.EventActions {
+// mergeEventActions merges other into base and returns the state delta keys
+// that other sets to a different value.
+func mergeEventActions(base, other *session.EventActions) (*session.EventActions, []string) {
 	// flows/llm_flows/functions.py merge_parallel_function_response_events
-	//
-	// TODO: merge_parallel_function_response_events creates a "last one wins" scenario
-	// except parts and requested_auth_configs. Check with the ADK team about
-	// the intention.
 	if other == nil {
-		return base
+		return base, nil
 	}
 	if base == nil {
-		return other
+		return other, nil
 	}
 	if other.SkipSummarization {
 		base.SkipSummarization = true
@@ -514,8 +789,38 @@ func mergeEventActions(base, other *session.EventActions) *session.EventActions
 	if other.Escalate {
 		base.Escalate = true
 	}
-	if other.StateDelta != nil {
-		base.StateDelta = other.StateDelta
+	var conflicts []string
+	if len(other.StateDelta) > 0 {
+		if base.StateDelta == nil {
+			base.StateDelta = make(map[string]any)
+		}
+		for k, v := range other.StateDelta {
+			if old, ok := base.StateDelta[k]; ok && !reflect.DeepEqual(old, v) {
+				conflicts = append(conflicts, k)
+			}
+			base.StateDelta[k] = v
+		}
+	}
+	if len(other.ArtifactDelta) > 0 {
+		if base.ArtifactDelta == nil {
+			base.ArtifactDelta = make(map[string]int64)
+		}
+		for name, version := range other.ArtifactDelta {
+			// Keep the latest version if several calls saved the same artifact.
+			base.ArtifactDelta[name] = max(base.ArtifactDelta[name], version)
+		}
+	}
+	if len(other.RequestedAuthConfigs) > 0 {
+		if base.RequestedAuthConfigs == nil {
+			base.RequestedAuthConfigs = make(map[string]*auth.Config)
+		}
+		maps.Copy(base.RequestedAuthConfigs, other.RequestedAuthConfigs)
+	}
+	if len(other.RequestedToolConfirmations) > 0 {
+		if base.RequestedToolConfirmations == nil {
+			base.RequestedToolConfirmations = make(map[string]*toolconfirmation.Confirmation)
+		}
+		maps.Copy(base.RequestedToolConfirmations, other.RequestedToolConfirmations)
 	}
-	return base
+	return base, conflicts
 }
@@ -0,0 +1,235 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// codeBlockDelimiters are the pairs of delimiters the model uses to surround
+// code to execute in a text response.
+var codeBlockDelimiters = [][2]string{
+	{"```tool_code\n", "\n```"},
+	{"```python\n", "\n```"},
+}
+
+// executionResultDelimiters surround the result of a code execution when it
+// is sent back to the model as text.
+var executionResultDelimiters = [2]string{"```tool_output\n", "\n```"}
+
+// codeExecutor returns the code executor of the agent, if any.
+func codeExecutor(ctx agent.InvocationContext) codeexecutor.CodeExecutor {
+	a, ok := ctx.Agent().(Agent)
+	if !ok {
+		return nil
+	}
+	return a.internal().CodeExecutor
+}
+
+func isBuiltInExecutor(e codeexecutor.CodeExecutor) bool {
+	switch e.(type) {
+	case codeexecutor.BuiltIn, *codeexecutor.BuiltIn:
+		return true
+	}
+	return false
+}
+
+// codeExecutionRequestProcessor prepares the request for code execution.
+//
+// With the built-in executor, the model's native code execution tool is
+// enabled. With other executors, executable code and execution results in
+// the history are converted to text, since the model only accepts them as
+// part of its own code execution.
+//
+// reference: adk-python src/google/adk/flows/llm_flows/_code_execution.py
+func codeExecutionRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest) error {
+	executor := codeExecutor(ctx)
+	if executor == nil {
+		return nil
+	}
+	if isBuiltInExecutor(executor) {
+		if req.Config == nil {
+			req.Config = &genai.GenerateContentConfig{}
+		}
+		req.Config.Tools = append(req.Config.Tools, &genai.Tool{CodeExecution: &genai.ToolCodeExecution{}})
+		return nil
+	}
+	for i, content := range req.Contents {
+		req.Contents[i] = codeExecutionPartsToText(content)
+	}
+	return nil
+}
+
+// codeExecutionPartsToText returns a copy of the content where executable
+// code and code execution result parts are replaced by delimited text.
+func codeExecutionPartsToText(c *genai.Content) *genai.Content {
+	if c == nil {
+		return nil
+	}
+	converted := false
+	parts := make([]*genai.Part, 0, len(c.Parts))
+	for _, p := range c.Parts {
+		switch {
+		case p.ExecutableCode != nil:
+			parts = append(parts, genai.NewPartFromText(codeBlockDelimiters[0][0]+p.ExecutableCode.Code+codeBlockDelimiters[0][1]))
+			converted = true
+		case p.CodeExecutionResult != nil:
+			parts = append(parts, genai.NewPartFromText(executionResultDelimiters[0]+p.CodeExecutionResult.Output+executionResultDelimiters[1]))
+			converted = true
+		default:
+			parts = append(parts, p)
+		}
+	}
+	if !converted {
+		return c
+	}
+	role := c.Role
+	if len(c.Parts) == 1 && c.Parts[0].CodeExecutionResult != nil {
+		// The execution result is given to the model as user input.
+		role = genai.RoleUser
+	}
+	return &genai.Content{Role: role, Parts: parts}
+}
+
+// codeExecutionResponseProcessor extracts the first code block from the
+// model response. The content is truncated after the code, which becomes
+// the trailing executable code part to be run by the flow.
+//
+// reference: adk-python src/google/adk/flows/llm_flows/_code_execution.py
+func codeExecutionResponseProcessor(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error {
+	if resp == nil || resp.Partial || resp.Content == nil {
+		return nil
+	}
+	executor := codeExecutor(ctx)
+	if executor == nil || isBuiltInExecutor(executor) {
+		return nil
+	}
+	extractCode(resp.Content)
+	return nil
+}
+
+// extractCode looks for the first code to execute in the content and
+// truncates the content so the code is its last part.
+func extractCode(c *genai.Content) {
+	for i, p := range c.Parts {
+		if p.ExecutableCode != nil {
+			c.Parts = c.Parts[:i+1]
+			return
+		}
+		if p.Text == "" || p.Thought {
+			continue
+		}
+		for _, d := range codeBlockDelimiters {
+			start := strings.Index(p.Text, d[0])
+			if start < 0 {
+				continue
+			}
+			end := strings.Index(p.Text[start+len(d[0]):], d[1])
+			if end < 0 {
+				continue
+			}
+			code := p.Text[start+len(d[0]) : start+len(d[0])+end]
+			var parts []*genai.Part
+			parts = append(parts, c.Parts[:i]...)
+			if prefix := p.Text[:start]; strings.TrimSpace(prefix) != "" {
+				parts = append(parts, genai.NewPartFromText(prefix))
+			}
+			parts = append(parts, genai.NewPartFromExecutableCode(code, genai.LanguagePython))
+			c.Parts = parts
+			return
+		}
+	}
+}
+
+// trailingExecutableCode returns the executable code that ends the content.
+func trailingExecutableCode(c *genai.Content) *genai.ExecutableCode {
+	if c == nil || len(c.Parts) == 0 {
+		return nil
+	}
+	return c.Parts[len(c.Parts)-1].ExecutableCode
+}
+
+// handleCodeExecution runs the code at the end of the model response with
+// the agent's code executor. The returned event holds the execution result
+// and the artifacts saved from the output files. It returns nil if there is
+// no code to execute.
+func (f *Flow) handleCodeExecution(ctx agent.InvocationContext, resp *model.LLMResponse) (*session.Event, error) {
+	if resp.Partial {
+		return nil, nil
+	}
+	executor := codeExecutor(ctx)
+	if executor == nil || isBuiltInExecutor(executor) {
+		return nil, nil
+	}
+	code := trailingExecutableCode(resp.Content)
+	if code == nil {
+		return nil, nil
+	}
+
+	result, err := executor.Execute(ctx, &codeexecutor.ExecutionInput{
+		Code:        code.Code,
+		Language:    code.Language,
+		ExecutionID: ctx.InvocationID(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute code: %w", err)
+	}
+
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+
+	var savedFiles []string
+	if artifacts := ctx.Artifacts(); artifacts != nil {
+		for _, file := range result.OutputFiles {
+			saved, err := artifacts.Save(ctx, file.Name, genai.NewPartFromBytes(file.Content, file.MIMEType))
+			if err != nil {
+				return nil, fmt.Errorf("failed to save output file %q: %w", file.Name, err)
+			}
+			if ev.Actions.ArtifactDelta == nil {
+				ev.Actions.ArtifactDelta = make(map[string]int64)
+			}
+			ev.Actions.ArtifactDelta[file.Name] = saved.Version
+			savedFiles = append(savedFiles, "`"+file.Name+"`")
+		}
+	}
+
+	var outcome genai.Outcome
+	var output string
+	if result.Stderr != "" {
+		outcome = genai.OutcomeFailed
+		output = result.Stderr
+	} else {
+		outcome = genai.OutcomeOK
+		output = "Code execution result:\n" + result.Stdout + "\n"
+		if len(savedFiles) > 0 {
+			output += "Saved artifacts:\n" + strings.Join(savedFiles, ",")
+		}
+	}
+	ev.LLMResponse = model.LLMResponse{
+		Content: &genai.Content{
+			Role:  genai.RoleModel,
+			Parts: []*genai.Part{genai.NewPartFromCodeExecutionResult(outcome, output)},
+		},
+	}
+	return ev, nil
+}
@@ -17,6 +17,7 @@ package llminternal
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -40,10 +41,19 @@ func ContentsRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest
 	if llmAgent.internal().IncludeContents == "none" {
 		// Include current turn context only (no conversation history)
 		fn = buildContentsCurrentTurnContextOnly
+	} else if w := llmAgent.internal().HistoryWindow; w != nil {
+		// Include the most recent history only.
+		fn = func(agentName, invocationBranch string, events []*session.Event) ([]*genai.Content, error) {
+			return buildContentsWindow(ctx, w, agentName, invocationBranch, events)
+		}
 	}
 	var events []*session.Event
 	if ctx.Session() != nil {
 		for e := range ctx.Session().Events().All() {
+			// The agents ignoring the compactions see the compacted events.
+			if e.Actions.Compaction != nil && llmAgent.internal().DisableEventCompaction {
+				continue
+			}
 			events = append(events, e)
 		}
 	}
@@ -58,9 +68,19 @@ func ContentsRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest
 // buildContentsDefault returns the contents for the LLM request by applying
 // filtering, rearrangement, and content processing to the given events.
 func buildContentsDefault(agentName, invocationBranch string, events []*session.Event) ([]*genai.Content, error) {
+	filtered, err := historyEvents(agentName, invocationBranch, events)
+	if err != nil {
+		return nil, err
+	}
+	return eventContents(filtered), nil
+}
+
+// historyEvents returns the events making the history of the agent, with
+// the function responses following their function calls.
+func historyEvents(agentName, invocationBranch string, events []*session.Event) ([]*session.Event, error) {
 	// parse the events, leaving the contents and the function calls and responses from the current agent.
 	var filtered []*session.Event
-	for _, ev := range events {
+	for _, ev := range applyCompactions(events) {
 		content := utils.Content(ev)
 		// Skip events without content or generated neither by user nor
 		// by model.
@@ -76,7 +96,7 @@ func buildContentsDefault(agentName, invocationBranch string, events []*session.
 		if !eventBelongsToBranch(invocationBranch, ev) {
 			continue
 		}
-		if isAuthEvent(ev) {
+		if isAuthEvent(ev) || isConfirmationEvent(ev) {
 			continue
 		}
 		if isOtherAgentReply(agentName, ev) {
@@ -93,13 +113,13 @@ func buildContentsDefault(agentName, invocationBranch string, events []*session.
 		return nil, err
 	}
 	//   - _rearrange_events_for_async_function_responses_in_history
-	filtered, err = rearrangeEventsForFunctionResponsesInHistory(filtered)
-	if err != nil {
-		return nil, err
-	}
+	return rearrangeEventsForFunctionResponsesInHistory(filtered)
+}
 
+// eventContents returns the contents of the events for the LLM request.
+func eventContents(events []*session.Event) []*genai.Content {
 	var contents []*genai.Content
-	for _, ev := range filtered {
+	for _, ev := range events {
 		content := clone(utils.Content(ev))
 		if content == nil {
 			continue
@@ -107,7 +127,58 @@ func buildContentsDefault(agentName, invocationBranch string, events []*session.
 		utils.RemoveClientFunctionCallID(content)
 		contents = append(contents, content)
 	}
-	return contents, nil
+	return contents
+}
+
+// applyCompactions replaces the events covered by the compactions recorded
+// in the session by their summaries, placed at the start of the ranges they
+// cover.
+func applyCompactions(events []*session.Event) []*session.Event {
+	var compactions []*session.EventCompaction
+	for _, ev := range events {
+		if c := ev.Actions.Compaction; c != nil && c.CompactedContent != nil {
+			compactions = append(compactions, c)
+		}
+	}
+	if len(compactions) == 0 {
+		return events
+	}
+	slices.SortStableFunc(compactions, func(a, b *session.EventCompaction) int {
+		return a.StartTimestamp.Compare(b.StartTimestamp)
+	})
+	summary := func(c *session.EventCompaction) *session.Event {
+		return &session.Event{
+			Timestamp:   c.StartTimestamp,
+			Author:      "user",
+			LLMResponse: model.LLMResponse{Content: c.CompactedContent},
+		}
+	}
+	compacted := func(ev *session.Event) bool {
+		for _, c := range compactions {
+			if !ev.Timestamp.Before(c.StartTimestamp) && !ev.Timestamp.After(c.EndTimestamp) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var ret []*session.Event
+	next := 0
+	for _, ev := range events {
+		if ev.Actions.Compaction != nil {
+			continue
+		}
+		for ; next < len(compactions) && !compactions[next].StartTimestamp.After(ev.Timestamp); next++ {
+			ret = append(ret, summary(compactions[next]))
+		}
+		if !compacted(ev) {
+			ret = append(ret, ev)
+		}
+	}
+	for _, c := range compactions[next:] {
+		ret = append(ret, summary(c))
+	}
+	return ret
 }
 
 func eventBelongsToBranch(invocationBranch string, event *session.Event) bool {
@@ -15,6 +15,7 @@
 package llminternal_test
 
 import (
+	"context"
 	"iter"
 	"slices"
 	"strings"
@@ -920,3 +921,185 @@ func (s *fakeSession) At(i int) *session.Event {
 
 var _ session.Session = (*fakeSession)(nil)
 var _ session.Events = (*fakeSession)(nil)
+
+func TestContentsRequestProcessor_Compaction(t *testing.T) {
+	const agentName = "testAgent"
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return base.Add(time.Duration(i) * time.Second) }
+	text := func(i int, author, role, text string) *session.Event {
+		return &session.Event{
+			Timestamp:   at(i),
+			Author:      author,
+			LLMResponse: model.LLMResponse{Content: genai.NewContentFromText(text, genai.Role(role))},
+		}
+	}
+	compaction := func(i, start, end int, summary string) *session.Event {
+		return &session.Event{
+			Timestamp: at(i),
+			Author:    "user",
+			Actions: session.EventActions{Compaction: &session.EventCompaction{
+				StartTimestamp:   at(start),
+				EndTimestamp:     at(end),
+				CompactedContent: genai.NewContentFromText(summary, genai.RoleModel),
+			}},
+		}
+	}
+	events := []*session.Event{
+		text(1, "user", "user", "q1"),
+		text(2, agentName, "model", "a1"),
+		text(3, "user", "user", "q2"),
+		text(4, agentName, "model", "a2"),
+		compaction(5, 1, 4, "summary of q1 to a2"),
+		text(6, "user", "user", "q3"),
+		text(7, agentName, "model", "a3"),
+		// Overlaps with the previous compaction.
+		compaction(8, 3, 7, "summary of q2 to a3"),
+		text(9, "user", "user", "q4"),
+	}
+
+	tests := []struct {
+		name    string
+		disable bool
+		want    []*genai.Content
+	}{
+		{
+			name: "summaries replace the compacted events",
+			want: []*genai.Content{
+				genai.NewContentFromText("summary of q1 to a2", genai.RoleModel),
+				genai.NewContentFromText("summary of q2 to a3", genai.RoleModel),
+				genai.NewContentFromText("q4", genai.RoleUser),
+			},
+		},
+		{
+			name:    "compaction disabled",
+			disable: true,
+			want: []*genai.Content{
+				genai.NewContentFromText("q1", genai.RoleUser),
+				genai.NewContentFromText("a1", genai.RoleModel),
+				genai.NewContentFromText("q2", genai.RoleUser),
+				genai.NewContentFromText("a2", genai.RoleModel),
+				genai.NewContentFromText("q3", genai.RoleUser),
+				genai.NewContentFromText("a3", genai.RoleModel),
+				genai.NewContentFromText("q4", genai.RoleUser),
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testAgent := utils.Must(llmagent.New(llmagent.Config{
+				Name:                   agentName,
+				Model:                  &testModel{},
+				DisableEventCompaction: tc.disable,
+			}))
+			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+				Agent:   testAgent,
+				Session: &fakeSession{events: events},
+			})
+
+			req := &model.LLMRequest{}
+			if err := llminternal.ContentsRequestProcessor(ctx, req); err != nil {
+				t.Fatalf("ContentsRequestProcessor() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, req.Contents); diff != "" {
+				t.Errorf("ContentsRequestProcessor() contents mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// contentCounter counts one token per content.
+type contentCounter struct{}
+
+func (contentCounter) CountTokens(_ context.Context, contents []*genai.Content) (int, error) {
+	return len(contents), nil
+}
+
+func TestContentsRequestProcessor_HistoryWindow(t *testing.T) {
+	const agentName = "testAgent"
+	event := func(author string, content *genai.Content) *session.Event {
+		return &session.Event{Author: author, LLMResponse: model.LLMResponse{Content: content}}
+	}
+	q1 := genai.NewContentFromText("q1", genai.RoleUser)
+	a1 := genai.NewContentFromText("a1", genai.RoleModel)
+	q2 := genai.NewContentFromText("q2", genai.RoleUser)
+	call := genai.NewContentFromFunctionCall("lookup", map[string]any{"key": "q2"}, genai.RoleModel)
+	resp := genai.NewContentFromFunctionResponse("lookup", map[string]any{"value": "v2"}, genai.RoleUser)
+	a2 := genai.NewContentFromText("a2", genai.RoleModel)
+	q3 := genai.NewContentFromText("a much longer third question", genai.RoleUser)
+	events := []*session.Event{
+		event("user", q1), event(agentName, a1),
+		event("user", q2), event(agentName, call), event(agentName, resp), event(agentName, a2),
+		event("user", q3),
+	}
+
+	tests := []struct {
+		name   string
+		window llmagent.HistoryWindow
+		events []*session.Event
+		want   []*genai.Content
+	}{
+		{
+			name:   "last turns",
+			window: llmagent.HistoryWindow{MaxTurns: 2},
+			events: events,
+			want:   []*genai.Content{q2, call, resp, a2, q3},
+		},
+		{
+			name:   "last events",
+			window: llmagent.HistoryWindow{MaxEvents: 4},
+			events: events,
+			want:   []*genai.Content{call, resp, a2, q3},
+		},
+		{
+			name:   "function response is not split from its call",
+			window: llmagent.HistoryWindow{MaxEvents: 3},
+			events: events,
+			want:   []*genai.Content{a2, q3},
+		},
+		{
+			name:   "token budget with a custom counter",
+			window: llmagent.HistoryWindow{MaxTokens: 3, TokenCounter: contentCounter{}},
+			events: events,
+			want:   []*genai.Content{a2, q3},
+		},
+		{
+			name:   "token budget with the heuristic counter",
+			window: llmagent.HistoryWindow{MaxTokens: 10},
+			events: events,
+			want:   []*genai.Content{a2, q3},
+		},
+		{
+			name:   "latest function call kept over the budget",
+			window: llmagent.HistoryWindow{MaxTokens: 1, TokenCounter: contentCounter{}},
+			events: events[:5],
+			want:   []*genai.Content{call, resp},
+		},
+		{
+			name:   "limits combined",
+			window: llmagent.HistoryWindow{MaxTurns: 3, MaxEvents: 6, MaxTokens: 100},
+			events: events,
+			want:   []*genai.Content{a1, q2, call, resp, a2, q3},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testAgent := utils.Must(llmagent.New(llmagent.Config{
+				Name:          agentName,
+				Model:         &testModel{},
+				HistoryWindow: &tc.window,
+			}))
+			ctx := icontext.NewInvocationContext(t.Context(), icontext.InvocationContextParams{
+				Agent:   testAgent,
+				Session: &fakeSession{events: tc.events},
+			})
+
+			req := &model.LLMRequest{}
+			if err := llminternal.ContentsRequestProcessor(ctx, req); err != nil {
+				t.Fatalf("ContentsRequestProcessor() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, req.Contents); diff != "" {
+				t.Errorf("ContentsRequestProcessor() contents mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
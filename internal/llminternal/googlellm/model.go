@@ -0,0 +1,42 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlellm
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IsGemini2OrAbove reports whether the model is a Gemini model of version 2
+// or above. The model name may be a resource path, e.g.
+// "projects/p/locations/l/publishers/google/models/gemini-2.5-flash".
+func IsGemini2OrAbove(modelName string) bool {
+	modelName = modelName[strings.LastIndex(modelName, "/")+1:]
+	version, ok := strings.CutPrefix(modelName, "gemini-")
+	if !ok {
+		return false
+	}
+	major, _, _ := strings.Cut(version, ".")
+	major, _, _ = strings.Cut(major, "-")
+	n, err := strconv.Atoi(major)
+	return err == nil && n >= 2
+}
+
+// CanUseOutputSchemaWithTools reports whether the model accepts a response
+// schema in the requests with function declarations.
+// see adk-python src/google/adk/utils/output_schema_utils.py
+func CanUseOutputSchemaWithTools(modelName string) bool {
+	return GetGoogleLLMVariant() == GoogleLLMVariantVertexAI && IsGemini2OrAbove(modelName)
+}
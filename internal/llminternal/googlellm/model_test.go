@@ -0,0 +1,34 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googlellm
+
+import "testing"
+
+func TestIsGemini2OrAbove(t *testing.T) {
+	for model, want := range map[string]bool{
+		"gemini-1.5-flash":             false,
+		"gemini-2.0-flash":             true,
+		"gemini-2.5-pro-preview-05-06": true,
+		"gemini-3-pro":                 true,
+		"projects/p/locations/l/publishers/google/models/gemini-2.5-flash": true,
+		"gemini-pro":     false,
+		"gpt-4o":         false,
+		"models/gemma-3": false,
+	} {
+		if got := IsGemini2OrAbove(model); got != want {
+			t.Errorf("IsGemini2OrAbove(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// HistoryWindow limits the conversation history sent to the model to its
+// most recent part. See llmagent.HistoryWindow.
+type HistoryWindow struct {
+	MaxTurns     int
+	MaxEvents    int
+	MaxTokens    int
+	TokenCounter model.TokenCounter
+}
+
+// buildContentsWindow returns the contents of the most recent history
+// within the limits of the window.
+func buildContentsWindow(ctx context.Context, w *HistoryWindow, agentName, invocationBranch string, events []*session.Event) ([]*genai.Content, error) {
+	filtered, err := historyEvents(agentName, invocationBranch, events)
+	if err != nil {
+		return nil, err
+	}
+	start, err := windowStart(ctx, w, filtered)
+	if err != nil {
+		return nil, err
+	}
+	return eventContents(filtered[start:]), nil
+}
+
+// windowStart returns the index of the first event of the history within
+// the limits of the window.
+//
+// The history never starts with a function response, so that function calls
+// and their responses are kept or dropped together. If the most recent
+// function call and response exceed the limits, they are kept anyway.
+func windowStart(ctx context.Context, w *HistoryWindow, events []*session.Event) (int, error) {
+	var cuts []int
+	for i, ev := range events {
+		if len(utils.FunctionResponses(utils.Content(ev))) == 0 {
+			cuts = append(cuts, i)
+		}
+	}
+	if len(cuts) == 0 {
+		return 0, nil
+	}
+
+	start := 0
+	if w.MaxEvents > 0 {
+		start = max(start, len(events)-w.MaxEvents)
+	}
+	if w.MaxTurns > 0 {
+		// Each user message starts a turn.
+		var turns []int
+		for _, i := range cuts {
+			if events[i].Author == "user" {
+				turns = append(turns, i)
+			}
+		}
+		if len(turns) > w.MaxTurns {
+			start = max(start, turns[len(turns)-w.MaxTurns])
+		}
+	}
+	i, _ := slices.BinarySearch(cuts, start)
+	i = min(i, len(cuts)-1)
+
+	if w.MaxTokens > 0 {
+		counter := w.TokenCounter
+		if counter == nil {
+			counter = model.HeuristicTokenCounter{}
+		}
+		// The later the history starts, the fewer tokens it has: search the
+		// first cut within the budget.
+		lo, hi := i, len(cuts)-1
+		for lo < hi {
+			mid := (lo + hi) / 2
+			n, err := counter.CountTokens(ctx, eventContents(events[cuts[mid]:]))
+			if err != nil {
+				return 0, fmt.Errorf("failed to count tokens: %w", err)
+			}
+			if n <= w.MaxTokens {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+		i = lo
+	}
+	return cuts[i], nil
+}
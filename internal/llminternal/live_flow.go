@@ -0,0 +1,229 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/agent/runconfig"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// liveInput is produced by the goroutine forwarding the live request queue
+// to the model.
+type liveInput struct {
+	event *session.Event
+	err   error
+}
+
+// liveOutput is produced by the goroutine receiving model responses.
+type liveOutput struct {
+	resp *model.LLMResponse
+	err  error
+}
+
+// runLive runs the flow over a bidirectional connection to the model.
+//
+// Unlike Run, the model is connected once and both directions are served
+// concurrently: user input is taken from the live request queue and
+// forwarded to the model, while model responses are yielded as events as
+// soon as they arrive. Function calls are executed as they are received and
+// their responses are sent back over the same connection.
+//
+// reference: adk-python src/google/adk/flows/llm_flows/base_llm_flow.py run_live
+func (f *Flow) runLive(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		cfg := runconfig.FromContext(ctx)
+		if cfg == nil || cfg.LiveRequestQueue == nil {
+			yield(nil, fmt.Errorf("live request queue is required in bidi streaming mode"))
+			return
+		}
+		liveModel, ok := f.Model.(model.LiveLLM)
+		if !ok {
+			yield(nil, fmt.Errorf("model %q does not support bidi streaming", f.Model.Name()))
+			return
+		}
+
+		req := &model.LLMRequest{}
+		if err := f.preprocess(ctx, req); err != nil {
+			yield(nil, err)
+			return
+		}
+		if ctx.Ended() {
+			return
+		}
+		tools, err := requestTools(req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// The live connection counts as a single call to the model.
+		if err := cfg.Limits.CountLLMCall(ctx); err != nil {
+			yield(nil, err)
+			return
+		}
+		conn, err := liveModel.ConnectLive(ctx, req)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to connect to model %q: %w", f.Model.Name(), err))
+			return
+		}
+
+		liveCtx, cancel := context.WithCancel(ctx)
+		inputs := make(chan liveInput)
+		outputs := make(chan liveOutput)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			sendToModel(liveCtx, ctx, cfg.LiveRequestQueue, conn, inputs)
+		}()
+		go func() {
+			defer wg.Done()
+			defer close(outputs)
+			for resp, err := range conn.Receive(liveCtx) {
+				select {
+				case outputs <- liveOutput{resp: resp, err: err}:
+				case <-liveCtx.Done():
+					return
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+		// stop tears down the connection. It must be called before handing
+		// the queue over to another agent.
+		stop := sync.OnceFunc(func() {
+			cancel()
+			conn.Close()
+			wg.Wait()
+		})
+		defer stop()
+
+		for {
+			var out liveOutput
+			select {
+			case in := <-inputs:
+				if !yield(in.event, in.err) || in.err != nil {
+					return
+				}
+				continue
+			case o, ok := <-outputs:
+				if !ok {
+					return
+				}
+				out = o
+			}
+			if out.err != nil {
+				yield(nil, out.err)
+				return
+			}
+			resp := out.resp
+			if err := f.postprocess(ctx, req, resp); err != nil {
+				yield(nil, err)
+				return
+			}
+			if resp.Content == nil && resp.ErrorCode == "" && !resp.Interrupted && !resp.TurnComplete {
+				continue
+			}
+
+			ev := f.finalizeModelResponseEvent(ctx, resp, tools, make(map[string]any))
+			if !yield(ev, nil) {
+				return
+			}
+
+			fnResponseEvent, err := f.handleFunctionCalls(ctx, tools, resp)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if fnResponseEvent == nil {
+				continue
+			}
+			if !yield(fnResponseEvent, nil) {
+				return
+			}
+
+			if fnResponseEvent.Actions.TransferToAgent != "" {
+				nextAgent := f.agentToRun(ctx, fnResponseEvent.Actions.TransferToAgent)
+				if nextAgent == nil {
+					yield(nil, fmt.Errorf("failed to find agent: %s", fnResponseEvent.Actions.TransferToAgent))
+					return
+				}
+				// The next agent opens its own connection and takes over the queue.
+				stop()
+				for ev, err := range nextAgent.Run(ctx) {
+					if !yield(ev, err) || err != nil {
+						return
+					}
+				}
+				return
+			}
+
+			if err := conn.Send(liveCtx, &model.LiveRequest{Content: fnResponseEvent.Content}); err != nil {
+				yield(nil, fmt.Errorf("failed to send function responses to model: %w", err))
+				return
+			}
+		}
+	}
+}
+
+// sendToModel forwards requests from the live request queue to the model
+// until the queue is closed or ctx is done.
+// User content is reported back as an event so that it becomes part of the
+// session history before the model responds to it.
+func sendToModel(ctx context.Context, ictx agent.InvocationContext, queue *agent.LiveRequestQueue, conn model.LiveConnection, inputs chan<- liveInput) {
+	report := func(in liveInput) bool {
+		select {
+		case inputs <- in:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	for {
+		req, err := queue.Receive(ctx)
+		if err != nil {
+			return
+		}
+		if req.Close {
+			conn.Close()
+			return
+		}
+		if req.Content != nil {
+			ev := session.NewEvent(ictx.InvocationID())
+			ev.Author = "user"
+			ev.Branch = ictx.Branch()
+			ev.LLMResponse = model.LLMResponse{Content: req.Content}
+			if ev.Content.Role == "" {
+				ev.Content.Role = genai.RoleUser
+			}
+			if !report(liveInput{event: ev}) {
+				return
+			}
+		}
+		if err := conn.Send(ctx, req); err != nil {
+			report(liveInput{err: fmt.Errorf("failed to send live request to model: %w", err)})
+			return
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// continuationPrompt asks the model to continue a truncated response.
+const continuationPrompt = "Your previous response was cut off because it reached the maximum output length. Continue it exactly where it stopped, without repeating any of it."
+
+// generateContent calls the LLM and asks the model to continue the responses
+// truncated because they reached the maximum number of output tokens, up to
+// the MaxOutputContinuations of the agent.
+//
+// Partial responses are forwarded as they arrive, while the final responses
+// of the continued calls are stitched into a single response. If a response
+// is still truncated once the continuations are exhausted, it is yielded,
+// followed by model.ErrOutputTruncated. Without continuations, the
+// truncated response is yielded as is, without an error, unless the agent
+// sets FailOnTruncatedOutput.
+func (f *Flow) generateContent(ctx agent.InvocationContext, req *model.LLMRequest, stateDelta map[string]any) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		maxContinuations, failOnTruncated := 0, false
+		if a, ok := ctx.Agent().(Agent); ok {
+			maxContinuations = a.internal().MaxOutputContinuations
+			failOnTruncated = maxContinuations > 0 || a.internal().FailOnTruncatedOutput
+		}
+
+		var truncated *model.LLMResponse
+		for continuations := 0; ; continuations++ {
+			var next *model.LLMResponse
+			for resp, err := range finalizeStream(f.callLLM(ctx, req, stateDelta)) {
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				if resp.Partial {
+					if !yield(resp, nil) {
+						return
+					}
+					continue
+				}
+				if truncated != nil {
+					resp = stitchResponses(truncated, resp)
+					truncated = nil
+				}
+				if isTruncated(resp) {
+					next = resp
+					continue
+				}
+				if !yield(resp, nil) {
+					return
+				}
+			}
+			if next == nil {
+				return
+			}
+			if continuations >= maxContinuations {
+				if !yield(next, nil) || !failOnTruncated {
+					return
+				}
+				yield(nil, fmt.Errorf("agent %q: %w", ctx.Agent().Name(), model.ErrOutputTruncated))
+				return
+			}
+			truncated = next
+			req = continuationRequest(req, next)
+		}
+	}
+}
+
+// finalizeStream makes sure a stream of responses ends with a final
+// response: if the last response is partial, the text of the partial
+// responses since the last final response is yielded as a final response.
+func finalizeStream(responses iter.Seq2[*model.LLMResponse, error]) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		var last *model.LLMResponse
+		var text, thoughts strings.Builder
+		for resp, err := range responses {
+			if !yield(resp, err) || err != nil {
+				return
+			}
+			last = resp
+			if !resp.Partial {
+				text.Reset()
+				thoughts.Reset()
+				continue
+			}
+			if resp.Content == nil {
+				continue
+			}
+			for _, p := range resp.Content.Parts {
+				if p.Thought {
+					thoughts.WriteString(p.Text)
+				} else {
+					text.WriteString(p.Text)
+				}
+			}
+		}
+		if last == nil || !last.Partial || text.Len()+thoughts.Len() == 0 {
+			return
+		}
+		content := &genai.Content{Role: genai.RoleModel}
+		if thoughts.Len() > 0 {
+			content.Parts = append(content.Parts, &genai.Part{Text: thoughts.String(), Thought: true})
+		}
+		if text.Len() > 0 {
+			content.Parts = append(content.Parts, &genai.Part{Text: text.String()})
+		}
+		yield(&model.LLMResponse{
+			Content:       content,
+			FinishReason:  last.FinishReason,
+			UsageMetadata: last.UsageMetadata,
+		}, nil)
+	}
+}
+
+// isTruncated reports whether the model stopped generating the response
+// because it reached the maximum number of output tokens.
+func isTruncated(resp *model.LLMResponse) bool {
+	return resp.FinishReason == genai.FinishReasonMaxTokens && len(utils.FunctionCalls(resp.Content)) == 0
+}
+
+// continuationRequest returns the request asking the model to continue the
+// truncated response.
+func continuationRequest(req *model.LLMRequest, truncated *model.LLMResponse) *model.LLMRequest {
+	prev := &genai.Content{Role: genai.RoleModel}
+	if truncated.Content != nil {
+		for _, p := range truncated.Content.Parts {
+			if !p.Thought {
+				prev.Parts = append(prev.Parts, p)
+			}
+		}
+	}
+	next := *req
+	next.Contents = append(slices.Clone(req.Contents), prev, genai.NewContentFromText(continuationPrompt, genai.RoleUser))
+	return &next
+}
+
+// stitchResponses returns the response made of the truncated response
+// followed by its continuation.
+func stitchResponses(truncated, continuation *model.LLMResponse) *model.LLMResponse {
+	ret := *continuation
+	content := &genai.Content{Role: genai.RoleModel}
+	for _, c := range []*genai.Content{truncated.Content, continuation.Content} {
+		if c == nil {
+			continue
+		}
+		for _, p := range c.Parts {
+			// Merge the consecutive text parts.
+			if n := len(content.Parts); n > 0 && isText(p) && isText(content.Parts[n-1]) && content.Parts[n-1].Thought == p.Thought {
+				merged := *content.Parts[n-1]
+				merged.Text += p.Text
+				content.Parts[n-1] = &merged
+				continue
+			}
+			content.Parts = append(content.Parts, p)
+		}
+	}
+	ret.Content = content
+	ret.UsageMetadata = addUsage(truncated.UsageMetadata, continuation.UsageMetadata)
+	return &ret
+}
+
+func isText(p *genai.Part) bool {
+	return p.Text != "" && p.FunctionCall == nil && p.FunctionResponse == nil && p.InlineData == nil &&
+		p.FileData == nil && p.ExecutableCode == nil && p.CodeExecutionResult == nil
+}
+
+// addUsage returns the token counts of both calls.
+func addUsage(a, b *genai.GenerateContentResponseUsageMetadata) *genai.GenerateContentResponseUsageMetadata {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	trafficType := b.TrafficType
+	if trafficType == "" {
+		trafficType = a.TrafficType
+	}
+	return &genai.GenerateContentResponseUsageMetadata{
+		CacheTokensDetails:         addModalityTokens(a.CacheTokensDetails, b.CacheTokensDetails),
+		CachedContentTokenCount:    a.CachedContentTokenCount + b.CachedContentTokenCount,
+		CandidatesTokenCount:       a.CandidatesTokenCount + b.CandidatesTokenCount,
+		CandidatesTokensDetails:    addModalityTokens(a.CandidatesTokensDetails, b.CandidatesTokensDetails),
+		PromptTokenCount:           a.PromptTokenCount + b.PromptTokenCount,
+		PromptTokensDetails:        addModalityTokens(a.PromptTokensDetails, b.PromptTokensDetails),
+		ThoughtsTokenCount:         a.ThoughtsTokenCount + b.ThoughtsTokenCount,
+		ToolUsePromptTokenCount:    a.ToolUsePromptTokenCount + b.ToolUsePromptTokenCount,
+		ToolUsePromptTokensDetails: addModalityTokens(a.ToolUsePromptTokensDetails, b.ToolUsePromptTokensDetails),
+		TotalTokenCount:            a.TotalTokenCount + b.TotalTokenCount,
+		TrafficType:                trafficType,
+	}
+}
+
+// addModalityTokens returns the token counts of both calls by modality, in
+// the order the modalities first appear.
+func addModalityTokens(a, b []*genai.ModalityTokenCount) []*genai.ModalityTokenCount {
+	var ret []*genai.ModalityTokenCount
+	for _, c := range slices.Concat(a, b) {
+		if c == nil {
+			continue
+		}
+		i := slices.IndexFunc(ret, func(r *genai.ModalityTokenCount) bool { return r.Modality == c.Modality })
+		if i < 0 {
+			ret = append(ret, &genai.ModalityTokenCount{Modality: c.Modality, TokenCount: c.TokenCount})
+			continue
+		}
+		ret[i].TokenCount += c.TokenCount
+	}
+	return ret
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestFinalizeStream(t *testing.T) {
+	partial := func(text string, thought bool) *model.LLMResponse {
+		return &model.LLMResponse{
+			Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{Text: text, Thought: thought}}},
+			Partial: true,
+		}
+	}
+	final := &model.LLMResponse{Content: genai.NewContentFromText("done", genai.RoleModel)}
+	truncatedChunk := partial("lo", false)
+	truncatedChunk.FinishReason = genai.FinishReasonMaxTokens
+
+	tests := []struct {
+		name      string
+		responses []*model.LLMResponse
+		want      []*model.LLMResponse
+	}{
+		{
+			name:      "ends with a final response",
+			responses: []*model.LLMResponse{partial("do", false), partial("ne", false), final},
+			want:      []*model.LLMResponse{partial("do", false), partial("ne", false), final},
+		},
+		{
+			name:      "ends with a partial response",
+			responses: []*model.LLMResponse{final, partial("hmm", true), partial("Hel", false), truncatedChunk},
+			want: []*model.LLMResponse{final, partial("hmm", true), partial("Hel", false), truncatedChunk, {
+				Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+					{Text: "hmm", Thought: true},
+					{Text: "Hello"},
+				}},
+				FinishReason: genai.FinishReasonMaxTokens,
+			}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stream iter.Seq2[*model.LLMResponse, error] = func(yield func(*model.LLMResponse, error) bool) {
+				for _, resp := range tc.responses {
+					if !yield(resp, nil) {
+						return
+					}
+				}
+			}
+			var got []*model.LLMResponse
+			for resp, err := range finalizeStream(stream) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, resp)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("finalizeStream() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStitchResponses(t *testing.T) {
+	truncated := &model.LLMResponse{
+		Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+			{Text: "plan", Thought: true},
+			{Text: "Hello, "},
+		}},
+		FinishReason: genai.FinishReasonMaxTokens,
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:        10,
+			CachedContentTokenCount: 8,
+			CandidatesTokenCount:    5,
+			ToolUsePromptTokenCount: 1,
+			TotalTokenCount:         15,
+			PromptTokensDetails:     []*genai.ModalityTokenCount{{Modality: genai.MediaModalityText, TokenCount: 10}},
+			CacheTokensDetails:      []*genai.ModalityTokenCount{{Modality: genai.MediaModalityText, TokenCount: 8}},
+		},
+	}
+	continuation := &model.LLMResponse{
+		Content:      genai.NewContentFromText("world!", genai.RoleModel),
+		FinishReason: genai.FinishReasonStop,
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:        20,
+			CachedContentTokenCount: 8,
+			CandidatesTokenCount:    2,
+			TotalTokenCount:         22,
+			PromptTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityText, TokenCount: 15},
+				{Modality: genai.MediaModalityImage, TokenCount: 5},
+			},
+			CacheTokensDetails: []*genai.ModalityTokenCount{{Modality: genai.MediaModalityText, TokenCount: 8}},
+		},
+	}
+	want := &model.LLMResponse{
+		Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+			{Text: "plan", Thought: true},
+			{Text: "Hello, world!"},
+		}},
+		FinishReason: genai.FinishReasonStop,
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:        30,
+			CachedContentTokenCount: 16,
+			CandidatesTokenCount:    7,
+			ToolUsePromptTokenCount: 1,
+			TotalTokenCount:         37,
+			PromptTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityText, TokenCount: 25},
+				{Modality: genai.MediaModalityImage, TokenCount: 5},
+			},
+			CacheTokensDetails: []*genai.ModalityTokenCount{{Modality: genai.MediaModalityText, TokenCount: 16}},
+		},
+	}
+	if diff := cmp.Diff(want, stitchResponses(truncated, continuation)); diff != "" {
+		t.Errorf("stitchResponses() mismatch (-want +got):\n%s", diff)
+	}
+	if got := truncated.Content.Parts[1].Text; got != "Hello, " {
+		t.Errorf("stitchResponses() modified the truncated response: %q", got)
+	}
+}
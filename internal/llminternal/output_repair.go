@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// repairPrompt asks the model to repair an output rejected by the
+// OutputValidator of the agent.
+const repairPrompt = "Your previous response is invalid: %v\nReply again with only the corrected JSON value, matching the response schema."
+
+// generateValidContent calls the LLM like generateContent and validates the
+// final text responses with the OutputValidator of the agent. The model is
+// asked to repair the invalid responses, up to the MaxOutputRepairs of the
+// agent.
+//
+// The invalid responses are not yielded, except the last one once the
+// repairs are exhausted, followed by model.ErrInvalidOutput.
+func (f *Flow) generateValidContent(ctx agent.InvocationContext, req *model.LLMRequest, stateDelta map[string]any) iter.Seq2[*model.LLMResponse, error] {
+	var (
+		validate   func(string) (any, error)
+		maxRepairs int
+	)
+	if a, ok := ctx.Agent().(Agent); ok {
+		validate, maxRepairs = a.internal().OutputValidator, a.internal().MaxOutputRepairs
+	}
+	if validate == nil {
+		return f.generateContent(ctx, req, stateDelta)
+	}
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for repairs := 0; ; repairs++ {
+			var (
+				invalid    *model.LLMResponse
+				invalidErr error
+			)
+			for resp, err := range f.generateContent(ctx, req, stateDelta) {
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				if text, ok := finalOutput(resp); ok {
+					if _, err := validate(text); err != nil {
+						invalid, invalidErr = resp, err
+						continue
+					}
+				}
+				if !yield(resp, nil) {
+					return
+				}
+			}
+			if invalid == nil {
+				return
+			}
+			if repairs >= maxRepairs {
+				if !yield(invalid, nil) {
+					return
+				}
+				yield(nil, fmt.Errorf("agent %q: %w: %v", ctx.Agent().Name(), model.ErrInvalidOutput, invalidErr))
+				return
+			}
+			req = repairRequest(req, invalid, invalidErr)
+		}
+	}
+}
+
+// finalOutput returns the text of a final model response, if it is a reply
+// of the agent rather than function calls.
+func finalOutput(resp *model.LLMResponse) (string, bool) {
+	if resp.Partial || resp.ErrorCode != "" || resp.Content == nil || len(utils.FunctionCalls(resp.Content)) > 0 {
+		return "", false
+	}
+	var sb strings.Builder
+	for _, p := range resp.Content.Parts {
+		if !p.Thought {
+			sb.WriteString(p.Text)
+		}
+	}
+	if strings.TrimSpace(sb.String()) == "" {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+// repairRequest returns the request asking the model to repair the invalid
+// response.
+func repairRequest(req *model.LLMRequest, invalid *model.LLMResponse, invalidErr error) *model.LLMRequest {
+	prev := &genai.Content{Role: genai.RoleModel}
+	for _, p := range invalid.Content.Parts {
+		if !p.Thought {
+			prev.Parts = append(prev.Parts, p)
+		}
+	}
+	next := *req
+	next.Contents = append(slices.Clone(req.Contents), prev, genai.NewContentFromText(fmt.Sprintf(repairPrompt, invalidErr), genai.RoleUser))
+	return &next
+}
@@ -0,0 +1,263 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/llminternal/googlellm"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+)
+
+// reference: adk-python src/google/adk/flows/llm_flows/_output_schema_processor.py
+
+const setModelResponseToolName = "set_model_response"
+
+const setModelResponseInstruction = `IMPORTANT: You have access to a special tool "set_model_response". ` +
+	`Once you are ready to provide your final response, you must call it with the response, ` +
+	`following the required structure, instead of replying with text.`
+
+// outputSchemaRequestProcessor lets the agents with an output schema use
+// tools with the models which do not accept a response schema along with
+// function declarations: the response schema is replaced with the
+// set_model_response tool, whose arguments are the final response of the
+// agent. Since function parameters are objects, the other schemas are
+// wrapped in an object with a single "result" property.
+func outputSchemaRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest) error {
+	llmAgent := asLLMAgent(ctx.Agent())
+	if llmAgent == nil {
+		return nil
+	}
+	state := llmAgent.internal()
+	if len(state.Tools) == 0 && len(state.Toolsets) == 0 {
+		return nil
+	}
+	cfg := req.Config
+	if cfg == nil || (cfg.ResponseSchema == nil && cfg.ResponseJsonSchema == nil) {
+		return nil
+	}
+	if state.Model != nil && googlellm.CanUseOutputSchemaWithTools(state.Model.Name()) {
+		return nil
+	}
+
+	t := &setModelResponseTool{
+		schema:     cfg.ResponseSchema,
+		jsonSchema: cfg.ResponseJsonSchema,
+		validate:   state.OutputValidator,
+	}
+	if !isObjectSchema(cfg.ResponseSchema, cfg.ResponseJsonSchema) {
+		t.wrapped = true
+		if t.schema != nil {
+			t.schema = &genai.Schema{
+				Type:       genai.TypeObject,
+				Properties: map[string]*genai.Schema{resultKey: t.schema},
+				Required:   []string{resultKey},
+			}
+		}
+		if t.jsonSchema != nil {
+			t.jsonSchema = map[string]any{
+				"type":       "object",
+				"properties": map[string]any{resultKey: t.jsonSchema},
+				"required":   []string{resultKey},
+			}
+		}
+	}
+	cfg.ResponseSchema = nil
+	cfg.ResponseJsonSchema = nil
+	cfg.ResponseMIMEType = ""
+	utils.AppendInstructions(req, setModelResponseInstruction)
+	return appendTools(req, t)
+}
+
+// isObjectSchema reports whether the response schema describes an object.
+func isObjectSchema(schema *genai.Schema, jsonSchema any) bool {
+	if schema != nil {
+		return schema.Type == genai.TypeObject
+	}
+	b, err := json.Marshal(jsonSchema)
+	if err != nil {
+		return false
+	}
+	var s struct {
+		Type any `json:"type"`
+	}
+	return json.Unmarshal(b, &s) == nil && s.Type == "object"
+}
+
+// resultKey is the property holding the response of the set_model_response
+// tool when its schema is not an object.
+const resultKey = "result"
+
+// setModelResponseTool is the tool called by the model with its final
+// response, in place of a response schema.
+type setModelResponseTool struct {
+	schema     *genai.Schema
+	jsonSchema any
+	validate   func(output string) (any, error)
+	// wrapped reports whether the response schema is wrapped in an object
+	// with a single resultKey property.
+	wrapped bool
+}
+
+// Name implements tool.Tool.
+func (t *setModelResponseTool) Name() string {
+	return setModelResponseToolName
+}
+
+// Description implements tool.Tool.
+func (t *setModelResponseTool) Description() string {
+	return "Sets your final response using the required output schema. Use this tool to provide your final structured answer instead of outputting text directly."
+}
+
+// IsLongRunning implements tool.Tool.
+func (t *setModelResponseTool) IsLongRunning() bool {
+	return false
+}
+
+// Declaration implements toolinternal.FunctionTool.
+func (t *setModelResponseTool) Declaration() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:                 t.Name(),
+		Description:          t.Description(),
+		Parameters:           t.schema,
+		ParametersJsonSchema: t.jsonSchema,
+	}
+}
+
+// ProcessRequest implements toolinternal.RequestProcessor.
+func (t *setModelResponseTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
+	return appendTools(req, t)
+}
+
+// Run implements toolinternal.FunctionTool. It validates the response and
+// returns it: the flow then yields it as the final response of the agent.
+func (t *setModelResponseTool) Run(ctx tool.Context, args any) (map[string]any, error) {
+	m, ok := args.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected args type: %T", args)
+	}
+	output, err := t.output(m)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case t.validate != nil:
+		_, err = t.validate(output)
+	case t.schema != nil:
+		var args []byte
+		if args, err = json.Marshal(m); err == nil {
+			_, err = utils.ValidateOutputSchema(string(args), t.schema)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return m, nil
+}
+
+// output returns the JSON encoding of the response set with the arguments
+// of the tool.
+func (t *setModelResponseTool) output(args map[string]any) (string, error) {
+	var v any = args
+	if t.wrapped {
+		result, ok := args[resultKey]
+		if !ok {
+			return "", fmt.Errorf("invalid response: missing %q", resultKey)
+		}
+		v = result
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the response: %w", err)
+	}
+	return string(b), nil
+}
+
+// structuredResponseEvent returns the final response event of the agent
+// made of the response set with the set_model_response tool, if the
+// function response event holds it.
+//
+// If the response was rejected, the model is asked to repair it, up to the
+// MaxOutputRepairs of the agent in the invocation: it then returns an error
+// wrapping model.ErrInvalidOutput.
+func structuredResponseEvent(ctx agent.InvocationContext, tools map[string]tool.Tool, ev *session.Event) (*session.Event, error) {
+	if ev.Content == nil {
+		return nil, nil
+	}
+	for _, p := range ev.Content.Parts {
+		fr := p.FunctionResponse
+		if fr == nil || fr.Name != setModelResponseToolName {
+			continue
+		}
+		if invalidErr, failed := fr.Response["error"]; failed {
+			maxRepairs := 0
+			if a, ok := ctx.Agent().(Agent); ok {
+				maxRepairs = a.internal().MaxOutputRepairs
+			}
+			if failedResponses(ctx, ev) > maxRepairs {
+				return nil, fmt.Errorf("agent %q: %w: %v", ctx.Agent().Name(), model.ErrInvalidOutput, invalidErr)
+			}
+			return nil, nil
+		}
+		t, ok := tools[setModelResponseToolName].(*setModelResponseTool)
+		if !ok {
+			return nil, fmt.Errorf("agent %q has no %s tool", ctx.Agent().Name(), setModelResponseToolName)
+		}
+		output, err := t.output(fr.Response)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode the response of agent %q: %w", ctx.Agent().Name(), err)
+		}
+		resp := session.NewEvent(ctx.InvocationID())
+		resp.Author = ctx.Agent().Name()
+		resp.Branch = ctx.Branch()
+		resp.LLMResponse = model.LLMResponse{
+			Content: genai.NewContentFromText(output, genai.RoleModel),
+		}
+		return resp, nil
+	}
+	return nil, nil
+}
+
+// failedResponses returns the number of rejected set_model_response calls
+// of the agent in the invocation, including the ones of ev.
+func failedResponses(ctx agent.InvocationContext, ev *session.Event) int {
+	failed := func(ev *session.Event) bool {
+		for _, fr := range utils.FunctionResponses(ev.Content) {
+			if _, ok := fr.Response["error"]; ok && fr.Name == setModelResponseToolName {
+				return true
+			}
+		}
+		return false
+	}
+	n := 0
+	if failed(ev) {
+		n++
+	}
+	if ctx.Session() == nil {
+		return n
+	}
+	for e := range ctx.Session().Events().All() {
+		if e.ID != ev.ID && e.InvocationID == ctx.InvocationID() && e.Author == ctx.Agent().Name() && e.Branch == ctx.Branch() && failed(e) {
+			n++
+		}
+	}
+	return n
+}
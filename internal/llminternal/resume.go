@@ -0,0 +1,62 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"slices"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
+	"google.golang.org/adk/internal/agent/checkpoint"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/toolconfirmation"
+	"google.golang.org/genai"
+)
+
+// resumePendingFunctionCalls calls the functions left without response
+// when a resumed invocation was interrupted, i.e. the function calls of the
+// last event of the agent in the invocation. The returned event holds their
+// function responses. It returns nil if there is nothing to resume.
+//
+// The long-running function calls and the credential and confirmation
+// requests are left to the client.
+func (f *Flow) resumePendingFunctionCalls(ctx agent.InvocationContext, tools map[string]tool.Tool) (*session.Event, error) {
+	if !checkpoint.FromContext(ctx).Resumed() || ctx.Session() == nil {
+		return nil, nil
+	}
+	events := ctx.Session().Events()
+	for i := events.Len() - 1; i >= 0; i-- {
+		ev := events.At(i)
+		if ev.InvocationID != ctx.InvocationID() {
+			return nil, nil
+		}
+		if ev.Author != ctx.Agent().Name() || ev.Branch != ctx.Branch() || ev.Partial {
+			continue
+		}
+		var calls []*genai.FunctionCall
+		for _, fc := range utils.FunctionCalls(ev.Content) {
+			if fc.Name != auth.RequestCredentialFunctionName && fc.Name != toolconfirmation.FunctionName && !slices.Contains(ev.LongRunningToolIDs, fc.ID) {
+				calls = append(calls, fc)
+			}
+		}
+		if len(calls) == 0 {
+			return nil, nil
+		}
+		return f.callFunctions(ctx, tools, calls, nil)
+	}
+	return nil, nil
+}
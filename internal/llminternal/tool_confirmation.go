@@ -0,0 +1,215 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/typeutil"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/toolconfirmation"
+	"google.golang.org/genai"
+)
+
+// reference: adk-python src/google/adk/flows/llm_flows/request_confirmation.py
+
+const (
+	confirmationRequiredMessage = "This tool call requires confirmation, please approve or reject."
+	confirmationRejectedMessage = "This tool call is rejected."
+)
+
+// requestConfirmation records the confirmation request of the tool call in
+// the actions of toolCtx if the call must be confirmed, according to the
+// policy of the agent or to the tool. It reports whether it did.
+func (f *Flow) requestConfirmation(t tool.Tool, args map[string]any, toolCtx tool.Context) bool {
+	var (
+		hint     string
+		required bool
+	)
+	if f.ToolConfirmationPolicy != nil {
+		hint, required = f.ToolConfirmationPolicy(toolCtx, t, args)
+	}
+	if ct, ok := t.(tool.ConfirmableTool); ok && !required {
+		hint, required = ct.RequiresConfirmation(toolCtx, args)
+	}
+	if !required {
+		return false
+	}
+	if hint == "" {
+		hint = fmt.Sprintf("Please approve or reject the tool call %s() by responding with a FunctionResponse with a confirmation.", t.Name())
+	}
+	actions := toolCtx.Actions()
+	if actions.RequestedToolConfirmations == nil {
+		actions.RequestedToolConfirmations = make(map[string]*toolconfirmation.Confirmation)
+	}
+	actions.RequestedToolConfirmations[toolCtx.FunctionCallID()] = &toolconfirmation.Confirmation{
+		Hint: hint,
+		Args: maps.Clone(args),
+	}
+	return true
+}
+
+// generateConfirmationEvent returns the event asking for the confirmations
+// requested for the function calls, or nil if there are none.
+func generateConfirmationEvent(ctx agent.InvocationContext, fnResponseEvent *session.Event) (*session.Event, error) {
+	requested := fnResponseEvent.Actions.RequestedToolConfirmations
+	if len(requested) == 0 {
+		return nil, nil
+	}
+	names := make(map[string]string)
+	for _, resp := range utils.FunctionResponses(fnResponseEvent.Content) {
+		names[resp.ID] = resp.Name
+	}
+	content := &genai.Content{Role: genai.RoleModel}
+	for _, id := range slices.Sorted(maps.Keys(requested)) {
+		confirmation := requested[id]
+		args, err := typeutil.ConvertToWithJSONSchema[toolconfirmation.RequestArgs, map[string]any](toolconfirmation.RequestArgs{
+			OriginalFunctionCall: &genai.FunctionCall{ID: id, Name: names[id], Args: confirmation.Args},
+			ToolConfirmation:     confirmation,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build confirmation request: %w", err)
+		}
+		content.Parts = append(content.Parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			Name: toolconfirmation.FunctionName,
+			Args: args,
+		}})
+	}
+	utils.PopulateClientFunctionCallID(content)
+
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.LLMResponse = model.LLMResponse{Content: content}
+	for _, fc := range utils.FunctionCalls(content) {
+		ev.LongRunningToolIDs = append(ev.LongRunningToolIDs, fc.ID)
+	}
+	return ev, nil
+}
+
+// confirmationPreprocess resumes the function calls that were waiting for a
+// confirmation.
+//
+// If the latest event is the client response to confirmation requests, the
+// confirmed tool calls are run, with the arguments of the confirmations if
+// set, and the rejected ones get a rejection response. The returned event
+// holds their function responses. It returns nil if there is nothing to
+// resume.
+func (f *Flow) confirmationPreprocess(ctx agent.InvocationContext, tools map[string]tool.Tool) (*session.Event, error) {
+	if ctx.Session() == nil {
+		return nil, nil
+	}
+	events := ctx.Session().Events()
+	if events.Len() == 0 {
+		return nil, nil
+	}
+	last := events.At(events.Len() - 1)
+	if last.Author != "user" {
+		return nil, nil
+	}
+
+	var requestIDs []string
+	responses := make(map[string]*toolconfirmation.Confirmation)
+	for _, resp := range utils.FunctionResponses(last.Content) {
+		if resp.Name != toolconfirmation.FunctionName {
+			continue
+		}
+		confirmation, err := typeutil.ConvertToWithJSONSchema[map[string]any, *toolconfirmation.Confirmation](resp.Response, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse confirmation response %q: %w", resp.ID, err)
+		}
+		requestIDs = append(requestIDs, resp.ID)
+		responses[resp.ID] = confirmation
+	}
+	if len(requestIDs) == 0 {
+		return nil, nil
+	}
+
+	// Find the tool calls to confirm in the confirmation requests of the
+	// agent. The requests already answered, or whose tool calls already
+	// got a response since, are not pending anymore: a confirmation sent
+	// again must not run the tool again.
+	requests := make(map[string]*genai.FunctionCall)
+	responded := make(map[string]bool)
+	for i := events.Len() - 2; i >= 0; i-- {
+		ev := events.At(i)
+		if ev.Author != ctx.Agent().Name() || ev.Branch != ctx.Branch() {
+			for _, resp := range utils.FunctionResponses(ev.Content) {
+				responded[resp.ID] = true
+			}
+			continue
+		}
+		for _, fc := range utils.FunctionCalls(ev.Content) {
+			if fc.Name != toolconfirmation.FunctionName || responses[fc.ID] == nil || responded[fc.ID] {
+				continue
+			}
+			args, err := typeutil.ConvertToWithJSONSchema[map[string]any, toolconfirmation.RequestArgs](fc.Args, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse confirmation request %q: %w", fc.ID, err)
+			}
+			if args.OriginalFunctionCall == nil {
+				return nil, fmt.Errorf("confirmation request %q has no function call", fc.ID)
+			}
+			if !responded[args.OriginalFunctionCall.ID] {
+				requests[fc.ID] = args.OriginalFunctionCall
+			}
+		}
+		for _, resp := range utils.FunctionResponses(ev.Content) {
+			responded[resp.ID] = true
+		}
+	}
+
+	var calls []*genai.FunctionCall
+	confirmations := make(map[string]*toolconfirmation.Confirmation)
+	for _, id := range requestIDs {
+		original, ok := requests[id]
+		if !ok {
+			continue
+		}
+		confirmation := responses[id]
+		call := *original
+		if confirmation.Confirmed && confirmation.Args != nil {
+			call.Args = confirmation.Args
+		}
+		calls = append(calls, &call)
+		confirmations[call.ID] = confirmation
+	}
+	if len(calls) == 0 {
+		return nil, nil
+	}
+	return f.callFunctions(ctx, tools, calls, confirmations)
+}
+
+func isConfirmationEvent(ev *session.Event) bool {
+	c := utils.Content(ev)
+	if c == nil {
+		return false
+	}
+	for _, p := range c.Parts {
+		if p.FunctionCall != nil && p.FunctionCall.Name == toolconfirmation.FunctionName {
+			return true
+		}
+		if p.FunctionResponse != nil && p.FunctionResponse.Name == toolconfirmation.FunctionName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugininternal runs the callbacks of the plugins of the runner.
+package plugininternal
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/plugininternal/pluginctx"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+)
+
+// Manager runs the callbacks of the plugins in their registration order,
+// until one returns a non-nil value or an error.
+//
+// A nil *Manager has no plugin.
+type Manager struct {
+	plugins []*plugin.Plugin
+}
+
+// NewManager returns the manager of the plugins, or nil if there is none.
+func NewManager(plugins []*plugin.Plugin) (*Manager, error) {
+	if len(plugins) == 0 {
+		return nil, nil
+	}
+	names := make(map[string]bool)
+	for i, p := range plugins {
+		if p == nil {
+			return nil, fmt.Errorf("plugins[%d] is nil", i)
+		}
+		if names[p.Name()] {
+			return nil, fmt.Errorf("duplicate plugin: %q", p.Name())
+		}
+		names[p.Name()] = true
+	}
+	return &Manager{plugins: plugins}, nil
+}
+
+// ToContext returns a copy of ctx holding the manager.
+func ToContext(ctx context.Context, m *Manager) context.Context {
+	if m == nil {
+		return ctx
+	}
+	return pluginctx.ToContext(ctx, m)
+}
+
+// FromContext returns the manager held by ctx, or nil.
+func FromContext(ctx context.Context) *Manager {
+	m, _ := pluginctx.FromContext(ctx).(*Manager)
+	return m
+}
+
+// OnUserMessage runs the OnUserMessageCallbacks.
+func (m *Manager) OnUserMessage(ctx agent.InvocationContext, msg *genai.Content) (*genai.Content, error) {
+	for _, p := range m.all() {
+		if cb := p.OnUserMessageCallback(); cb != nil {
+			if content, err := cb(ctx, msg); content != nil || err != nil {
+				return content, wrap(p, err)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// BeforeRun runs the BeforeRunCallbacks.
+func (m *Manager) BeforeRun(ctx agent.InvocationContext) (*genai.Content, error) {
+	for _, p := range m.all() {
+		if cb := p.BeforeRunCallback(); cb != nil {
+			if content, err := cb(ctx); content != nil || err != nil {
+				return content, wrap(p, err)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// AfterRun runs all the AfterRunCallbacks.
+func (m *Manager) AfterRun(ctx agent.InvocationContext) {
+	for _, p := range m.all() {
+		if cb := p.AfterRunCallback(); cb != nil {
+			cb(ctx)
+		}
+	}
+}
+
+// OnEvent runs the OnEventCallbacks.
+func (m *Manager) OnEvent(ctx agent.InvocationContext, event *session.Event) (*session.Event, error) {
+	for _, p := range m.all() {
+		if cb := p.OnEventCallback(); cb != nil {
+			if ev, err := cb(ctx, event); ev != nil || err != nil {
+				return ev, wrap(p, err)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// BeforeAgentCallbacks returns the BeforeAgentCallbacks of the plugins, run
+// by the agents before their own ones.
+func (m *Manager) BeforeAgentCallbacks() []agent.BeforeAgentCallback {
+	var callbacks []agent.BeforeAgentCallback
+	for _, p := range m.all() {
+		if cb := p.BeforeAgentCallback(); cb != nil {
+			callbacks = append(callbacks, cb)
+		}
+	}
+	return callbacks
+}
+
+// AfterAgentCallbacks returns the AfterAgentCallbacks of the plugins, run
+// by the agents before their own ones.
+func (m *Manager) AfterAgentCallbacks() []agent.AfterAgentCallback {
+	var callbacks []agent.AfterAgentCallback
+	for _, p := range m.all() {
+		if cb := p.AfterAgentCallback(); cb != nil {
+			callbacks = append(callbacks, cb)
+		}
+	}
+	return callbacks
+}
+
+// BeforeModel runs the BeforeModelCallbacks.
+func (m *Manager) BeforeModel(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+	for _, p := range m.all() {
+		if cb := p.BeforeModelCallback(); cb != nil {
+			if resp, err := cb(ctx, req); resp != nil || err != nil {
+				return resp, wrap(p, err)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// AfterModel runs the AfterModelCallbacks.
+func (m *Manager) AfterModel(ctx agent.CallbackContext, resp *model.LLMResponse) (*model.LLMResponse, error) {
+	for _, p := range m.all() {
+		if cb := p.AfterModelCallback(); cb != nil {
+			if r, err := cb(ctx, resp); r != nil || err != nil {
+				return r, wrap(p, err)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// OnModelError runs the OnModelErrorCallbacks.
+func (m *Manager) OnModelError(ctx agent.CallbackContext, req *model.LLMRequest, modelErr error) (*model.LLMResponse, error) {
+	for _, p := range m.all() {
+		if cb := p.OnModelErrorCallback(); cb != nil {
+			if resp, err := cb(ctx, req, modelErr); resp != nil || err != nil {
+				return resp, wrap(p, err)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// BeforeTool runs the BeforeToolCallbacks.
+func (m *Manager) BeforeTool(ctx tool.Context, t tool.Tool, args map[string]any) (map[string]any, error) {
+	for _, p := range m.all() {
+		if cb := p.BeforeToolCallback(); cb != nil {
+			if result, err := cb(ctx, t, args); result != nil || err != nil {
+				return result, wrap(p, err)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// AfterTool runs the AfterToolCallbacks.
+func (m *Manager) AfterTool(ctx tool.Context, t tool.Tool, args, result map[string]any) (map[string]any, error) {
+	for _, p := range m.all() {
+		if cb := p.AfterToolCallback(); cb != nil {
+			if r, err := cb(ctx, t, args, result); r != nil || err != nil {
+				return r, wrap(p, err)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// OnToolError runs the OnToolErrorCallbacks.
+func (m *Manager) OnToolError(ctx tool.Context, t tool.Tool, args map[string]any, toolErr error) (map[string]any, error) {
+	for _, p := range m.all() {
+		if cb := p.OnToolErrorCallback(); cb != nil {
+			if result, err := cb(ctx, t, args, toolErr); result != nil || err != nil {
+				return result, wrap(p, err)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (m *Manager) all() []*plugin.Plugin {
+	if m == nil {
+		return nil
+	}
+	return m.plugins
+}
+
+func wrap(p *plugin.Plugin, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("plugin %q: %w", p.Name(), err)
+}
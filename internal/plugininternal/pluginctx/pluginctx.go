@@ -0,0 +1,31 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pluginctx holds the plugins of the runner in the context, for the
+// packages which cannot depend on the plugin package, e.g. agent.
+package pluginctx
+
+import "context"
+
+type pluginsKey struct{}
+
+// ToContext returns a copy of ctx holding the plugins.
+func ToContext(ctx context.Context, plugins any) context.Context {
+	return context.WithValue(ctx, pluginsKey{}, plugins)
+}
+
+// FromContext returns the plugins held by ctx, or nil.
+func FromContext(ctx context.Context) any {
+	return ctx.Value(pluginsKey{})
+}
@@ -49,7 +49,7 @@ func TestCopyrightHeader(t *testing.T) {
 		"internal/jsonschema": true,
 		"internal/util":       true,
 		// The following was copied from golang.org/x/oscar.
-		"internal/httprr": true,
+		"adktest/httprr": true,
 	}
 	_ = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -15,13 +15,10 @@
 package testutil
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 
-	"google.golang.org/adk/internal/httprr"
+	"google.golang.org/adk/adktest/httprr"
 )
 
 // NewGeminiTransport returns the genai.ClientConfig configured for record and replay.
@@ -30,29 +27,9 @@ func NewGeminiTransport(rrfile string) (http.RoundTripper, error) {
 	if err != nil {
 		return nil, fmt.Errorf("httprr.Open(%q) failed: %w", rrfile, err)
 	}
-	rr.ScrubReq(scrubGeminiRequest)
+	rr.ScrubReq(
+		httprr.ScrubHeaders("X-Goog-Api-Key", "X-Goog-Api-Client", "User-Agent"),
+		httprr.CanonicalizeJSON,
+	)
 	return rr, nil
 }
-
-func scrubGeminiRequest(req *http.Request) error {
-	delete(req.Header, "x-goog-api-key")    // genai does not canonicalize
-	req.Header.Del("X-Goog-Api-Key")        // in case it starts
-	delete(req.Header, "x-goog-api-client") // contains version numbers
-	req.Header.Del("X-Goog-Api-Client")
-	delete(req.Header, "user-agent") // contains google-genai-sdk and gl-go version numbers
-	req.Header.Del("User-Agent")
-
-	if ctype := req.Header.Get("Content-Type"); ctype == "application/json" || strings.HasPrefix(ctype, "application/json;") {
-		// Canonicalize JSON body.
-		// google.golang.org/protobuf/internal/encoding.json
-		// goes out of its way to randomize the JSON encodings
-		// of protobuf messages by adding or not adding spaces
-		// after commas. Derandomize by compacting the JSON.
-		b := req.Body.(*httprr.Body)
-		var buf bytes.Buffer
-		if err := json.Compact(&buf, b.Data); err == nil {
-			b.Data = buf.Bytes()
-		}
-	}
-	return nil
-}
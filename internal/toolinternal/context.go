@@ -16,10 +16,13 @@ package toolinternal
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/uuid"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/auth"
+	authinternal "google.golang.org/adk/internal/auth"
 	contextinternal "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/session"
@@ -98,3 +101,58 @@ func (c *toolContext) AgentName() string {
 func (c *toolContext) SearchMemory(ctx context.Context, query string) (*memory.SearchResponse, error) {
 	return c.invocationContext.Memory().Search(ctx, query)
 }
+
+func (c *toolContext) Credential(cfg *auth.Config) (*auth.Credential, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("auth config is required")
+	}
+	if err := cfg.Scheme.Validate(); err != nil {
+		return nil, err
+	}
+	key := authinternal.StateKey(cfg)
+	if v, err := c.State().Get(key); err == nil && v != nil {
+		return authinternal.CredentialFromState(v)
+	}
+	exchangeCfg := cfg
+	if v, err := c.State().Get(authinternal.ResponseStateKey(cfg)); err == nil && v != nil {
+		// The client responded to the credential request: it is exchanged
+		// with the secrets of the tool's config, which are never sent to
+		// the client.
+		resp, err := authinternal.CredentialFromState(v)
+		if err != nil {
+			return nil, err
+		}
+		withResp := *cfg
+		withResp.ExchangedCredential = resp
+		exchangeCfg = &withResp
+	} else if authinternal.NeedsUserInteraction(cfg) {
+		return nil, nil
+	} else {
+		switch cfg.Scheme.Type {
+		case auth.SchemeTypeAPIKey, auth.SchemeTypeHTTPBearer:
+			return cfg.RawCredential, nil
+		}
+	}
+	cred, err := authinternal.Exchange(c, exchangeCfg)
+	if err != nil {
+		return nil, err
+	}
+	// The credential is set in the state delta of the event, so that it is
+	// persisted.
+	if err := c.State().Set(key, cred); err != nil {
+		return nil, fmt.Errorf("failed to store credential: %w", err)
+	}
+	return cred, nil
+}
+
+func (c *toolContext) RequestCredential(cfg *auth.Config) error {
+	req, err := authinternal.NewRequest(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to request credential: %w", err)
+	}
+	if c.eventActions.RequestedAuthConfigs == nil {
+		c.eventActions.RequestedAuthConfigs = make(map[string]*auth.Config)
+	}
+	c.eventActions.RequestedAuthConfigs[c.functionCallID] = req
+	return nil
+}
@@ -0,0 +1,270 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache implements a [model.LLM] caching the responses of the model
+// it wraps, e.g. to replay the identical requests of eval suites and
+// development loops without calling the model again.
+//
+// The responses are cached by a hash of the request: the model name, the
+// contents, the generation config including the tool declarations, and
+// whether the responses are streamed. A cached streaming sequence, partial
+// responses included, is replayed in order.
+//
+// Only the complete, successful sequences are cached: the calls failing, the
+// responses with an error code, and the sequences the consumer stopped are
+// not.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"log"
+	"maps"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// HeaderCacheControl is the header of the request HTTP options controlling
+// the cache for a call, removed before calling the model:
+//   - "no-cache" calls the model without looking up the cache, and caches
+//     its responses,
+//   - "no-store" does not cache the responses,
+//   - both are combined with a comma, e.g. "no-cache, no-store".
+const HeaderCacheControl = "Cache-Control"
+
+// MetadataKey is the key set to true in the custom metadata of the
+// responses replayed from the cache.
+const MetadataKey = "adk_cache_hit"
+
+// Store stores the cached responses. Its implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the entry stored under the key, or nil if there is none.
+	Get(ctx context.Context, key string) (*Entry, error)
+	// Set stores the entry under the key, replacing any previous one.
+	Set(ctx context.Context, key string, entry *Entry) error
+}
+
+// Entry is a cached sequence of responses.
+type Entry struct {
+	Responses []*model.LLMResponse `json:"responses"`
+	// ExpiresAt is the time after which the entry is ignored. Zero if it
+	// does not expire.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+func (e *Entry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// Config is used to create a caching model.
+type Config struct {
+	// Model is the model whose responses are cached. Required.
+	Model model.LLM
+	// Store stores the responses. Required, e.g. [NewMemoryStore].
+	Store Store
+	// TTL is the duration for which the responses are cached. Zero caches
+	// them without expiration.
+	TTL time.Duration
+}
+
+// New returns a model replaying the cached responses of cfg.Model.
+//
+// Its name is the name of the wrapped model. Failing to read from or write
+// to the store does not fail the call: the model is called and the failure
+// logged.
+func New(cfg Config) (model.LLM, error) {
+	if cfg.Model == nil {
+		return nil, fmt.Errorf("model is required")
+	}
+	if cfg.Store == nil {
+		return nil, fmt.Errorf("cache store is required")
+	}
+	if cfg.TTL < 0 {
+		return nil, fmt.Errorf("cache TTL must not be negative")
+	}
+	return &cachingModel{llm: cfg.Model, store: cfg.Store, ttl: cfg.TTL}, nil
+}
+
+type cachingModel struct {
+	llm   model.LLM
+	store Store
+	ttl   time.Duration
+}
+
+func (m *cachingModel) Name() string {
+	return m.llm.Name()
+}
+
+// GenerateContent replays the cached responses of the request, or calls the
+// model and caches its responses.
+func (m *cachingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		req, noCache, noStore := cacheControl(req)
+		keyReq := req
+		if req != nil && req.Model == "" {
+			r := *req
+			r.Model = m.llm.Name()
+			keyReq = &r
+		}
+		key, err := Key(keyReq, stream)
+		if err != nil {
+			log.Printf("Failed to compute the cache key of the request to %s: %v", m.llm.Name(), err)
+			noCache, noStore = true, true
+		}
+
+		if !noCache {
+			entry, err := m.store.Get(ctx, key)
+			if err != nil {
+				log.Printf("Failed to read the cached responses of %s: %v", m.llm.Name(), err)
+			}
+			if entry != nil && !entry.expired(time.Now()) {
+				for _, resp := range entry.Responses {
+					// A stored entry may hold null responses.
+					if resp == nil {
+						continue
+					}
+					if !yield(replayed(resp), nil) {
+						return
+					}
+				}
+				return
+			}
+		}
+
+		var responses []*model.LLMResponse
+		cacheable := !noStore
+		for resp, err := range m.llm.GenerateContent(ctx, req, stream) {
+			if err != nil || resp == nil || resp.ErrorCode != "" {
+				cacheable = false
+			} else if cacheable {
+				// The consumer may modify the response, e.g. to set the IDs
+				// of the function calls.
+				c, err := clone(resp)
+				if err != nil {
+					log.Printf("Failed to copy the response of %s, not caching it: %v", m.llm.Name(), err)
+					cacheable = false
+				} else {
+					responses = append(responses, c)
+				}
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+		if !cacheable || len(responses) == 0 {
+			return
+		}
+		entry := &Entry{Responses: responses}
+		if m.ttl > 0 {
+			entry.ExpiresAt = time.Now().Add(m.ttl)
+		}
+		if err := m.store.Set(ctx, key, entry); err != nil {
+			log.Printf("Failed to cache the responses of %s: %v", m.llm.Name(), err)
+		}
+	}
+}
+
+// replayed returns a copy of the cached response marked as replayed.
+func replayed(resp *model.LLMResponse) *model.LLMResponse {
+	r := *resp
+	r.CustomMetadata = make(map[string]any, len(resp.CustomMetadata)+1)
+	maps.Copy(r.CustomMetadata, resp.CustomMetadata)
+	r.CustomMetadata[MetadataKey] = true
+	return &r
+}
+
+func clone(resp *model.LLMResponse) (*model.LLMResponse, error) {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	var c model.LLMResponse
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// cacheControl returns the request without the HeaderCacheControl header,
+// and the directives of the header.
+func cacheControl(req *model.LLMRequest) (_ *model.LLMRequest, noCache, noStore bool) {
+	if req == nil || req.Config == nil || req.Config.HTTPOptions == nil {
+		return req, false, false
+	}
+	values := req.Config.HTTPOptions.Headers.Values(HeaderCacheControl)
+	if len(values) == 0 {
+		return req, false, false
+	}
+	for _, v := range values {
+		for directive := range strings.SplitSeq(v, ",") {
+			switch strings.ToLower(strings.TrimSpace(directive)) {
+			case "no-cache":
+				noCache = true
+			case "no-store":
+				noStore = true
+			}
+		}
+	}
+
+	r := *req
+	cfg := *req.Config
+	opts := *req.Config.HTTPOptions
+	opts.Headers = req.Config.HTTPOptions.Headers.Clone()
+	opts.Headers.Del(HeaderCacheControl)
+	cfg.HTTPOptions = &opts
+	r.Config = &cfg
+	return &r, noCache, noStore
+}
+
+// Key returns the cache key of the request: the hex SHA-256 hash of the
+// JSON encoding of its model name, contents and generation config, the HTTP
+// options excepted but for their extra body, and of the streaming mode.
+func Key(req *model.LLMRequest, stream bool) (string, error) {
+	if req == nil {
+		return "", fmt.Errorf("request is nil")
+	}
+	var (
+		cfg       *genai.GenerateContentConfig
+		extraBody map[string]any
+	)
+	if req.Config != nil {
+		c := *req.Config
+		if c.HTTPOptions != nil {
+			extraBody = c.HTTPOptions.ExtraBody
+		}
+		c.HTTPOptions = nil
+		cfg = &c
+	}
+	// encoding/json sorts the keys of the maps: the encoding is canonical.
+	b, err := json.Marshal(struct {
+		Model     string                       `json:"model"`
+		Contents  []*genai.Content             `json:"contents"`
+		Config    *genai.GenerateContentConfig `json:"config"`
+		ExtraBody map[string]any               `json:"extra_body,omitempty"`
+		Stream    bool                         `json:"stream"`
+	}{req.Model, req.Contents, cfg, extraBody, stream})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the request: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/cache"
+	"google.golang.org/genai"
+)
+
+// countingModel streams the text of its calls, word by word, followed by
+// err.
+type countingModel struct {
+	calls int
+	err   error
+}
+
+func (m *countingModel) Name() string { return "counting" }
+
+func (m *countingModel) GenerateContent(_ context.Context, _ *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.calls++
+		text := fmt.Sprintf("call %d", m.calls)
+		if stream {
+			for _, word := range []string{"call ", fmt.Sprint(m.calls)} {
+				if !yield(&model.LLMResponse{Content: genai.NewContentFromText(word, genai.RoleModel), Partial: true}, nil) {
+					return
+				}
+			}
+		}
+		if m.err != nil {
+			yield(nil, m.err)
+			return
+		}
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleModel), TurnComplete: true}, nil)
+	}
+}
+
+func generate(t *testing.T, llm model.LLM, req *model.LLMRequest, stream bool) ([]string, bool) {
+	t.Helper()
+	var (
+		texts []string
+		hit   bool
+	)
+	for resp, err := range llm.GenerateContent(t.Context(), req, stream) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		texts = append(texts, resp.Content.Parts[0].Text)
+		hit, _ = resp.CustomMetadata[cache.MetadataKey].(bool)
+	}
+	return texts, hit
+}
+
+func request(text string) *model.LLMRequest {
+	return &model.LLMRequest{
+		Contents: []*genai.Content{genai.NewContentFromText(text, genai.RoleUser)},
+		Config:   &genai.GenerateContentConfig{},
+	}
+}
+
+func TestCachingModel(t *testing.T) {
+	inner := &countingModel{}
+	llm, err := cache.New(cache.Config{Model: inner, Store: cache.NewMemoryStore(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name     string
+		req      *model.LLMRequest
+		stream   bool
+		want     []string
+		wantHit  bool
+		wantCall int
+	}{
+		{name: "miss", req: request("hi"), want: []string{"call 1"}, wantCall: 1},
+		{name: "hit", req: request("hi"), want: []string{"call 1"}, wantHit: true, wantCall: 1},
+		{name: "other contents", req: request("bye"), want: []string{"call 2"}, wantCall: 2},
+		{name: "streaming is cached separately", req: request("hi"), stream: true, want: []string{"call ", "3", "call 3"}, wantCall: 3},
+		{name: "streaming replay", req: request("hi"), stream: true, want: []string{"call ", "3", "call 3"}, wantHit: true, wantCall: 3},
+		{
+			name: "bypass",
+			req: func() *model.LLMRequest {
+				r := request("hi")
+				r.Config.HTTPOptions = &genai.HTTPOptions{Headers: http.Header{"Cache-Control": {"no-cache"}}}
+				return r
+			}(),
+			want:     []string{"call 4"},
+			wantCall: 4,
+		},
+		{name: "bypass stored the responses", req: request("hi"), want: []string{"call 4"}, wantHit: true, wantCall: 4},
+		{
+			name: "no store",
+			req: func() *model.LLMRequest {
+				r := request("no store")
+				r.Config.HTTPOptions = &genai.HTTPOptions{Headers: http.Header{"Cache-Control": {"no-store"}}}
+				return r
+			}(),
+			want:     []string{"call 5"},
+			wantCall: 5,
+		},
+		{name: "responses not stored", req: request("no store"), want: []string{"call 6"}, wantCall: 6},
+	}
+	for _, step := range steps {
+		got, hit := generate(t, llm, step.req, step.stream)
+		if diff := cmp.Diff(step.want, got); diff != "" {
+			t.Errorf("%s: GenerateContent() mismatch (-want +got):\n%s", step.name, diff)
+		}
+		if hit != step.wantHit {
+			t.Errorf("%s: cache hit = %v, want %v", step.name, hit, step.wantHit)
+		}
+		if inner.calls != step.wantCall {
+			t.Errorf("%s: model calls = %d, want %d", step.name, inner.calls, step.wantCall)
+		}
+	}
+}
+
+func TestCachingModel_Errors(t *testing.T) {
+	inner := &countingModel{err: errors.New("unavailable")}
+	llm, err := cache.New(cache.Config{Model: inner, Store: cache.NewMemoryStore(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		var gotErr error
+		for _, err := range llm.GenerateContent(t.Context(), request("hi"), true) {
+			gotErr = err
+		}
+		if gotErr == nil {
+			t.Fatal("GenerateContent() error = nil, want the model error")
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("model calls = %d, want 2: failed calls must not be cached", inner.calls)
+	}
+}
+
+func TestCachingModel_TTL(t *testing.T) {
+	inner := &countingModel{}
+	store := cache.NewMemoryStore(0)
+	llm, err := cache.New(cache.Config{Model: inner, Store: store, TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	generate(t, llm, request("hi"), false)
+
+	key, err := cache.Key(&model.LLMRequest{Model: "counting", Contents: request("hi").Contents, Config: &genai.GenerateContentConfig{}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := store.Get(t.Context(), key)
+	if err != nil || entry == nil {
+		t.Fatalf("store.Get() = (%v, %v), want the cached entry", entry, err)
+	}
+	if d := time.Until(entry.ExpiresAt); d <= 0 || d > time.Hour {
+		t.Errorf("entry expires in %v, want within an hour", d)
+	}
+
+	// Once expired, the model is called again.
+	entry.ExpiresAt = time.Now().Add(-time.Second)
+	if err := store.Set(t.Context(), key, entry); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := generate(t, llm, request("hi"), false); !cmp.Equal(got, []string{"call 2"}) {
+		t.Errorf("GenerateContent() after expiration = %v, want [call 2]", got)
+	}
+}
+
+func TestCachingModel_NilResponse(t *testing.T) {
+	inner := &countingModel{}
+	store := cache.NewMemoryStore(0)
+	llm, err := cache.New(cache.Config{Model: inner, Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := cache.Key(&model.LLMRequest{Model: "counting", Contents: request("hi").Contents, Config: &genai.GenerateContentConfig{}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &cache.Entry{Responses: []*model.LLMResponse{nil, {Content: genai.NewContentFromText("cached", genai.RoleModel), TurnComplete: true}}}
+	if err := store.Set(t.Context(), key, entry); err != nil {
+		t.Fatal(err)
+	}
+	if got, hit := generate(t, llm, request("hi"), false); !hit || !cmp.Equal(got, []string{"cached"}) {
+		t.Errorf("GenerateContent() = (%v, hit %v), want ([cached], hit true)", got, hit)
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := func() *model.LLMRequest {
+		return &model.LLMRequest{
+			Model:    "m",
+			Contents: []*genai.Content{genai.NewContentFromText("hi", genai.RoleUser)},
+			Config: &genai.GenerateContentConfig{Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{
+				{Name: "f", Parameters: &genai.Schema{Type: genai.TypeObject, Properties: map[string]*genai.Schema{
+					"a": {Type: genai.TypeString}, "b": {Type: genai.TypeString},
+				}}},
+			}}}},
+		}
+	}
+	key := func(req *model.LLMRequest, stream bool) string {
+		k, err := cache.Key(req, stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	want := key(base(), false)
+	if got := key(base(), false); got != want {
+		t.Errorf("Key() of identical requests = %q, want %q", got, want)
+	}
+
+	withHeaders := base()
+	withHeaders.Config.HTTPOptions = &genai.HTTPOptions{Headers: http.Header{"X-Trace": {"1"}}}
+	if got := key(withHeaders, false); got != want {
+		t.Errorf("Key() with HTTP headers = %q, want %q", got, want)
+	}
+
+	otherTool := base()
+	otherTool.Config.Tools[0].FunctionDeclarations[0].Name = "g"
+	for name, k := range map[string]string{
+		"streaming":  key(base(), true),
+		"other tool": key(otherTool, false),
+		"other model": key(func() *model.LLMRequest {
+			r := base()
+			r.Model = "other"
+			return r
+		}(), false),
+	} {
+		if k == want {
+			t.Errorf("Key() with %s = Key() of the base request", name)
+		}
+	}
+}
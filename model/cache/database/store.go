@@ -0,0 +1,114 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package database provides a [cache.Store] keeping the cached model
+// responses in a relational database, via the GORM library used by the
+// database session service.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/cache"
+	"gorm.io/gorm"
+)
+
+// storageEntry corresponds to the 'model_cache' table.
+type storageEntry struct {
+	// Key is stored as cache_key, key being a reserved word in MySQL.
+	Key string `gorm:"column:cache_key;primaryKey;"`
+	// Responses is the JSON encoding of the cached responses.
+	Responses  string
+	ExpiresAt  *time.Time
+	CreateTime time.Time
+}
+
+// TableName explicitly sets the table name for the storageEntry struct.
+func (storageEntry) TableName() string {
+	return "model_cache"
+}
+
+// NewStore returns a [cache.Store] backed by a relational database (e.g.,
+// PostgreSQL, Spanner, SQLite), possibly the one of the database session
+// service.
+//
+// It requires a [gorm.Dialector] to specify the database connection and
+// accepts optional [gorm.Option] values for further GORM configuration. The
+// 'model_cache' table is created or migrated if needed.
+func NewStore(dialector gorm.Dialector, opts ...gorm.Option) (cache.Store, error) {
+	db, err := gorm.Open(dialector, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating database cache store: %w", err)
+	}
+	if err := db.AutoMigrate(&storageEntry{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate cache table: %w", err)
+	}
+	return &databaseStore{db: db}, nil
+}
+
+type databaseStore struct {
+	db *gorm.DB
+}
+
+// Get implements cache.Store. Expired entries are deleted when read.
+func (s *databaseStore) Get(ctx context.Context, key string) (*cache.Entry, error) {
+	var stored storageEntry
+	err := s.db.WithContext(ctx).First(&stored, "cache_key = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	if stored.ExpiresAt != nil && !time.Now().Before(*stored.ExpiresAt) {
+		if err := s.db.WithContext(ctx).Delete(&storageEntry{Key: key}).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete expired cache entry: %w", err)
+		}
+		return nil, nil
+	}
+
+	entry := &cache.Entry{}
+	if err := json.Unmarshal([]byte(stored.Responses), &entry.Responses); err != nil {
+		return nil, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	if stored.ExpiresAt != nil {
+		entry.ExpiresAt = *stored.ExpiresAt
+	}
+	return entry, nil
+}
+
+// Set implements cache.Store.
+func (s *databaseStore) Set(ctx context.Context, key string, entry *cache.Entry) error {
+	responses := entry.Responses
+	if responses == nil {
+		responses = []*model.LLMResponse{}
+	}
+	b, err := json.Marshal(responses)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	stored := &storageEntry{Key: key, Responses: string(b), CreateTime: time.Now()}
+	if !entry.ExpiresAt.IsZero() {
+		stored.ExpiresAt = &entry.ExpiresAt
+	}
+	if err := s.db.WithContext(ctx).Save(stored).Error; err != nil {
+		return fmt.Errorf("failed to save cache entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/cache"
+	"google.golang.org/genai"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDatabaseStore(t *testing.T) {
+	store, err := NewStore(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	entry := &cache.Entry{
+		Responses: []*model.LLMResponse{
+			{Content: genai.NewContentFromText("hel", genai.RoleModel), Partial: true},
+			{Content: genai.NewContentFromText("hello", genai.RoleModel), TurnComplete: true},
+		},
+		ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+
+	got, err := store.Get(t.Context(), "k")
+	if err != nil || got != nil {
+		t.Errorf("Get() of a missing key = (%v, %v), want (nil, nil)", got, err)
+	}
+	if err := store.Set(t.Context(), "k", entry); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err = store.Get(t.Context(), "k")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if diff := cmp.Diff(entry, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+		t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Set replaces the entry.
+	expired := &cache.Entry{Responses: entry.Responses, ExpiresAt: time.Now().Add(-time.Second)}
+	if err := store.Set(t.Context(), "k", expired); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := store.Get(t.Context(), "k"); err != nil || got != nil {
+		t.Errorf("Get() of an expired entry = (%v, %v), want (nil, nil)", got, err)
+	}
+	var count int64
+	if err := store.(*databaseStore).db.Model(&storageEntry{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("entries after reading an expired one = %d, want 0", count)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/internal/fileutil"
+)
+
+// NewMemoryStore returns a [Store] keeping up to capacity entries in memory,
+// evicting the least recently used ones. Zero or a negative capacity does
+// not limit the number of entries.
+func NewMemoryStore(capacity int) Store {
+	return &memoryStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+type memoryStore struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru holds the *memoryEntry, the most recently used first.
+	lru *list.List
+}
+
+type memoryEntry struct {
+	key       string
+	expiresAt time.Time
+	// data is the JSON encoding of the entry, so that the entries returned
+	// can be modified.
+	data []byte
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	elem, ok := s.entries[key]
+	if !ok {
+		s.mu.Unlock()
+		return nil, nil
+	}
+	e := elem.Value.(*memoryEntry)
+	if !e.expiresAt.IsZero() && !time.Now().Before(e.expiresAt) {
+		s.lru.Remove(elem)
+		delete(s.entries, key)
+		s.mu.Unlock()
+		return nil, nil
+	}
+	s.lru.MoveToFront(elem)
+	s.mu.Unlock()
+	return decodeEntry(e.data)
+}
+
+func (s *memoryStore) Set(_ context.Context, key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	e := &memoryEntry{key: key, expiresAt: entry.ExpiresAt, data: data}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		elem.Value = e
+		s.lru.MoveToFront(elem)
+		return nil
+	}
+	s.entries[key] = s.lru.PushFront(e)
+	if s.capacity > 0 && s.lru.Len() > s.capacity {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// NewFileStore returns a [Store] keeping each entry in a JSON file of the
+// directory, created if needed, named after the SHA-256 hash of its key. The
+// expired entries are removed when read.
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &fileStore{dir: dir}, nil
+}
+
+type fileStore struct {
+	dir string
+}
+
+// path returns the path of the file of the entry. The key is hashed, so that
+// any key maps to a file of the directory.
+func (s *fileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (s *fileStore) Get(_ context.Context, key string) (*Entry, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	entry, err := decodeEntry(data)
+	if err != nil {
+		return nil, err
+	}
+	if entry.expired(time.Now()) {
+		if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove expired cache entry: %w", err)
+		}
+		return nil, nil
+	}
+	return entry, nil
+}
+
+func (s *fileStore) Set(_ context.Context, key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	if err := fileutil.WriteAtomic(s.path(key), data); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+func decodeEntry(data []byte) (*Entry, error) {
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/cache"
+	"google.golang.org/genai"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) cache.Store{
+		"memory": func(*testing.T) cache.Store { return cache.NewMemoryStore(0) },
+		"file": func(t *testing.T) cache.Store {
+			s, err := cache.NewFileStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			entry := &cache.Entry{
+				Responses: []*model.LLMResponse{
+					{Content: genai.NewContentFromText("hel", genai.RoleModel), Partial: true},
+					{Content: genai.NewContentFromFunctionCall("f", map[string]any{"a": "b"}, genai.RoleModel), TurnComplete: true},
+				},
+				ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+			}
+
+			got, err := store.Get(t.Context(), "k")
+			if err != nil || got != nil {
+				t.Errorf("Get() of a missing key = (%v, %v), want (nil, nil)", got, err)
+			}
+			if err := store.Set(t.Context(), "k", entry); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			got, err = store.Get(t.Context(), "k")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if diff := cmp.Diff(entry, got); diff != "" {
+				t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+			}
+
+			// The returned entries are copies.
+			got.Responses[0].Content.Parts[0].Text = "modified"
+			if again, _ := store.Get(t.Context(), "k"); again.Responses[0].Content.Parts[0].Text != "hel" {
+				t.Errorf("Get() returned the stored entry, modified to %q", again.Responses[0].Content.Parts[0].Text)
+			}
+
+			expired := &cache.Entry{Responses: entry.Responses, ExpiresAt: time.Now().Add(-time.Second)}
+			if err := store.Set(t.Context(), "k", expired); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if got, err := store.Get(t.Context(), "k"); err != nil || got != nil {
+				t.Errorf("Get() of an expired entry = (%v, %v), want (nil, nil)", got, err)
+			}
+		})
+	}
+}
+
+func TestFileStore_Keys(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "cache")
+	store, err := cache.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &cache.Entry{Responses: []*model.LLMResponse{{Content: genai.NewContentFromText("a", genai.RoleModel)}}}
+	keys := []string{"../escaped", "a/b", "", "k"}
+	for _, key := range keys {
+		if err := store.Set(t.Context(), key, entry); err != nil {
+			t.Fatalf("Set(%q) error = %v", key, err)
+		}
+	}
+	for _, key := range keys {
+		if got, err := store.Get(t.Context(), key); err != nil || got == nil {
+			t.Errorf("Get(%q) = (%v, %v), want the entry", key, got, err)
+		}
+	}
+
+	// Every entry is a file of the directory.
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(keys) {
+		t.Errorf("directory has %d files, want %d", len(files), len(keys))
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			t.Errorf("directory has subdirectory %q", f.Name())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "escaped.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("entry written outside of the directory: %v", err)
+	}
+}
+
+func TestMemoryStore_Eviction(t *testing.T) {
+	store := cache.NewMemoryStore(2)
+	entry := &cache.Entry{Responses: []*model.LLMResponse{{Content: genai.NewContentFromText("a", genai.RoleModel)}}}
+	for _, key := range []string{"a", "b"} {
+		if err := store.Set(t.Context(), key, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// "a" is used more recently than "b", evicted by "c".
+	if _, err := store.Get(t.Context(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(t.Context(), "c", entry); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		got, err := store.Get(t.Context(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (got != nil) != want {
+			t.Errorf("Get(%q) present = %v, want %v", key, got != nil, want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// ContextCacheMetadataKey is the key of the context cache report in the
+// custom metadata of the responses of a model with context caching. The
+// report is a map with:
+//   - "hit": whether the request reused an existing cached content,
+//   - "cache_name": the name of the cached content used, if any,
+//   - "cached_contents": the number of contents of the history it holds.
+const ContextCacheMetadataKey = "gemini_context_cache"
+
+// ContextCacheConfig configures the explicit context caching of a Gemini
+// model.
+//
+// The stable prefix of the requests, i.e. their system instruction, tools
+// and tool config, and the history preceding their last content, is stored
+// in a [genai.CachedContent] reused by the next requests with the same
+// prefix. A request whose system instruction, tools or history match no
+// cached prefix, e.g. of another session of the agent, gets a new cached
+// content, kept alongside the others. The requests with less than two
+// contents, having no history, are sent without cache.
+type ContextCacheConfig struct {
+	// TTL is the time to live of the cached contents. Defaults to 30
+	// minutes. A cached content is replaced once 90% of its TTL elapsed.
+	TTL time.Duration
+	// MinTokens is the estimated number of tokens below which the prefix is
+	// not cached. Gemini rejects the cached contents below a model-specific
+	// minimum, e.g. 1024 tokens for gemini-2.5-flash: the requests are then
+	// sent without cache. Zero sets no minimum.
+	MinTokens int
+	// MaxEntries is the number of cached contents kept at once, one per
+	// distinct system instruction, tools, tool config and history prefix,
+	// e.g. for the sessions of the agents sharing the model. The least
+	// recently used one is deleted once exceeded. Defaults to 16.
+	MaxEntries int
+}
+
+// NewModelWithContextCache returns a Gemini [model.LLM] like [NewModel],
+// caching the stable prefix of its requests as configured by cacheCfg.
+func NewModelWithContextCache(ctx context.Context, modelName string, cfg *genai.ClientConfig, cacheCfg ContextCacheConfig) (model.LLM, error) {
+	if cacheCfg.TTL < 0 || cacheCfg.MinTokens < 0 || cacheCfg.MaxEntries < 0 {
+		return nil, fmt.Errorf("invalid context cache config: %+v", cacheCfg)
+	}
+	if cacheCfg.TTL == 0 {
+		cacheCfg.TTL = 30 * time.Minute
+	}
+	if cacheCfg.MaxEntries == 0 {
+		cacheCfg.MaxEntries = 16
+	}
+	llm, err := NewModel(ctx, modelName, cfg)
+	if err != nil {
+		return nil, err
+	}
+	m := llm.(*geminiModel)
+	m.contextCache = &contextCache{cfg: cacheCfg, entries: make(map[string][]*cacheEntry)}
+	return m, nil
+}
+
+type contextCache struct {
+	cfg ContextCacheConfig
+
+	// creating makes the concurrent requests with the same prefix create a
+	// single cached content.
+	creating singleflight.Group
+
+	// mu guards entries. It is not held while calling the API.
+	mu sync.Mutex
+	// entries are the cached contents by fingerprint of the system
+	// instruction, tools and tool config, one per history prefix.
+	entries map[string][]*cacheEntry
+}
+
+type cacheEntry struct {
+	name string
+	// prefixLen is the number of contents of the history cached, and
+	// prefixHash their fingerprint.
+	prefixLen  int
+	prefixHash string
+	// refreshTime is the time after which the cached content is replaced.
+	refreshTime time.Time
+	lastUsed    time.Time
+}
+
+// apply returns the request to send, using a cached content for its prefix
+// if possible, and the report of the context cache to add to the responses,
+// nil if the request is not cacheable.
+func (c *contextCache) apply(ctx context.Context, m *geminiModel, req *model.LLMRequest) (*model.LLMRequest, map[string]any) {
+	cfg := req.Config
+	if cfg.CachedContent != "" || (cfg.SystemInstruction == nil && len(cfg.Tools) == 0) || len(req.Contents) < 2 {
+		return req, nil
+	}
+	key, err := fingerprint(cfg.SystemInstruction, cfg.Tools, cfg.ToolConfig)
+	if err != nil {
+		log.Printf("Failed to fingerprint the request to %s, not caching it: %v", m.name, err)
+		return req, nil
+	}
+
+	now := time.Now()
+	entry, expired := c.lookup(key, req.Contents, now)
+	c.deleteCachedContents(ctx, m, expired)
+	if entry != nil {
+		return cachedRequest(req, entry), cacheReport(true, entry)
+	}
+
+	prefixLen := len(req.Contents) - 1
+	prefixHash, err := fingerprint(req.Contents[:prefixLen])
+	if err != nil {
+		log.Printf("Failed to fingerprint the request to %s, not caching it: %v", m.name, err)
+		return req, nil
+	}
+	if c.cfg.MinTokens > 0 && estimateTokens(cfg, req.Contents[:prefixLen]) < c.cfg.MinTokens {
+		return req, cacheReport(false, nil)
+	}
+	v, err, _ := c.creating.Do(key+"/"+prefixHash, func() (any, error) {
+		// A concurrent request may have created it since the lookup.
+		if entry := c.find(key, prefixHash); entry != nil {
+			return entry, nil
+		}
+		headers := make(http.Header)
+		m.addHeaders(headers)
+		cached, err := m.client.Caches.Create(ctx, m.name, &genai.CreateCachedContentConfig{
+			HTTPOptions:       &genai.HTTPOptions{Headers: headers},
+			TTL:               c.cfg.TTL,
+			Contents:          req.Contents[:prefixLen],
+			SystemInstruction: cfg.SystemInstruction,
+			Tools:             cfg.Tools,
+			ToolConfig:        cfg.ToolConfig,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entry := &cacheEntry{
+			name:        cached.Name,
+			prefixLen:   prefixLen,
+			prefixHash:  prefixHash,
+			refreshTime: now.Add(c.cfg.TTL * 9 / 10),
+			lastUsed:    now,
+		}
+		c.mu.Lock()
+		c.entries[key] = append(c.entries[key], entry)
+		evicted := c.evict()
+		c.mu.Unlock()
+		c.deleteCachedContents(ctx, m, evicted)
+		return entry, nil
+	})
+	if err != nil {
+		log.Printf("Failed to create the cached content of %s, sending the request without cache: %v", m.name, err)
+		return req, cacheReport(false, nil)
+	}
+	entry = v.(*cacheEntry)
+	return cachedRequest(req, entry), cacheReport(false, entry)
+}
+
+// lookup returns the entry of the key caching the longest prefix of the
+// contents, if any, and removes the entries of the key about to expire,
+// returned to be deleted.
+func (c *contextCache) lookup(key string, contents []*genai.Content, now time.Time) (hit *cacheEntry, expired []*cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var kept []*cacheEntry
+	for _, entry := range c.entries[key] {
+		if !now.Before(entry.refreshTime) {
+			expired = append(expired, entry)
+			continue
+		}
+		kept = append(kept, entry)
+		if entry.prefixLen >= len(contents) || (hit != nil && hit.prefixLen >= entry.prefixLen) {
+			continue
+		}
+		if hash, err := fingerprint(contents[:entry.prefixLen]); err == nil && hash == entry.prefixHash {
+			hit = entry
+		}
+	}
+	if len(kept) == 0 {
+		delete(c.entries, key)
+	} else {
+		c.entries[key] = kept
+	}
+	if hit != nil {
+		hit.lastUsed = now
+	}
+	return hit, expired
+}
+
+// find returns the entry of the key caching the prefix with the hash, if
+// any.
+func (c *contextCache) find(key, prefixHash string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range c.entries[key] {
+		if entry.prefixHash == prefixHash {
+			return entry
+		}
+	}
+	return nil
+}
+
+// evict removes the least recently used entries in excess, returned to be
+// deleted. c.mu must be held.
+func (c *contextCache) evict() []*cacheEntry {
+	var evicted []*cacheEntry
+	for {
+		var n int
+		var oldestKey string
+		var oldest *cacheEntry
+		for key, entries := range c.entries {
+			n += len(entries)
+			for _, entry := range entries {
+				if oldest == nil || entry.lastUsed.Before(oldest.lastUsed) {
+					oldestKey, oldest = key, entry
+				}
+			}
+		}
+		if n <= c.cfg.MaxEntries {
+			return evicted
+		}
+		entries := slices.DeleteFunc(c.entries[oldestKey], func(entry *cacheEntry) bool { return entry == oldest })
+		if len(entries) == 0 {
+			delete(c.entries, oldestKey)
+		} else {
+			c.entries[oldestKey] = entries
+		}
+		evicted = append(evicted, oldest)
+	}
+}
+
+// deleteCachedContents deletes the cached contents of the entries.
+func (c *contextCache) deleteCachedContents(ctx context.Context, m *geminiModel, entries []*cacheEntry) {
+	for _, entry := range entries {
+		c.deleteCachedContent(ctx, m, entry)
+	}
+}
+
+// deleteCachedContent deletes the cached content of the entry. Failures are
+// logged: the cached content expires anyway.
+func (c *contextCache) deleteCachedContent(ctx context.Context, m *geminiModel, entry *cacheEntry) {
+	headers := make(http.Header)
+	m.addHeaders(headers)
+	if _, err := m.client.Caches.Delete(ctx, entry.name, &genai.DeleteCachedContentConfig{HTTPOptions: &genai.HTTPOptions{Headers: headers}}); err != nil {
+		log.Printf("Failed to delete the cached content %s: %v", entry.name, err)
+	}
+}
+
+// cachedRequest returns a copy of the request using the cached content of
+// the entry in place of its prefix.
+func cachedRequest(req *model.LLMRequest, entry *cacheEntry) *model.LLMRequest {
+	r := *req
+	cfg := *req.Config
+	cfg.CachedContent = entry.name
+	cfg.SystemInstruction = nil
+	cfg.Tools = nil
+	cfg.ToolConfig = nil
+	r.Config = &cfg
+	r.Contents = req.Contents[entry.prefixLen:]
+	return &r
+}
+
+func cacheReport(hit bool, entry *cacheEntry) map[string]any {
+	report := map[string]any{"hit": hit}
+	if entry != nil {
+		report["cache_name"] = entry.name
+		report["cached_contents"] = entry.prefixLen
+	}
+	return report
+}
+
+// fingerprint returns the hex SHA-256 hash of the JSON encoding of values.
+func fingerprint(values ...any) (string, error) {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// estimateTokens estimates the number of tokens of the cached prefix.
+func estimateTokens(cfg *genai.GenerateContentConfig, contents []*genai.Content) int {
+	n, _ := model.HeuristicTokenCounter{}.CountTokens(context.Background(), append([]*genai.Content{cfg.SystemInstruction}, contents...))
+	if len(cfg.Tools) > 0 {
+		if b, err := json.Marshal(cfg.Tools); err == nil {
+			n += len(b) / 4
+		}
+	}
+	return n
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestModel_ContextCache(t *testing.T) {
+	httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+	llm, err := NewModelWithContextCache(t.Context(), "gemini-2.0-flash", newGeminiTestClientConfig(t, httpRecordFilename), ContextCacheConfig{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tools := []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{
+		{Name: "get_capital", Description: "Returns the capital of a country."},
+	}}}
+	// request returns the request with the instruction, and the texts as
+	// alternating user and model contents.
+	request := func(instruction string, texts ...string) *model.LLMRequest {
+		var contents []*genai.Content
+		for i, text := range texts {
+			role := genai.Role(genai.RoleUser)
+			if i%2 == 1 {
+				role = genai.RoleModel
+			}
+			contents = append(contents, genai.NewContentFromText(text, role))
+		}
+		return &model.LLMRequest{
+			Contents: contents,
+			Config: &genai.GenerateContentConfig{
+				SystemInstruction: genai.NewContentFromText(instruction, genai.RoleUser),
+				Tools:             tools,
+			},
+		}
+	}
+
+	steps := []struct {
+		name       string
+		req        *model.LLMRequest
+		wantText   string
+		wantReport map[string]any
+	}{
+		{
+			name:     "does not cache a single content",
+			req:      request("Answer in one word.", "What is the capital of France?"),
+			wantText: "Paris",
+		},
+		{
+			name:       "creates the cached content",
+			req:        request("Answer in one word.", "What is the capital of France?", "Paris", "And of Germany?"),
+			wantText:   "Berlin",
+			wantReport: map[string]any{"hit": false, "cache_name": "cachedContents/first", "cached_contents": 2},
+		},
+		{
+			name:       "reuses the cached content",
+			req:        request("Answer in one word.", "What is the capital of France?", "Paris", "And of Germany?", "Berlin", "And of Italy?"),
+			wantText:   "Rome",
+			wantReport: map[string]any{"hit": true, "cache_name": "cachedContents/first", "cached_contents": 2},
+		},
+		{
+			name:       "caches the prefix of another instruction separately",
+			req:        request("Answer in one lowercase word.", "What is the capital of France?", "Paris", "And of Germany?", "Berlin", "And of Italy?"),
+			wantText:   "rome",
+			wantReport: map[string]any{"hit": false, "cache_name": "cachedContents/second", "cached_contents": 4},
+		},
+		{
+			name:       "caches another history of the instruction alongside",
+			req:        request("Answer in one lowercase word.", "What is the capital of Spain?", "madrid", "And of Portugal?"),
+			wantText:   "lisbon",
+			wantReport: map[string]any{"hit": false, "cache_name": "cachedContents/third", "cached_contents": 2},
+		},
+		{
+			name:       "still reuses the cached content of the first history",
+			req:        request("Answer in one lowercase word.", "What is the capital of France?", "Paris", "And of Germany?", "Berlin", "And of Italy?", "rome", "And of Spain?"),
+			wantText:   "madrid",
+			wantReport: map[string]any{"hit": true, "cache_name": "cachedContents/second", "cached_contents": 4},
+		},
+	}
+	for _, step := range steps {
+		var got []*model.LLMResponse
+		for resp, err := range llm.GenerateContent(t.Context(), step.req, false) {
+			if err != nil {
+				t.Fatalf("%s: GenerateContent() error = %v", step.name, err)
+			}
+			got = append(got, resp)
+		}
+		if len(got) != 1 {
+			t.Fatalf("%s: GenerateContent() returned %d responses, want 1", step.name, len(got))
+		}
+		if text := got[0].Content.Parts[0].Text; text != step.wantText {
+			t.Errorf("%s: GenerateContent() text = %q, want %q", step.name, text, step.wantText)
+		}
+		report, _ := got[0].CustomMetadata[ContextCacheMetadataKey].(map[string]any)
+		if diff := cmp.Diff(step.wantReport, report); diff != "" {
+			t.Errorf("%s: context cache report mismatch (-want +got):\n%s", step.name, diff)
+		}
+		if cached := got[0].UsageMetadata != nil && got[0].UsageMetadata.CachedContentTokenCount > 0; cached != (step.wantReport != nil) {
+			t.Errorf("%s: usage metadata = %+v, want cached content tokens: %v", step.name, got[0].UsageMetadata, step.wantReport != nil)
+		}
+	}
+}
+
+func TestContextCache_NotCached(t *testing.T) {
+	instruction := genai.NewContentFromText("Answer in one word.", genai.RoleUser)
+	history := []*genai.Content{
+		genai.NewContentFromText("Hi", genai.RoleUser),
+		genai.NewContentFromText("Hello!", genai.RoleModel),
+		genai.NewContentFromText("What is the capital of France?", genai.RoleUser),
+	}
+	tests := []struct {
+		name       string
+		cfg        *genai.GenerateContentConfig
+		contents   []*genai.Content
+		wantReport map[string]any
+	}{
+		{
+			name:     "no system instruction nor tools",
+			cfg:      &genai.GenerateContentConfig{},
+			contents: history,
+		},
+		{
+			name:     "explicit cached content",
+			cfg:      &genai.GenerateContentConfig{SystemInstruction: instruction, CachedContent: "cachedContents/explicit"},
+			contents: history,
+		},
+		{
+			name:     "single content",
+			cfg:      &genai.GenerateContentConfig{SystemInstruction: instruction},
+			contents: genai.Text("What is the capital of France?"),
+		},
+		{
+			name: "no contents",
+			cfg:  &genai.GenerateContentConfig{SystemInstruction: instruction},
+		},
+		{
+			name:       "prefix below the minimum",
+			cfg:        &genai.GenerateContentConfig{SystemInstruction: instruction},
+			contents:   history,
+			wantReport: map[string]any{"hit": false},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// The model has no client: no cached content is created.
+			m := &geminiModel{name: "gemini-2.0-flash"}
+			c := &contextCache{cfg: ContextCacheConfig{TTL: time.Hour, MinTokens: 1024, MaxEntries: 1}, entries: make(map[string][]*cacheEntry)}
+			req := &model.LLMRequest{Contents: tc.contents, Config: tc.cfg}
+
+			got, report := c.apply(t.Context(), m, req)
+			if got != req {
+				t.Errorf("apply() = %+v, want the request unchanged", got)
+			}
+			if diff := cmp.Diff(tc.wantReport, report); diff != "" {
+				t.Errorf("apply() report mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestContextCache_Concurrent(t *testing.T) {
+	var creates, deletes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/cachedContents"):
+			n := creates.Add(1)
+			// Let the concurrent requests with the same prefix arrive.
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprintf(w, `{"name":"cachedContents/%d"}`, n)
+		case r.Method == http.MethodDelete:
+			deletes.Add(1)
+			fmt.Fprint(w, `{}`)
+		default:
+			fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]},"finishReason":"STOP"}]}`)
+		}
+	}))
+	defer server.Close()
+	llm, err := NewModelWithContextCache(t.Context(), "gemini-2.0-flash", &genai.ClientConfig{
+		APIKey:      "fakekey",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: server.URL},
+	}, ContextCacheConfig{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(session string) *model.LLMRequest {
+		return &model.LLMRequest{
+			Contents: []*genai.Content{
+				genai.NewContentFromText("Hi, I am "+session, genai.RoleUser),
+				genai.NewContentFromText("Hello!", genai.RoleModel),
+				genai.NewContentFromText("What is my name?", genai.RoleUser),
+			},
+			Config: &genai.GenerateContentConfig{SystemInstruction: genai.NewContentFromText("Be brief.", genai.RoleUser)},
+		}
+	}
+
+	// The concurrent requests of the two sessions create one cached content
+	// per session, kept by the later requests.
+	var wg sync.WaitGroup
+	for range 2 {
+		for _, session := range []string{"alice", "bob"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for _, err := range llm.GenerateContent(t.Context(), request(session), false) {
+					if err != nil {
+						t.Errorf("GenerateContent() error = %v", err)
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	for _, session := range []string{"alice", "bob"} {
+		for resp, err := range llm.GenerateContent(t.Context(), request(session), false) {
+			if err != nil {
+				t.Fatalf("GenerateContent() error = %v", err)
+			}
+			if report, _ := resp.CustomMetadata[ContextCacheMetadataKey].(map[string]any); report["hit"] != true {
+				t.Errorf("session %s: context cache report = %v, want a hit", session, report)
+			}
+		}
+	}
+	if got := creates.Load(); got != 2 {
+		t.Errorf("cached contents created = %d, want 2", got)
+	}
+	if got := deletes.Load(); got != 0 {
+		t.Errorf("cached contents deleted = %d, want 0", got)
+	}
+}
@@ -35,6 +35,8 @@ type geminiModel struct {
 	client             *genai.Client
 	name               string
 	versionHeaderValue string
+	// contextCache caches the prefix of the requests, nil if disabled.
+	contextCache *contextCache
 }
 
 // NewModel returns [model.LLM], backed by the Gemini API.
@@ -79,6 +81,9 @@ func (m *geminiModel) GenerateContent(ctx context.Context, req *model.LLMRequest
 	}
 	m.addHeaders(req.Config.HTTPOptions.Headers)
 
+	if m.contextCache != nil {
+		return m.generateWithContextCache(ctx, req, stream)
+	}
 	if stream {
 		return m.generateStream(ctx, req)
 	}
@@ -89,6 +94,55 @@ func (m *geminiModel) GenerateContent(ctx context.Context, req *model.LLMRequest
 	}
 }
 
+// generateWithContextCache calls the model using a cached content for the
+// prefix of the request, reporting it in the custom metadata of the
+// responses.
+func (m *geminiModel) generateWithContextCache(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		req, report := m.contextCache.apply(ctx, m, req)
+		responses := m.generateStream(ctx, req)
+		if !stream {
+			responses = func(yield func(*model.LLMResponse, error) bool) {
+				yield(m.generate(ctx, req))
+			}
+		}
+		for resp, err := range responses {
+			if resp != nil && report != nil {
+				if resp.CustomMetadata == nil {
+					resp.CustomMetadata = make(map[string]any)
+				}
+				resp.CustomMetadata[ContextCacheMetadataKey] = report
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+// CountTokens implements [model.TokenCounter] with the CountTokens API.
+func (m *geminiModel) CountTokens(ctx context.Context, contents []*genai.Content) (int, error) {
+	cfg := &genai.CountTokensConfig{HTTPOptions: &genai.HTTPOptions{Headers: make(http.Header)}}
+	m.addHeaders(cfg.HTTPOptions.Headers)
+	resp, err := m.client.Models.CountTokens(ctx, m.name, contents, cfg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	return int(resp.TotalTokens), nil
+}
+
+// setServedModel records the version of the model that served the
+// response, if known.
+func setServedModel(resp *model.LLMResponse, modelVersion string) {
+	if resp == nil || modelVersion == "" {
+		return
+	}
+	if resp.CustomMetadata == nil {
+		resp.CustomMetadata = make(map[string]any)
+	}
+	resp.CustomMetadata[model.ServedModelMetadataKey] = modelVersion
+}
+
 // addHeaders sets the x-goog-api-client and user-agent headers
 func (m *geminiModel) addHeaders(headers http.Header) {
 	headers.Set("x-goog-api-client", m.versionHeaderValue)
@@ -105,7 +159,9 @@ func (m *geminiModel) generate(ctx context.Context, req *model.LLMRequest) (*mod
 		// shouldn't happen?
 		return nil, fmt.Errorf("empty response")
 	}
-	return converters.Genai2LLMResponse(resp), nil
+	llmResponse := converters.Genai2LLMResponse(resp)
+	setServedModel(llmResponse, resp.ModelVersion)
+	return llmResponse, nil
 }
 
 // generateStream returns a stream of responses from the model.
@@ -113,18 +169,24 @@ func (m *geminiModel) generateStream(ctx context.Context, req *model.LLMRequest)
 	aggregator := llminternal.NewStreamingResponseAggregator()
 
 	return func(yield func(*model.LLMResponse, error) bool) {
+		var modelVersion string
 		for resp, err := range m.client.Models.GenerateContentStream(ctx, m.name, req.Contents, req.Config) {
 			if err != nil {
 				yield(nil, err)
 				return
 			}
+			if resp.ModelVersion != "" {
+				modelVersion = resp.ModelVersion
+			}
 			for llmResponse, err := range aggregator.ProcessResponse(ctx, resp) {
+				setServedModel(llmResponse, modelVersion)
 				if !yield(llmResponse, err) {
 					return // Consumer stopped
 				}
 			}
 		}
 		if closeResult := aggregator.Close(); closeResult != nil {
+			setServedModel(closeResult, modelVersion)
 			yield(closeResult, nil)
 		}
 	}
@@ -24,7 +24,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
-	"google.golang.org/adk/internal/httprr"
+	"google.golang.org/adk/adktest/httprr"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/genai"
@@ -58,7 +58,8 @@ func TestModel_Generate(t *testing.T) {
 					PromptTokensDetails:     []*genai.ModalityTokenCount{{Modality: "TEXT", TokenCount: 10}},
 					TotalTokenCount:         12,
 				},
-				FinishReason: "STOP",
+				CustomMetadata: map[string]any{model.ServedModelMetadataKey: "gemini-2.0-flash"},
+				FinishReason:   "STOP",
 			},
 		},
 	}
@@ -127,10 +128,32 @@ func TestModel_GenerateStream(t *testing.T) {
 			if diff := cmp.Diff(tt.want, got.FinalText); diff != "" {
 				t.Errorf("Model.GenerateStream() = %v, want %v\ndiff(-want +got):\n%v", got.FinalText, tt.want, diff)
 			}
+			if got.ServedModel != tt.modelName {
+				t.Errorf("Model.GenerateStream() served model = %q, want %q", got.ServedModel, tt.modelName)
+			}
 		})
 	}
 }
 
+func TestModel_CountTokens(t *testing.T) {
+	httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
+	llm, err := NewModel(t.Context(), "gemini-2.0-flash", newGeminiTestClientConfig(t, httpRecordFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter, ok := llm.(model.TokenCounter)
+	if !ok {
+		t.Fatal("Gemini model does not implement model.TokenCounter")
+	}
+	got, err := counter.CountTokens(t.Context(), genai.Text("What is the capital of France? One word."))
+	if err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+	if got != 10 {
+		t.Errorf("CountTokens() = %d, want 10", got)
+	}
+}
+
 func TestModel_TrackingHeaders(t *testing.T) {
 	t.Run("verifies_headers_are_set", func(t *testing.T) {
 		httpRecordFilename := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".httprr")
@@ -211,6 +234,8 @@ type TextResponse struct {
 	PartialText string
 	// FinalText is the full text concatenated from all final (non-partial) responses.
 	FinalText string
+	// ServedModel is the model that served the final responses.
+	ServedModel string
 }
 
 // readResponse transforms a sequence into a TextResponse, concating the text value of the response parts
@@ -235,6 +260,7 @@ func readResponse(s iter.Seq2[*model.LLMResponse, error]) (TextResponse, error)
 			partialBuilder.WriteString(text)
 		} else {
 			finalBuilder.WriteString(text)
+			result.ServedModel, _ = resp.CustomMetadata[model.ServedModelMetadataKey].(string)
 		}
 	}
 
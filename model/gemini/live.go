@@ -0,0 +1,227 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"sync"
+
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+var _ model.LiveLLM = (*geminiModel)(nil)
+
+// ConnectLive opens a Gemini Live API session.
+//
+// The conversation history in req.Contents is sent to the model as the
+// initial turns of the session.
+func (m *geminiModel) ConnectLive(ctx context.Context, req *model.LLMRequest) (model.LiveConnection, error) {
+	cfg := liveConnectConfig(req.Config)
+	if cfg.HTTPOptions == nil {
+		cfg.HTTPOptions = &genai.HTTPOptions{}
+	}
+	if cfg.HTTPOptions.Headers == nil {
+		cfg.HTTPOptions.Headers = make(http.Header)
+	}
+	m.addHeaders(cfg.HTTPOptions.Headers)
+
+	s, err := m.client.Live.Connect(ctx, m.name, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to model: %w", err)
+	}
+	conn := &liveConnection{session: s}
+	if len(req.Contents) > 0 {
+		turnComplete := false
+		if err := s.SendClientContent(genai.LiveClientContentInput{Turns: req.Contents, TurnComplete: &turnComplete}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to send history: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// liveConnectConfig converts the generate content config of the request to
+// the live connect config.
+func liveConnectConfig(c *genai.GenerateContentConfig) *genai.LiveConnectConfig {
+	if c == nil {
+		return &genai.LiveConnectConfig{}
+	}
+	cfg := &genai.LiveConnectConfig{
+		Temperature:       c.Temperature,
+		TopP:              c.TopP,
+		TopK:              c.TopK,
+		MaxOutputTokens:   c.MaxOutputTokens,
+		MediaResolution:   c.MediaResolution,
+		Seed:              c.Seed,
+		SpeechConfig:      c.SpeechConfig,
+		SystemInstruction: c.SystemInstruction,
+		Tools:             c.Tools,
+	}
+	// The options are cloned, since ConnectLive adds its headers.
+	if c.HTTPOptions != nil {
+		opts := *c.HTTPOptions
+		opts.Headers = c.HTTPOptions.Headers.Clone()
+		cfg.HTTPOptions = &opts
+	}
+	for _, m := range c.ResponseModalities {
+		cfg.ResponseModalities = append(cfg.ResponseModalities, genai.Modality(m))
+	}
+	return cfg
+}
+
+type liveConnection struct {
+	session *genai.Session
+
+	// sendMu serializes writes to the underlying websocket.
+	sendMu sync.Mutex
+
+	mu     sync.Mutex
+	closed bool
+	// text accumulates partial text of the current model turn.
+	text string
+}
+
+// Send implements model.LiveConnection.
+func (c *liveConnection) Send(ctx context.Context, req *model.LiveRequest) error {
+	if req.Close {
+		return c.Close()
+	}
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	switch {
+	case req.Blob != nil:
+		return c.session.SendRealtimeInput(genai.LiveRealtimeInput{Media: req.Blob})
+	case req.Content != nil:
+		if responses := utils.FunctionResponses(req.Content); len(responses) > 0 {
+			return c.session.SendToolResponse(genai.LiveToolResponseInput{FunctionResponses: responses})
+		}
+		return c.session.SendClientContent(genai.LiveClientContentInput{Turns: []*genai.Content{req.Content}})
+	}
+	return nil
+}
+
+// Receive implements model.LiveConnection.
+func (c *liveConnection) Receive(ctx context.Context) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for {
+			msg, err := c.session.Receive()
+			if err != nil {
+				if c.isClosed() || ctx.Err() != nil {
+					return
+				}
+				yield(nil, fmt.Errorf("failed to receive from model: %w", err))
+				return
+			}
+			for _, resp := range c.responses(msg) {
+				if !yield(resp, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// responses converts a server message to zero or more LLM responses.
+//
+// Text is yielded as partial responses and aggregated into a single
+// non-partial response when the turn completes or gets interrupted.
+func (c *liveConnection) responses(msg *genai.LiveServerMessage) []*model.LLMResponse {
+	var ret []*model.LLMResponse
+	if msg.ToolCall != nil && len(msg.ToolCall.FunctionCalls) > 0 {
+		ret = append(ret, c.flushText()...)
+		content := &genai.Content{Role: genai.RoleModel}
+		for _, fc := range msg.ToolCall.FunctionCalls {
+			content.Parts = append(content.Parts, &genai.Part{FunctionCall: fc})
+		}
+		ret = append(ret, &model.LLMResponse{Content: content})
+	}
+	sc := msg.ServerContent
+	if sc == nil {
+		return ret
+	}
+	if turn := sc.ModelTurn; turn != nil && len(turn.Parts) > 0 {
+		if text := textOf(turn); text != "" {
+			c.text += text
+			ret = append(ret, &model.LLMResponse{Content: turn, Partial: true})
+		} else {
+			ret = append(ret, &model.LLMResponse{Content: turn, GroundingMetadata: sc.GroundingMetadata})
+		}
+	}
+	if sc.Interrupted {
+		ret = append(ret, c.flushText()...)
+		ret = append(ret, &model.LLMResponse{Interrupted: true})
+	}
+	if sc.TurnComplete {
+		ret = append(ret, c.flushText()...)
+		ret = append(ret, &model.LLMResponse{TurnComplete: true, UsageMetadata: usageMetadata(msg.UsageMetadata)})
+	}
+	return ret
+}
+
+// flushText returns the text accumulated so far as a single response.
+func (c *liveConnection) flushText() []*model.LLMResponse {
+	if c.text == "" {
+		return nil
+	}
+	resp := &model.LLMResponse{Content: genai.NewContentFromText(c.text, genai.RoleModel)}
+	c.text = ""
+	return []*model.LLMResponse{resp}
+}
+
+// Close implements model.LiveConnection.
+func (c *liveConnection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.session.Close()
+}
+
+func (c *liveConnection) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func textOf(c *genai.Content) string {
+	var text string
+	for _, p := range c.Parts {
+		if !p.Thought {
+			text += p.Text
+		}
+	}
+	return text
+}
+
+func usageMetadata(u *genai.UsageMetadata) *genai.GenerateContentResponseUsageMetadata {
+	if u == nil {
+		return nil
+	}
+	return &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:        u.PromptTokenCount,
+		CachedContentTokenCount: u.CachedContentTokenCount,
+		CandidatesTokenCount:    u.ResponseTokenCount,
+		ToolUsePromptTokenCount: u.ToolUsePromptTokenCount,
+		ThoughtsTokenCount:      u.ThoughtsTokenCount,
+		TotalTokenCount:         u.TotalTokenCount,
+	}
+}
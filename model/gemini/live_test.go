@@ -0,0 +1,47 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+)
+
+func TestLiveConnectConfig_HTTPOptions(t *testing.T) {
+	opts := &genai.HTTPOptions{
+		BaseURL: "https://example.com",
+		Headers: http.Header{"X-Custom": []string{"value"}},
+	}
+	cfg := liveConnectConfig(&genai.GenerateContentConfig{HTTPOptions: opts})
+	if cfg.HTTPOptions == opts {
+		t.Fatalf("liveConnectConfig() shares the HTTP options of the request")
+	}
+
+	// The headers added by ConnectLive do not change the request options.
+	(&geminiModel{versionHeaderValue: "adk-go"}).addHeaders(cfg.HTTPOptions.Headers)
+	if cfg.HTTPOptions.BaseURL != opts.BaseURL {
+		t.Errorf("liveConnectConfig() base URL = %q, want %q", cfg.HTTPOptions.BaseURL, opts.BaseURL)
+	}
+	want := http.Header{"X-Custom": []string{"value"}}
+	if diff := cmp.Diff(want, opts.Headers); diff != "" {
+		t.Errorf("request headers modified (-want +got):\n%s", diff)
+	}
+	if got := cfg.HTTPOptions.Headers.Get("X-Custom"); got != "value" {
+		t.Errorf("liveConnectConfig() header X-Custom = %q, want %q", got, "value")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"iter"
+
+	"google.golang.org/genai"
+)
+
+// LiveLLM is implemented by models that support bidirectional streaming.
+type LiveLLM interface {
+	LLM
+	// ConnectLive opens a live connection to the model.
+	//
+	// The request carries the system instruction, tools, generation config and
+	// the conversation history that the model should start from.
+	ConnectLive(ctx context.Context, req *LLMRequest) (LiveConnection, error)
+}
+
+// LiveConnection is a full-duplex connection to a model.
+//
+// Send and Receive can be called concurrently.
+type LiveConnection interface {
+	// Send sends the request to the model.
+	Send(ctx context.Context, req *LiveRequest) error
+	// Receive yields model responses as they arrive.
+	//
+	// The iterator ends without an error once the connection is closed.
+	Receive(ctx context.Context) iter.Seq2[*LLMResponse, error]
+	// Close closes the connection.
+	Close() error
+}
+
+// LiveRequest is a single message sent to the model over a live connection.
+//
+// Only one of the fields is expected to be set.
+type LiveRequest struct {
+	// Content is sent in turn-by-turn mode, e.g. user text or function
+	// responses.
+	Content *genai.Content
+	// Blob is sent in realtime mode, e.g. audio or video chunks.
+	Blob *genai.Blob
+	// Close signals the end of the live session.
+	Close bool
+}
@@ -17,11 +17,26 @@ package model
 
 import (
 	"context"
+	"errors"
 	"iter"
 
 	"google.golang.org/genai"
 )
 
+// ErrOutputTruncated is returned when the model stopped generating a response
+// because it reached the maximum number of output tokens.
+var ErrOutputTruncated = errors.New("model output truncated: maximum number of output tokens reached")
+
+// ErrInvalidOutput is returned when the output of the model is rejected by
+// the output validation of the agent.
+var ErrInvalidOutput = errors.New("invalid model output")
+
+// ServedModelMetadataKey is the key of the name of the model that served a
+// response in its custom metadata, set by the models serving a call with
+// another model than their own, e.g. fallback models or models honoring
+// [LLMRequest.Model]. The usage accounting prices the response with it.
+const ServedModelMetadataKey = "adk_served_model"
+
 // LLM provides the access to the underlying LLM.
 type LLM interface {
 	Name() string
@@ -14,7 +14,11 @@
 
 package openai
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/openai/openai-go/v3"
+)
 
 var (
 	// ErrModelNameRequired is returned when a model name is not provided.
@@ -52,3 +56,28 @@ var (
 	// ErrNoTextOrToolContent is returned when the response output does not contain text or tool content.
 	ErrNoTextOrToolContent = errors.New("openai: response output did not contain text or tool content")
 )
+
+// statusError annotates an error of the OpenAI API with the HTTP status code
+// of its response, so that it can be classified, e.g. as a transient error
+// by the resilient model.
+type statusError struct {
+	err  error
+	code int
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+
+func (e *statusError) Unwrap() error { return e.err }
+
+// HTTPStatusCode returns the HTTP status code of the failed response.
+func (e *statusError) HTTPStatusCode() int { return e.code }
+
+// withStatus annotates err with its HTTP status code if it is an error of
+// the OpenAI API.
+func withStatus(err error) error {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return &statusError{err: err, code: apiErr.StatusCode}
+	}
+	return err
+}
@@ -67,7 +67,7 @@ func (m *openAIModel) generate(ctx context.Context, params responses.ResponseNew
 	return func(yield func(*model.LLMResponse, error) bool) {
 		resp, err := m.client.Responses.New(ctx, params)
 		if err != nil {
-			yield(nil, fmt.Errorf("openai: call failed: %w", err))
+			yield(nil, fmt.Errorf("openai: call failed: %w", withStatus(err)))
 			return
 		}
 		genaiResp, err := convertResponse(resp)
@@ -89,7 +89,7 @@ func (m *openAIModel) generateStream(ctx context.Context, params responses.Respo
 			return
 		}
 		if err := stream.Err(); err != nil {
-			yield(nil, err)
+			yield(nil, withStatus(err))
 			return
 		}
 
@@ -109,13 +109,14 @@ func (m *openAIModel) generateStream(ctx context.Context, params responses.Respo
 			}
 			// Then, we accumulate the streaming responses and yield them as discrete LLMResponses.
 			for resp, err := range aggregator.ProcessResponse(ctx, genaiResp) {
+				setServedModel(resp, string(params.Model))
 				if !yield(resp, err) {
 					return
 				}
 			}
 		}
 		if err := stream.Err(); err != nil {
-			yield(nil, err)
+			yield(nil, withStatus(err))
 			return
 		}
 		if err := stream.Close(); err != nil {
@@ -123,6 +124,7 @@ func (m *openAIModel) generateStream(ctx context.Context, params responses.Respo
 			return
 		}
 		if final := aggregator.Close(); final != nil {
+			setServedModel(final, string(params.Model))
 			yield(final, nil)
 		}
 	}
@@ -137,6 +139,19 @@ func attachMetadata(resp *model.LLMResponse, openaiResp *responses.Response) {
 	}
 	resp.CustomMetadata["openai_response_id"] = openaiResp.ID
 	resp.CustomMetadata["openai_model"] = openaiResp.Model
+	resp.CustomMetadata[model.ServedModelMetadataKey] = string(openaiResp.Model)
+}
+
+// setServedModel records the model that served the streamed response, if
+// known.
+func setServedModel(resp *model.LLMResponse, modelName string) {
+	if resp == nil || modelName == "" {
+		return
+	}
+	if resp.CustomMetadata == nil {
+		resp.CustomMetadata = map[string]any{}
+	}
+	resp.CustomMetadata[model.ServedModelMetadataKey] = modelName
 }
 
 func singleErrorSequence(err error) iter.Seq2[*model.LLMResponse, error] {
@@ -0,0 +1,54 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openaitest provides utilities for testing agents using OpenAI
+// models, with HTTP record and replay, see package httprr:
+//
+//	rr, err := httprr.Open(filepath.Join("testdata", t.Name()+".httprr"), http.DefaultTransport)
+//	...
+//	llm, err := openaimodel.NewModel(ctx, openai.ChatModelGPT4oMini, openaitest.NewClient(rr))
+package openaitest
+
+import (
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"google.golang.org/adk/adktest/httprr"
+)
+
+// NewClient returns an OpenAI client, e.g. for openai.NewModel, sending its
+// requests through rr. The opts are applied last.
+//
+// It adds to rr the scrubbers of the OpenAI API requests: the credentials
+// and the headers varying with the SDK version, the platform or the
+// attempt are removed, and the JSON bodies are canonicalized. The client
+// does not retry, so that the requests missing from the log fail fast. In
+// replay mode, the API key is a fake one, so that no credentials are
+// needed; in record mode, it is read from the environment, see
+// openai.NewClient.
+func NewClient(rr *httprr.RecordReplay, opts ...option.RequestOption) openai.Client {
+	rr.ScrubReq(
+		httprr.ScrubAuth,
+		httprr.ScrubHeaders("User-Agent", "OpenAI-Organization", "OpenAI-Project", "Idempotency-Key"),
+		httprr.ScrubHeaderPrefix("X-Stainless-"),
+		httprr.CanonicalizeJSON,
+	)
+	clientOpts := []option.RequestOption{
+		option.WithHTTPClient(rr.Client()),
+		option.WithMaxRetries(0),
+	}
+	if !rr.Recording() {
+		clientOpts = append(clientOpts, option.WithAPIKey("fake-key"))
+	}
+	return openai.NewClient(append(clientOpts, opts...)...)
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resilient
+
+import (
+	"sync"
+	"time"
+)
+
+// now returns the current time. It is replaced by the tests.
+var now = time.Now
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	// circuitHalfOpen lets a single trial call through.
+	circuitHalfOpen
+)
+
+// circuitBreaker tracks the consecutive transient errors of a model. A nil
+// *circuitBreaker always allows the calls.
+type circuitBreaker struct {
+	threshold    int
+	openDuration time.Duration
+
+	mu        sync.Mutex
+	state     circuitState
+	failures  int
+	openUntil time.Time
+}
+
+// allow reports whether a call can be made to the model.
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case circuitOpen:
+		if now().Before(b.openUntil) {
+			return false
+		}
+		b.state = circuitHalfOpen
+		return true
+	case circuitHalfOpen:
+		// The trial call is in progress.
+		return false
+	}
+	return true
+}
+
+// record records the result of a call: transient errors are failures, any
+// other result shows that the model is available.
+func (b *circuitBreaker) record(err error, isTransient func(error) bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil || !isTransient(err) {
+		b.state = circuitClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		b.state = circuitOpen
+		b.openUntil = now().Add(b.openDuration)
+	}
+}
+
+// abandon records a call abandoned by the caller, e.g. cancelled, which
+// tells nothing about the model: a trial call is made again by the next
+// call.
+func (b *circuitBreaker) abandon() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == circuitHalfOpen {
+		b.state = circuitOpen
+	}
+}
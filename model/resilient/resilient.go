@@ -0,0 +1,337 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resilient implements a [model.LLM] retrying the transient errors of
+// the models it wraps, falling back to the next model of a chain once a
+// model keeps failing, and skipping the models whose circuit breaker is open.
+//
+// A call is never retried once the model yielded a response, e.g. a partial
+// response in streaming mode: the consumer would otherwise receive the same
+// output twice. The errors following the first response are returned as is.
+package resilient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// Config is used to create a resilient model.
+type Config struct {
+	// Models are the models to call, in order: the first one is the primary
+	// model, the next ones are the fallbacks called once the previous ones
+	// failed with transient errors. At least one is required.
+	Models []model.LLM
+	// Retry is the retry policy applied to each model.
+	Retry RetryPolicy
+	// CircuitBreaker configures a circuit breaker per model. Optional: nil
+	// disables it.
+	CircuitBreaker *CircuitBreakerConfig
+}
+
+// RetryPolicy controls the retries of the calls failing with transient
+// errors. The zero value retries twice, after about 1s and 2s.
+type RetryPolicy struct {
+	// MaxAttempts is the number of calls made to a model, the first one
+	// included, before falling back to the next model. Defaults to 3; 1
+	// disables the retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. Defaults to 1s.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries. Defaults to 30s.
+	MaxBackoff time.Duration
+	// Multiplier is the factor applied to the delay after each retry.
+	// Defaults to 2.
+	Multiplier float64
+	// Jitter is the fraction of the delay randomly added or removed, between
+	// 0 and 1, so that concurrent clients do not retry in lockstep. Defaults
+	// to 0.2; a negative value disables it.
+	Jitter float64
+	// IsTransient classifies the errors: transient errors are retried, then
+	// fall back to the next model, the other ones are returned to the caller.
+	// Defaults to IsTransient.
+	IsTransient func(error) bool
+}
+
+// CircuitBreakerConfig configures the circuit breakers of the models.
+//
+// The circuit of a model opens after FailureThreshold consecutive transient
+// errors: the model is then skipped for OpenDuration, after which a single
+// trial call decides whether the circuit closes again.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive transient errors opening
+	// the circuit. Required.
+	FailureThreshold int
+	// OpenDuration is the time during which the model is skipped. Defaults
+	// to 30s.
+	OpenDuration time.Duration
+}
+
+// ErrCircuitOpen is returned when the circuits of all the models are open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// New returns a model calling the models of the chain with retries.
+//
+// Its name is the name of the primary model.
+func New(cfg Config) (model.LLM, error) {
+	if len(cfg.Models) == 0 {
+		return nil, fmt.Errorf("at least one model is required")
+	}
+	for i, m := range cfg.Models {
+		if m == nil {
+			return nil, fmt.Errorf("model %d is nil", i)
+		}
+	}
+	retry := cfg.Retry
+	if retry.MaxAttempts == 0 {
+		retry.MaxAttempts = 3
+	}
+	if retry.InitialBackoff == 0 {
+		retry.InitialBackoff = time.Second
+	}
+	if retry.MaxBackoff == 0 {
+		retry.MaxBackoff = 30 * time.Second
+	}
+	if retry.Multiplier == 0 {
+		retry.Multiplier = 2
+	}
+	if retry.Jitter == 0 {
+		retry.Jitter = 0.2
+	}
+	if retry.IsTransient == nil {
+		retry.IsTransient = IsTransient
+	}
+	if retry.MaxAttempts < 0 || retry.InitialBackoff < 0 || retry.MaxBackoff < 0 || retry.Multiplier < 1 || retry.Jitter > 1 {
+		return nil, fmt.Errorf("invalid retry policy: %+v", cfg.Retry)
+	}
+
+	llm := &resilientModel{retry: retry}
+	for _, m := range cfg.Models {
+		var breaker *circuitBreaker
+		if cb := cfg.CircuitBreaker; cb != nil {
+			if cb.FailureThreshold <= 0 || cb.OpenDuration < 0 {
+				return nil, fmt.Errorf("invalid circuit breaker config: %+v", *cb)
+			}
+			breaker = &circuitBreaker{threshold: cb.FailureThreshold, openDuration: cb.OpenDuration}
+			if breaker.openDuration == 0 {
+				breaker.openDuration = 30 * time.Second
+			}
+		}
+		llm.models = append(llm.models, &chainModel{LLM: m, breaker: breaker})
+	}
+	return llm, nil
+}
+
+type resilientModel struct {
+	models []*chainModel
+	retry  RetryPolicy
+}
+
+type chainModel struct {
+	model.LLM
+	breaker *circuitBreaker
+}
+
+func (m *resilientModel) Name() string {
+	return m.models[0].Name()
+}
+
+// GenerateContent calls the models of the chain in order, retrying each one
+// on transient errors until one of them yields a response.
+func (m *resilientModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		var errs []error
+		for _, llm := range m.models {
+			modelReq := req
+			if req != nil && req.Model != llm.Name() {
+				r := *req
+				r.Model = llm.Name()
+				modelReq = &r
+			}
+			done, err := m.generate(ctx, llm, modelReq, stream, yield)
+			if done {
+				return
+			}
+			errs = append(errs, fmt.Errorf("model %q: %w", llm.Name(), err))
+			if !errors.Is(err, ErrCircuitOpen) && !m.retry.IsTransient(err) {
+				break
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		if len(errs) == 1 {
+			yield(nil, errs[0])
+			return
+		}
+		yield(nil, fmt.Errorf("all models failed: %w", errors.Join(errs...)))
+	}
+}
+
+// generate calls the model with retries. It reports whether the call is
+// done, i.e. the model yielded a response or the consumer stopped, or
+// returns the error of the last attempt.
+func (m *resilientModel) generate(ctx context.Context, llm *chainModel, req *model.LLMRequest, stream bool, yield func(*model.LLMResponse, error) bool) (bool, error) {
+	var err error
+	for attempt := 0; attempt < m.retry.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, m.retry.backoff(attempt)); err != nil {
+				return false, err
+			}
+		}
+		if !llm.breaker.allow() {
+			return false, ErrCircuitOpen
+		}
+		var done bool
+		done, err = m.attempt(ctx, llm, req, stream, yield)
+		if done {
+			return true, nil
+		}
+		if !m.retry.IsTransient(err) {
+			return false, err
+		}
+	}
+	return false, err
+}
+
+// attempt makes a single call to the model. Once the model yielded a
+// response, its responses and errors are forwarded to the consumer, marked
+// as served by the model, and the call is done.
+func (m *resilientModel) attempt(ctx context.Context, llm *chainModel, req *model.LLMRequest, stream bool, yield func(*model.LLMResponse, error) bool) (bool, error) {
+	yielded := false
+	for resp, err := range llm.GenerateContent(ctx, req, stream) {
+		if err != nil && !yielded {
+			if callerDone(ctx, err) {
+				llm.breaker.abandon()
+			} else {
+				llm.breaker.record(err, m.retry.IsTransient)
+			}
+			return false, err
+		}
+		if !yielded {
+			yielded = true
+			llm.breaker.record(nil, m.retry.IsTransient)
+		}
+		if !yield(served(resp, llm.Name()), err) {
+			return true, nil
+		}
+	}
+	if !yielded {
+		// The model yielded nothing: the call is not retried, the model
+		// would most likely do the same again.
+		if ctx.Err() != nil {
+			llm.breaker.abandon()
+		} else {
+			llm.breaker.record(nil, m.retry.IsTransient)
+		}
+	}
+	return true, nil
+}
+
+// callerDone reports whether err is caused by the cancellation or the
+// deadline of the caller's ctx, rather than by the model.
+func callerDone(ctx context.Context, err error) bool {
+	return ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded))
+}
+
+// served returns the response marked as served by the model, unless the
+// model already named the model that served it.
+func served(resp *model.LLMResponse, modelName string) *model.LLMResponse {
+	if resp == nil {
+		return nil
+	}
+	if _, ok := resp.CustomMetadata[model.ServedModelMetadataKey]; ok {
+		return resp
+	}
+	r := *resp
+	r.CustomMetadata = make(map[string]any, len(resp.CustomMetadata)+1)
+	maps.Copy(r.CustomMetadata, resp.CustomMetadata)
+	r.CustomMetadata[model.ServedModelMetadataKey] = modelName
+	return &r
+}
+
+// backoff returns the jittered delay before the given retry.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < retry && d < float64(p.MaxBackoff); i++ {
+		d *= p.Multiplier
+	}
+	d = min(d, float64(p.MaxBackoff))
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// sleep waits for d, or until ctx is done. It is replaced by the tests.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// IsTransient reports whether the error of a model call is transient, i.e.
+// whether the call may succeed if made again: the HTTP statuses 408, 429,
+// 500, 502, 503 and 504, the network timeouts and the connections closed
+// unexpectedly. Context cancellations are not transient.
+//
+// The HTTP status is read from [genai.APIError], returned by the Gemini
+// models, or from the errors with an HTTPStatusCode() int method, e.g. the
+// errors of the OpenAI API returned by the OpenAI models.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return transientStatus(apiErr.Code)
+	}
+	var apiErrPtr *genai.APIError
+	if errors.As(err, &apiErrPtr) {
+		return transientStatus(apiErrPtr.Code)
+	}
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		return transientStatus(statusErr.HTTPStatusCode())
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed)
+}
+
+func transientStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,329 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resilient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// call is the scripted result of a model call: the texts yielded, then the
+// error, if any.
+type call struct {
+	texts []string
+	err   error
+}
+
+// scriptedModel returns the scripted calls in order, then fails with a 503.
+type scriptedModel struct {
+	name     string
+	calls    []call
+	requests []*model.LLMRequest
+}
+
+func (m *scriptedModel) Name() string { return m.name }
+
+func (m *scriptedModel) GenerateContent(_ context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.requests = append(m.requests, req)
+		c := call{err: unavailable}
+		if len(m.requests) <= len(m.calls) {
+			c = m.calls[len(m.requests)-1]
+		}
+		for _, text := range c.texts {
+			if !yield(&model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleModel), Partial: stream}, nil) {
+				return
+			}
+		}
+		if c.err != nil {
+			yield(nil, c.err)
+		}
+	}
+}
+
+var (
+	unavailable = genai.APIError{Code: http.StatusServiceUnavailable, Status: "UNAVAILABLE"}
+	badRequest  = genai.APIError{Code: http.StatusBadRequest, Status: "INVALID_ARGUMENT"}
+)
+
+// fakeTime replaces the clock and records the sleeps for the duration of the
+// test.
+func fakeTime(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var sleeps []time.Duration
+	current := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	origSleep, origNow := sleep, now
+	sleep = func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		current = current.Add(d)
+		return nil
+	}
+	now = func() time.Time { return current }
+	t.Cleanup(func() { sleep, now = origSleep, origNow })
+	return &sleeps
+}
+
+func collect(llm model.LLM, stream bool) ([]string, error) {
+	var texts []string
+	for resp, err := range llm.GenerateContent(context.Background(), &model.LLMRequest{Model: llm.Name()}, stream) {
+		if err != nil {
+			return texts, err
+		}
+		texts = append(texts, resp.Content.Parts[0].Text)
+	}
+	return texts, nil
+}
+
+func TestGenerateContent(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   []call
+		fallback  []call
+		stream    bool
+		wantTexts []string
+		// wantErrCode is the HTTP status of the returned genai.APIError.
+		wantErrCode   int
+		wantPrimary   int
+		wantFallback  int
+		wantSleeps    []time.Duration
+		wantErrString string
+	}{
+		{
+			name:        "success",
+			primary:     []call{{texts: []string{"hello"}}},
+			wantTexts:   []string{"hello"},
+			wantPrimary: 1,
+		},
+		{
+			name:        "transient errors are retried",
+			primary:     []call{{err: unavailable}, {err: fmt.Errorf("wrapped: %w", genai.APIError{Code: http.StatusTooManyRequests})}, {texts: []string{"hello"}}},
+			wantTexts:   []string{"hello"},
+			wantPrimary: 3,
+			wantSleeps:  []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:        "permanent errors are not retried",
+			primary:     []call{{err: badRequest}},
+			fallback:    []call{{texts: []string{"fallback"}}},
+			wantErrCode: http.StatusBadRequest,
+			wantPrimary: 1,
+		},
+		{
+			name:         "fallback after the retries",
+			fallback:     []call{{texts: []string{"fallback"}}},
+			wantTexts:    []string{"fallback"},
+			wantPrimary:  3,
+			wantFallback: 1,
+			wantSleeps:   []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:          "all models fail",
+			wantErrCode:   http.StatusServiceUnavailable,
+			wantErrString: `all models failed: model "primary": Error 503`,
+			wantPrimary:   3,
+			wantFallback:  3,
+			wantSleeps:    []time.Duration{time.Second, 2 * time.Second, time.Second, 2 * time.Second},
+		},
+		{
+			name:        "no retry after partial output",
+			primary:     []call{{texts: []string{"hel"}, err: unavailable}, {texts: []string{"hello"}}},
+			stream:      true,
+			wantTexts:   []string{"hel"},
+			wantErrCode: http.StatusServiceUnavailable,
+			wantPrimary: 1,
+		},
+		{
+			name:        "streaming retried before any output",
+			primary:     []call{{err: unavailable}, {texts: []string{"hel", "lo"}}},
+			stream:      true,
+			wantTexts:   []string{"hel", "lo"},
+			wantPrimary: 2,
+			wantSleeps:  []time.Duration{time.Second},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sleeps := fakeTime(t)
+			primary := &scriptedModel{name: "primary", calls: tc.primary}
+			fallback := &scriptedModel{name: "fallback", calls: tc.fallback}
+			llm, err := New(Config{Models: []model.LLM{primary, fallback}, Retry: RetryPolicy{Jitter: -1}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			texts, err := collect(llm, tc.stream)
+			gotCode := 0
+			var apiErr genai.APIError
+			if errors.As(err, &apiErr) {
+				gotCode = apiErr.Code
+			}
+			if gotCode != tc.wantErrCode || (err == nil) != (tc.wantErrCode == 0) {
+				t.Errorf("GenerateContent() error = %v, want error code %d", err, tc.wantErrCode)
+			}
+			if tc.wantErrString != "" && (err == nil || !strings.HasPrefix(err.Error(), tc.wantErrString)) {
+				t.Errorf("GenerateContent() error = %v, want prefix %q", err, tc.wantErrString)
+			}
+			if diff := cmp.Diff(tc.wantTexts, texts); diff != "" {
+				t.Errorf("GenerateContent() texts mismatch (-want +got):\n%s", diff)
+			}
+			if got := len(primary.requests); got != tc.wantPrimary {
+				t.Errorf("primary model calls = %d, want %d", got, tc.wantPrimary)
+			}
+			if got := len(fallback.requests); got != tc.wantFallback {
+				t.Errorf("fallback model calls = %d, want %d", got, tc.wantFallback)
+			}
+			for _, req := range fallback.requests {
+				if req.Model != "fallback" {
+					t.Errorf("fallback request model = %q, want %q", req.Model, "fallback")
+				}
+			}
+			if diff := cmp.Diff(tc.wantSleeps, *sleeps); diff != "" {
+				t.Errorf("backoff mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	fakeTime(t)
+	primary := &scriptedModel{name: "primary", calls: []call{
+		{err: unavailable}, {err: unavailable},
+		// The trial call once the circuit is half-open.
+		{texts: []string{"recovered"}},
+	}}
+	fallback := &scriptedModel{name: "fallback", calls: []call{
+		{texts: []string{"fallback 1"}}, {texts: []string{"fallback 2"}}, {texts: []string{"fallback 3"}},
+	}}
+	llm, err := New(Config{
+		Models:         []model.LLM{primary, fallback},
+		Retry:          RetryPolicy{MaxAttempts: 1},
+		CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: 2, OpenDuration: time.Minute},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for range 2 {
+		texts, err := collect(llm, false)
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		got = append(got, texts...)
+	}
+	if len(primary.requests) != 2 {
+		t.Fatalf("primary model calls = %d, want 2", len(primary.requests))
+	}
+
+	// The circuit of the primary model is open.
+	texts, err := collect(llm, false)
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+	got = append(got, texts...)
+	if len(primary.requests) != 2 {
+		t.Errorf("primary model calls with an open circuit = %d, want 2", len(primary.requests))
+	}
+
+	// The circuit is half-open once the open duration elapsed.
+	_ = sleep(context.Background(), time.Minute)
+	texts, err = collect(llm, false)
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+	got = append(got, texts...)
+	if diff := cmp.Diff([]string{"fallback 1", "fallback 2", "fallback 3", "recovered"}, got); diff != "" {
+		t.Errorf("responses mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCircuitBreaker_Canceled(t *testing.T) {
+	fakeTime(t)
+	primary := &scriptedModel{name: "primary", calls: []call{
+		{err: unavailable}, {err: unavailable},
+		// The trial call once the circuit is half-open, cancelled by the
+		// caller.
+		{err: context.Canceled},
+		// The next trial call.
+		{err: unavailable},
+		{texts: []string{"recovered"}},
+	}}
+	fallback := &scriptedModel{name: "fallback"}
+	llm, err := New(Config{
+		Models:         []model.LLM{primary, fallback},
+		Retry:          RetryPolicy{MaxAttempts: 1},
+		CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: 2, OpenDuration: time.Minute},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		_, _ = collect(llm, false)
+	}
+	_ = sleep(context.Background(), time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range llm.GenerateContent(ctx, &model.LLMRequest{}, false) {
+	}
+	if len(primary.requests) != 3 {
+		t.Fatalf("primary model calls = %d, want 3", len(primary.requests))
+	}
+
+	// The cancelled trial call does not close the circuit: the next trial
+	// call fails and opens it again.
+	for range 2 {
+		_, _ = collect(llm, false)
+	}
+	if len(primary.requests) != 4 {
+		t.Errorf("primary model calls = %d, want 4", len(primary.requests))
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: unavailable, want: true},
+		{err: &genai.APIError{Code: http.StatusTooManyRequests}, want: true},
+		{err: badRequest, want: false},
+		{err: statusError(http.StatusBadGateway), want: true},
+		{err: fmt.Errorf("call failed: %w", statusError(http.StatusUnauthorized)), want: false},
+		{err: context.Canceled, want: false},
+		{err: errors.New("boom"), want: false},
+	}
+	for _, tc := range tests {
+		if got := IsTransient(tc.err); got != tc.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+type statusError int
+
+func (e statusError) Error() string { return fmt.Sprintf("status %d", int(e)) }
+
+func (e statusError) HTTPStatusCode() int { return int(e) }
@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/genai"
+)
+
+// TokenCounter counts the tokens of contents, e.g. to keep the conversation
+// history within a token budget.
+//
+// The Gemini models implement it with the CountTokens API.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, contents []*genai.Content) (int, error)
+}
+
+// mediaTokens is the estimated number of tokens of a media part.
+const mediaTokens = 258
+
+// HeuristicTokenCounter estimates the number of tokens from the size of the
+// contents: about 4 bytes per token of text, function call or function
+// response, and 258 tokens per media part. It makes no API call.
+type HeuristicTokenCounter struct{}
+
+// CountTokens implements [TokenCounter].
+func (HeuristicTokenCounter) CountTokens(_ context.Context, contents []*genai.Content) (int, error) {
+	bytes, media := 0, 0
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		for _, p := range c.Parts {
+			bytes += len(p.Text)
+			switch {
+			case p.FunctionCall != nil:
+				bytes += len(p.FunctionCall.Name) + jsonLen(p.FunctionCall.Args)
+			case p.FunctionResponse != nil:
+				bytes += len(p.FunctionResponse.Name) + jsonLen(p.FunctionResponse.Response)
+			case p.ExecutableCode != nil:
+				bytes += len(p.ExecutableCode.Code)
+			case p.CodeExecutionResult != nil:
+				bytes += len(p.CodeExecutionResult.Output)
+			case p.InlineData != nil, p.FileData != nil:
+				media++
+			}
+		}
+	}
+	return (bytes+3)/4 + media*mediaTokens, nil
+}
+
+func jsonLen(v map[string]any) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
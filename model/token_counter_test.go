@@ -0,0 +1,66 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestHeuristicTokenCounter(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents []*genai.Content
+		want     int
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "text",
+			contents: []*genai.Content{genai.NewContentFromText(strings.Repeat("a", 40), genai.RoleUser), genai.NewContentFromText("abc", genai.RoleModel)},
+			want:     11,
+		},
+		{
+			name: "function call and response",
+			contents: []*genai.Content{
+				// 2 bytes of name, 7 bytes of arguments.
+				genai.NewContentFromFunctionCall("fn", map[string]any{"a": 1}, genai.RoleModel),
+				// 2 bytes of name, 7 bytes of response.
+				genai.NewContentFromFunctionResponse("fn", map[string]any{"b": 2}, genai.RoleUser),
+			},
+			want: 5,
+		},
+		{
+			name:     "media",
+			contents: []*genai.Content{genai.NewContentFromBytes([]byte("image"), "image/png", genai.RoleUser)},
+			want:     258,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := model.HeuristicTokenCounter{}.CountTokens(t.Context(), tc.contents)
+			if err != nil {
+				t.Fatalf("CountTokens() error = %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("CountTokens() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
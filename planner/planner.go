@@ -0,0 +1,70 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package planner provides planners that make an LLM agent plan and reason
+// before it acts.
+//
+// A planner is set on an LLM agent via llmagent.Config.Planner. The planning
+// and reasoning produced by the model are marked as thoughts: they are part
+// of the events but are not sent back to the model in later requests.
+package planner
+
+import (
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// Planner guides the model to plan before it acts.
+type Planner interface {
+	// BuildPlanningInstruction returns the instruction appended to the system
+	// instruction of the request. It may also adjust the request, e.g. its
+	// generation config. An empty string adds no instruction.
+	BuildPlanningInstruction(ctx agent.ReadonlyContext, req *model.LLMRequest) (string, error)
+	// ProcessPlanningResponse post-processes the parts of a model response,
+	// e.g. to mark the planning parts as thoughts. It returns the parts that
+	// replace the response parts, or nil to keep them unchanged.
+	ProcessPlanningResponse(ctx agent.ReadonlyContext, parts []*genai.Part) ([]*genai.Part, error)
+}
+
+// NewBuiltIn returns a planner that uses the built-in thinking of the model.
+//
+// The thinking config is set on every request. The model must support
+// thinking, e.g. Gemini 2.5 models.
+func NewBuiltIn(thinkingConfig *genai.ThinkingConfig) Planner {
+	return &builtInPlanner{thinkingConfig: thinkingConfig}
+}
+
+type builtInPlanner struct {
+	thinkingConfig *genai.ThinkingConfig
+}
+
+// BuildPlanningInstruction implements Planner.
+func (p *builtInPlanner) BuildPlanningInstruction(ctx agent.ReadonlyContext, req *model.LLMRequest) (string, error) {
+	if p.thinkingConfig == nil {
+		return "", nil
+	}
+	if req.Config == nil {
+		req.Config = &genai.GenerateContentConfig{}
+	}
+	req.Config.ThinkingConfig = p.thinkingConfig
+	return "", nil
+}
+
+// ProcessPlanningResponse implements Planner.
+//
+// The model already marks its thoughts, the parts are kept unchanged.
+func (p *builtInPlanner) ProcessPlanningResponse(ctx agent.ReadonlyContext, parts []*genai.Part) ([]*genai.Part, error) {
+	return nil, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
+	"google.golang.org/genai"
+)
+
+func TestPlanReAct_ProcessPlanningResponse(t *testing.T) {
+	call := func(name string) *genai.Part {
+		return &genai.Part{FunctionCall: &genai.FunctionCall{Name: name}}
+	}
+	tests := []struct {
+		name  string
+		parts []*genai.Part
+		want  []*genai.Part
+	}{
+		{
+			name: "planning and final answer",
+			parts: []*genai.Part{
+				{Text: "/*PLANNING*/\n1. think"},
+				{Text: "/*REASONING*/ done /*FINAL_ANSWER*/\n42"},
+			},
+			want: []*genai.Part{
+				{Text: "/*PLANNING*/\n1. think", Thought: true},
+				{Text: "/*REASONING*/ done /*FINAL_ANSWER*/", Thought: true},
+				{Text: "\n42"},
+			},
+		},
+		{
+			name:  "untagged text",
+			parts: []*genai.Part{{Text: "hello"}},
+			want:  []*genai.Part{{Text: "hello"}},
+		},
+		{
+			name: "parts after function calls are dropped",
+			parts: []*genai.Part{
+				{Text: "/*ACTION*/"},
+				call("a"),
+				call(""),
+				call("b"),
+				{Text: "/*REASONING*/ guessed result"},
+				call("c"),
+			},
+			want: []*genai.Part{
+				{Text: "/*ACTION*/", Thought: true},
+				call("a"),
+				call("b"),
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := planner.NewPlanReAct().ProcessPlanningResponse(nil, tc.parts)
+			if err != nil {
+				t.Fatalf("ProcessPlanningResponse() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ProcessPlanningResponse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPlanReAct_BuildPlanningInstruction(t *testing.T) {
+	got, err := planner.NewPlanReAct().BuildPlanningInstruction(nil, &model.LLMRequest{})
+	if err != nil {
+		t.Fatalf("BuildPlanningInstruction() error = %v", err)
+	}
+	for _, tag := range []string{planner.PlanningTag, planner.ReplanningTag, planner.ReasoningTag, planner.ActionTag, planner.FinalAnswerTag} {
+		if !strings.Contains(got, tag) {
+			t.Errorf("BuildPlanningInstruction() does not mention %s", tag)
+		}
+	}
+}
+
+func TestBuiltIn(t *testing.T) {
+	thinkingConfig := &genai.ThinkingConfig{IncludeThoughts: true, ThinkingBudget: genai.Ptr[int32](1024)}
+	p := planner.NewBuiltIn(thinkingConfig)
+
+	req := &model.LLMRequest{}
+	instruction, err := p.BuildPlanningInstruction(nil, req)
+	if err != nil || instruction != "" {
+		t.Fatalf("BuildPlanningInstruction() = (%q, %v), want (\"\", nil)", instruction, err)
+	}
+	if req.Config == nil || req.Config.ThinkingConfig != thinkingConfig {
+		t.Errorf("BuildPlanningInstruction() did not set the thinking config, got %+v", req.Config)
+	}
+
+	parts := []*genai.Part{{Text: "thinking", Thought: true}, {Text: "answer"}}
+	if got, err := p.ProcessPlanningResponse(nil, parts); got != nil || err != nil {
+		t.Errorf("ProcessPlanningResponse() = (%v, %v), want (nil, nil)", got, err)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugin provides the plugins of the runner: lifecycle callbacks
+// applied to the whole agent tree, e.g. for logging, policy enforcement or
+// caching, instead of configuring them in every agent.
+//
+// The plugins of a runner are called in their registration order, before
+// the callbacks of the agents. Once a callback of a plugin returns a
+// non-nil value or an error, the callbacks of the next plugins and of the
+// agent are skipped, and the value or error is used.
+//
+// The plugins also apply to the agents run by agent tools, see
+// tool/agenttool.
+package plugin
+
+import (
+	"fmt"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+)
+
+// Config of a plugin. All the callbacks are optional.
+type Config struct {
+	// Name of the plugin, unique within the plugins of a runner.
+	Name string
+
+	// OnUserMessageCallback is called with the message of the user, before
+	// it is added to the session. If it returns non-nil content, the
+	// content replaces the message.
+	OnUserMessageCallback OnUserMessageCallback
+	// BeforeRunCallback is called before the runner runs the agent. If it
+	// returns non-nil content, the agent run is skipped and the content is
+	// the response to the user.
+	BeforeRunCallback BeforeRunCallback
+	// AfterRunCallback is called once the runner ran the agent.
+	AfterRunCallback AfterRunCallback
+	// OnEventCallback is called with each event of the agents, before it is
+	// added to the session. If it returns a non-nil event, the event
+	// replaces it.
+	OnEventCallback OnEventCallback
+
+	// BeforeAgentCallback is called before each agent starts its run, like
+	// the agent.Config.BeforeAgentCallbacks.
+	BeforeAgentCallback agent.BeforeAgentCallback
+	// AfterAgentCallback is called after each agent completed its run, like
+	// the agent.Config.AfterAgentCallbacks.
+	AfterAgentCallback agent.AfterAgentCallback
+
+	// BeforeModelCallback is called before each model call. If it returns a
+	// non-nil response or an error, the model call is skipped and the
+	// response or error is used.
+	BeforeModelCallback BeforeModelCallback
+	// AfterModelCallback is called with each response of the models. If it
+	// returns a non-nil response or an error, it replaces the response.
+	AfterModelCallback AfterModelCallback
+	// OnModelErrorCallback is called when a model call fails. If it returns
+	// a non-nil response, it is used in place of the error; if it returns an
+	// error, it replaces the error.
+	OnModelErrorCallback OnModelErrorCallback
+
+	// BeforeToolCallback is called before each tool call. If it returns a
+	// non-nil result or an error, the tool call is skipped and the result or
+	// error is used.
+	BeforeToolCallback BeforeToolCallback
+	// AfterToolCallback is called with the result of each successful tool
+	// call. If it returns a non-nil result or an error, it replaces the
+	// result.
+	//
+	// Unlike the AfterToolCallbacks of the agent, it is not called when the
+	// tool call fails, unless an OnToolErrorCallback replaced the error with
+	// a result: the plugins see the error in OnToolErrorCallback instead.
+	AfterToolCallback AfterToolCallback
+	// OnToolErrorCallback is called when a tool call fails, before the
+	// AfterToolCallbacks. If it returns a non-nil result, it is used in
+	// place of the error; if it returns an error, it replaces the error.
+	OnToolErrorCallback OnToolErrorCallback
+}
+
+// OnUserMessageCallback is called with the message of the user.
+type OnUserMessageCallback func(ctx agent.InvocationContext, msg *genai.Content) (*genai.Content, error)
+
+// BeforeRunCallback is called before the runner runs the agent.
+type BeforeRunCallback func(ctx agent.InvocationContext) (*genai.Content, error)
+
+// AfterRunCallback is called once the runner ran the agent.
+type AfterRunCallback func(ctx agent.InvocationContext)
+
+// OnEventCallback is called with each event of the agents.
+type OnEventCallback func(ctx agent.InvocationContext, event *session.Event) (*session.Event, error)
+
+// BeforeModelCallback is called before each model call.
+type BeforeModelCallback func(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error)
+
+// AfterModelCallback is called with each response of the models.
+type AfterModelCallback func(ctx agent.CallbackContext, resp *model.LLMResponse) (*model.LLMResponse, error)
+
+// OnModelErrorCallback is called when a model call fails.
+type OnModelErrorCallback func(ctx agent.CallbackContext, req *model.LLMRequest, err error) (*model.LLMResponse, error)
+
+// BeforeToolCallback is called before each tool call.
+type BeforeToolCallback func(ctx tool.Context, tool tool.Tool, args map[string]any) (map[string]any, error)
+
+// AfterToolCallback is called with the result of each successful tool call,
+// see Config.AfterToolCallback.
+type AfterToolCallback func(ctx tool.Context, tool tool.Tool, args, result map[string]any) (map[string]any, error)
+
+// OnToolErrorCallback is called when a tool call fails.
+type OnToolErrorCallback func(ctx tool.Context, tool tool.Tool, args map[string]any, err error) (map[string]any, error)
+
+// Plugin is a set of lifecycle callbacks registered with a runner.
+type Plugin struct {
+	cfg Config
+}
+
+// New creates a Plugin.
+func New(cfg Config) (*Plugin, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("plugin name is required")
+	}
+	return &Plugin{cfg: cfg}, nil
+}
+
+// Name returns the name of the plugin.
+func (p *Plugin) Name() string { return p.cfg.Name }
+
+// OnUserMessageCallback returns the OnUserMessageCallback of the plugin.
+func (p *Plugin) OnUserMessageCallback() OnUserMessageCallback { return p.cfg.OnUserMessageCallback }
+
+// BeforeRunCallback returns the BeforeRunCallback of the plugin.
+func (p *Plugin) BeforeRunCallback() BeforeRunCallback { return p.cfg.BeforeRunCallback }
+
+// AfterRunCallback returns the AfterRunCallback of the plugin.
+func (p *Plugin) AfterRunCallback() AfterRunCallback { return p.cfg.AfterRunCallback }
+
+// OnEventCallback returns the OnEventCallback of the plugin.
+func (p *Plugin) OnEventCallback() OnEventCallback { return p.cfg.OnEventCallback }
+
+// BeforeAgentCallback returns the BeforeAgentCallback of the plugin.
+func (p *Plugin) BeforeAgentCallback() agent.BeforeAgentCallback { return p.cfg.BeforeAgentCallback }
+
+// AfterAgentCallback returns the AfterAgentCallback of the plugin.
+func (p *Plugin) AfterAgentCallback() agent.AfterAgentCallback { return p.cfg.AfterAgentCallback }
+
+// BeforeModelCallback returns the BeforeModelCallback of the plugin.
+func (p *Plugin) BeforeModelCallback() BeforeModelCallback { return p.cfg.BeforeModelCallback }
+
+// AfterModelCallback returns the AfterModelCallback of the plugin.
+func (p *Plugin) AfterModelCallback() AfterModelCallback { return p.cfg.AfterModelCallback }
+
+// OnModelErrorCallback returns the OnModelErrorCallback of the plugin.
+func (p *Plugin) OnModelErrorCallback() OnModelErrorCallback { return p.cfg.OnModelErrorCallback }
+
+// BeforeToolCallback returns the BeforeToolCallback of the plugin.
+func (p *Plugin) BeforeToolCallback() BeforeToolCallback { return p.cfg.BeforeToolCallback }
+
+// AfterToolCallback returns the AfterToolCallback of the plugin.
+func (p *Plugin) AfterToolCallback() AfterToolCallback { return p.cfg.AfterToolCallback }
+
+// OnToolErrorCallback returns the OnToolErrorCallback of the plugin.
+func (p *Plugin) OnToolErrorCallback() OnToolErrorCallback { return p.cfg.OnToolErrorCallback }
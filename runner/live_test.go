@@ -0,0 +1,284 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+)
+
+// fakeLiveModel replays a scripted turn for each content it receives over a
+// live connection.
+type fakeLiveModel struct {
+	mu       sync.Mutex
+	turns    [][]*model.LLMResponse
+	received []*model.LiveRequest
+}
+
+func (m *fakeLiveModel) Name() string { return "fake-live" }
+
+func (m *fakeLiveModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(nil, fmt.Errorf("not supported"))
+	}
+}
+
+func (m *fakeLiveModel) ConnectLive(ctx context.Context, req *model.LLMRequest) (model.LiveConnection, error) {
+	return &fakeLiveConnection{
+		model:     m,
+		responses: make(chan *model.LLMResponse, 100),
+		done:      make(chan struct{}),
+	}, nil
+}
+
+type fakeLiveConnection struct {
+	model     *fakeLiveModel
+	responses chan *model.LLMResponse
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *fakeLiveConnection) Send(ctx context.Context, req *model.LiveRequest) error {
+	m := c.model
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.received = append(m.received, req)
+	if req.Content == nil {
+		return nil
+	}
+	if len(m.turns) == 0 {
+		return fmt.Errorf("no more scripted turns")
+	}
+	for _, resp := range m.turns[0] {
+		c.responses <- resp
+	}
+	m.turns = m.turns[1:]
+	return nil
+}
+
+func (c *fakeLiveConnection) Receive(ctx context.Context) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for {
+			select {
+			case resp := <-c.responses:
+				if !yield(resp, nil) {
+					return
+				}
+			case <-c.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (c *fakeLiveConnection) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+	return nil
+}
+
+func TestRunner_RunLive(t *testing.T) {
+	ctx := t.Context()
+	appName, userID, sessionID := "testApp", "testUser", "testSession"
+
+	type Args struct {
+		City string `json:"city"`
+	}
+	weatherTool, err := functiontool.New(functiontool.Config{
+		Name:        "get_weather",
+		Description: "returns the weather in a city",
+	}, func(ctx tool.Context, args Args) map[string]string {
+		return map[string]string{"weather": "sunny in " + args.City}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeModel := &fakeLiveModel{
+		turns: [][]*model.LLMResponse{
+			// User asks for the weather, the model calls the tool.
+			{
+				{Content: genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Paris"}, genai.RoleModel)},
+			},
+			// The tool response is sent back, the model streams the answer.
+			{
+				{Content: genai.NewContentFromText("It is", genai.RoleModel), Partial: true},
+				{Content: genai.NewContentFromText(" sunny", genai.RoleModel), Partial: true},
+				{Content: genai.NewContentFromText("It is sunny", genai.RoleModel)},
+				{TurnComplete: true},
+			},
+			// The user interrupts the next answer.
+			{
+				{Content: genai.NewContentFromText("Once upon", genai.RoleModel), Partial: true},
+				{Interrupted: true},
+				{TurnComplete: true},
+			},
+		},
+	}
+
+	a := must(llmagent.New(llmagent.Config{
+		Name:  "live_agent",
+		Model: fakeModel,
+		Tools: []tool.Tool{weatherTool},
+	}))
+
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: appName, UserID: userID, SessionID: sessionID}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(Config{AppName: appName, Agent: a, SessionService: sessionService})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue := agent.NewLiveRequestQueue()
+	queue.SendContent(genai.NewContentFromText("What's the weather in Paris?", genai.RoleUser))
+
+	type got struct {
+		Author      string
+		Text        string
+		Call        string
+		Response    string
+		Partial     bool
+		Interrupted bool
+		Complete    bool
+	}
+	var gotEvents []got
+	turns := 0
+	for ev, err := range r.RunLive(ctx, userID, sessionID, queue, agent.RunConfig{}) {
+		if err != nil {
+			t.Fatalf("RunLive() error = %v", err)
+		}
+		g := got{Author: ev.Author, Partial: ev.Partial, Interrupted: ev.Interrupted, Complete: ev.TurnComplete}
+		if ev.Content != nil {
+			for _, p := range ev.Content.Parts {
+				g.Text += p.Text
+				if p.FunctionCall != nil {
+					g.Call = p.FunctionCall.Name
+				}
+				if p.FunctionResponse != nil {
+					g.Response = fmt.Sprint(p.FunctionResponse.Response)
+				}
+			}
+		}
+		gotEvents = append(gotEvents, g)
+
+		if ev.TurnComplete {
+			turns++
+			switch turns {
+			case 1:
+				queue.SendContent(genai.NewContentFromText("Tell me a story", genai.RoleUser))
+				queue.SendRealtime(&genai.Blob{MIMEType: "audio/pcm", Data: []byte("stop")})
+			case 2:
+				queue.Close()
+			}
+		}
+	}
+
+	wantEvents := []got{
+		{Author: "user", Text: "What's the weather in Paris?"},
+		{Author: "live_agent", Call: "get_weather"},
+		{Author: "live_agent", Response: "map[weather:sunny in Paris]"},
+		{Author: "live_agent", Text: "It is", Partial: true},
+		{Author: "live_agent", Text: " sunny", Partial: true},
+		{Author: "live_agent", Text: "It is sunny"},
+		{Author: "live_agent", Complete: true},
+		{Author: "user", Text: "Tell me a story"},
+		{Author: "live_agent", Text: "Once upon", Partial: true},
+		{Author: "live_agent", Interrupted: true},
+		{Author: "live_agent", Complete: true},
+	}
+	if diff := cmp.Diff(wantEvents, gotEvents); diff != "" {
+		t.Errorf("RunLive() events mismatch (-want +got):\n%s", diff)
+	}
+
+	fakeModel.mu.Lock()
+	var gotBlobs int
+	for _, req := range fakeModel.received {
+		if req.Blob != nil {
+			gotBlobs++
+		}
+	}
+	fakeModel.mu.Unlock()
+	if gotBlobs != 1 {
+		t.Errorf("model received %d blobs, want 1", gotBlobs)
+	}
+
+	resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: appName, UserID: userID, SessionID: sessionID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored int
+	for ev := range resp.Session.Events().All() {
+		if ev.Partial {
+			t.Errorf("partial event stored in session: %v", ev)
+		}
+		stored++
+	}
+	if want := 8; stored != want {
+		t.Errorf("session has %d events, want %d", stored, want)
+	}
+}
+
+func TestRunner_RunLive_UnsupportedModel(t *testing.T) {
+	ctx := t.Context()
+	appName, userID, sessionID := "testApp", "testUser", "testSession"
+
+	a := must(llmagent.New(llmagent.Config{
+		Name:  "agent",
+		Model: &nonLiveModel{},
+	}))
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: appName, UserID: userID, SessionID: sessionID}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(Config{AppName: appName, Agent: a, SessionService: sessionService})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue := agent.NewLiveRequestQueue()
+	queue.Close()
+	var gotErr error
+	for _, err := range r.RunLive(ctx, userID, sessionID, queue, agent.RunConfig{}) {
+		if err != nil {
+			gotErr = err
+		}
+	}
+	if gotErr == nil {
+		t.Error("RunLive() with a model without live support succeeded, want error")
+	}
+}
+
+type nonLiveModel struct{}
+
+func (m *nonLiveModel) Name() string { return "non-live" }
+
+func (m *nonLiveModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {}
+}
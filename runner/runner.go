@@ -20,19 +20,26 @@ import (
 	"fmt"
 	"iter"
 	"log"
+	"slices"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/agent/checkpoint"
 	"google.golang.org/adk/internal/agent/parentmap"
 	"google.golang.org/adk/internal/agent/runconfig"
 	artifactinternal "google.golang.org/adk/internal/artifact"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/llminternal"
 	imemory "google.golang.org/adk/internal/memory"
+	"google.golang.org/adk/internal/plugininternal"
 	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/compaction"
+	"google.golang.org/adk/usage"
 	"google.golang.org/genai"
 )
 
@@ -47,6 +54,26 @@ type Config struct {
 	ArtifactService artifact.Service
 	// optional
 	MemoryService memory.Service
+
+	// Resumable makes the workflow agents record their progress in the
+	// session events, so that an interrupted invocation can be continued
+	// with [Runner.Resume].
+	Resumable bool
+
+	// Compaction enables the compaction of the session events: once due, the
+	// older events are summarized after an invocation. Optional.
+	Compaction *compaction.Config
+
+	// Usage enables the usage accounting: once an invocation completed, a
+	// summary event reports the tokens used by its model calls, which are
+	// added to the totals of the session and of the user. Optional.
+	Usage *usage.Config
+
+	// Plugins are the lifecycle callbacks applied to the whole agent tree,
+	// called in their order, before the callbacks of the agents. See the
+	// plugin package. The runners without plugins, e.g. the ones of agent
+	// tools, apply the plugins of the invocation running them. Optional.
+	Plugins []*plugin.Plugin
 }
 
 // New creates a new [Runner].
@@ -59,6 +86,17 @@ func New(cfg Config) (*Runner, error) {
 		return nil, fmt.Errorf("session service is required")
 	}
 
+	if cfg.Compaction != nil {
+		if err := cfg.Compaction.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid compaction config: %w", err)
+		}
+	}
+
+	plugins, err := plugininternal.NewManager(cfg.Plugins)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugins: %w", err)
+	}
+
 	parents, err := parentmap.New(cfg.Agent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create agent tree: %w", err)
@@ -70,6 +108,10 @@ func New(cfg Config) (*Runner, error) {
 		sessionService:  cfg.SessionService,
 		artifactService: cfg.ArtifactService,
 		memoryService:   cfg.MemoryService,
+		resumable:       cfg.Resumable,
+		compaction:      cfg.Compaction,
+		usage:           cfg.Usage,
+		plugins:         plugins,
 		parents:         parents,
 	}, nil
 }
@@ -83,6 +125,10 @@ type Runner struct {
 	sessionService  session.Service
 	artifactService artifact.Service
 	memoryService   memory.Service
+	resumable       bool
+	compaction      *compaction.Config
+	usage           *usage.Config
+	plugins         *plugininternal.Manager
 
 	parents parentmap.Map
 }
@@ -90,89 +136,433 @@ type Runner struct {
 // Run runs the agent for the given user input, yielding events from agents.
 // For each user message it finds the proper agent within an agent tree to
 // continue the conversation within the session.
+//
+// If the invocation exceeds one of the limits of cfg, e.g. MaxLLMCalls, the
+// last event has the corresponding agent.ErrorCode*Exceeded error code.
 func (r *Runner) Run(ctx context.Context, userID, sessionID string, msg *genai.Content, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
 	// TODO(hakim): we need to validate whether cfg is compatible with the Agent.
 	//   see adk-python/src/google/adk/runners.py Runner._new_invocation_context.
 	// TODO: setup tracer.
 	return func(yield func(*session.Event, error) bool) {
-		resp, err := r.sessionService.Get(ctx, &session.GetRequest{
-			AppName:   r.appName,
-			UserID:    userID,
-			SessionID: sessionID,
-		})
+		runCtx, cancel := runconfig.WithDeadline(ctx, &cfg)
+		defer cancel()
+
+		session, err := r.getSession(runCtx, userID, sessionID)
 		if err != nil {
 			yield(nil, err)
 			return
 		}
 
-		session := resp.Session
-
-		agentToRun, err := r.findAgentToRun(session)
+		agentToRun, err := r.findAgentToRun(session, msg)
 		if err != nil {
 			yield(nil, err)
 			return
 		}
 
-		ctx = parentmap.ToContext(ctx, r.parents)
-		ctx = runconfig.ToContext(ctx, &runconfig.RunConfig{
+		var checkpoints *checkpoint.State
+		if r.resumable {
+			checkpoints = checkpoint.New()
+		}
+		ctx := r.newInvocationContext(checkpoint.ToContext(runCtx, checkpoints), session, agentToRun, "", msg, &cfg, &runconfig.RunConfig{
 			StreamingMode: runconfig.StreamingMode(cfg.StreamingMode),
+			Limits:        runconfig.NewLimits(parentLimits(runCtx), &cfg),
 		})
 
-		var artifacts agent.Artifacts
-		if r.artifactService != nil {
-			artifacts = &artifactinternal.Artifacts{
-				Service:   r.artifactService,
-				SessionID: session.ID(),
-				AppName:   session.AppName(),
-				UserID:    session.UserID(),
-			}
+		modifiedMsg, err := plugininternal.FromContext(ctx).OnUserMessage(ctx, msg)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if modifiedMsg != nil {
+			msg = modifiedMsg
+			ctx = r.newInvocationContext(ctx, session, agentToRun, ctx.InvocationID(), msg, &cfg, runconfig.FromContext(ctx))
 		}
 
-		var memoryImpl agent.Memory = nil
-		if r.memoryService != nil {
-			memoryImpl = &imemory.Memory{
-				Service:   r.memoryService,
-				SessionID: session.ID(),
-				UserID:    session.UserID(),
-				AppName:   session.AppName(),
-			}
+		if err := r.appendMessageToSession(ctx, session, msg, cfg.SaveInputBlobsAsArtifacts); err != nil {
+			yield(nil, err)
+			return
 		}
 
-		ctx := icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
-			Artifacts:   artifacts,
-			Memory:      memoryImpl,
-			Session:     sessioninternal.NewMutableSession(r.sessionService, session),
-			Agent:       agentToRun,
-			UserContent: msg,
-			RunConfig:   &cfg,
+		if r.runAgent(ctx, session, agentToRun, r.newUsageReport(nil), yield) {
+			r.compactEvents(ctx, session)
+		}
+	}
+}
+
+// RunLive runs the agent in bidirectional streaming mode.
+//
+// User content and realtime blobs (e.g. audio or video chunks) sent to the
+// queue are forwarded to the model as they arrive, and events are yielded as
+// soon as the model produces them. User content is recorded in the session
+// as user events; realtime blobs are not. Events with the Interrupted flag
+// signal that the model stopped generating because of new user input.
+//
+// The run ends when the queue is closed, ctx is cancelled, or an error
+// occurs. The agent must use a model implementing [model.LiveLLM].
+func (r *Runner) RunLive(ctx context.Context, userID, sessionID string, queue *agent.LiveRequestQueue, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		if queue == nil {
+			yield(nil, fmt.Errorf("live request queue is required"))
+			return
+		}
+		runCtx, cancel := runconfig.WithDeadline(ctx, &cfg)
+		defer cancel()
+
+		session, err := r.getSession(runCtx, userID, sessionID)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		agentToRun, err := r.findAgentToRun(session, nil)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		cfg.StreamingMode = agent.StreamingModeBidi
+		ctx := r.newInvocationContext(checkpoint.ToContext(runCtx, nil), session, agentToRun, "", nil, &cfg, &runconfig.RunConfig{
+			StreamingMode:    runconfig.StreamingModeBidi,
+			LiveRequestQueue: queue,
+			Limits:           runconfig.NewLimits(parentLimits(runCtx), &cfg),
 		})
 
-		if err := r.appendMessageToSession(ctx, session, msg, cfg.SaveInputBlobsAsArtifacts); err != nil {
+		r.runAgent(ctx, session, agentToRun, r.newUsageReport(nil), yield)
+	}
+}
+
+// Resume continues the invocation of the session with the given ID, e.g.
+// after the process running it stopped, yielding the events of the agents
+// from where it was interrupted.
+//
+// The workflow agents continue from their last checkpoint, i.e. the
+// sub-agent a sequential or loop agent was running, in the same loop
+// iteration, and the sub-agents of a parallel agent that did not complete.
+// The function calls left without response are called again before calling
+// the model. Resuming a completed invocation yields no event.
+//
+// The runner must be [Config.Resumable], and so must have been the one that
+// started the invocation. The limits of cfg, e.g. MaxLLMCalls, apply to the
+// resumed run, as with Run.
+func (r *Runner) Resume(ctx context.Context, userID, sessionID, invocationID string, cfg agent.RunConfig) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		if !r.resumable {
+			yield(nil, fmt.Errorf("runner is not resumable"))
+			return
+		}
+		runCtx, cancel := runconfig.WithDeadline(ctx, &cfg)
+		defer cancel()
+
+		storedSession, err := r.getSession(runCtx, userID, sessionID)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		var events []*session.Event
+		for event := range storedSession.Events().All() {
+			if event.InvocationID == invocationID && event.Actions.Compaction == nil {
+				events = append(events, event)
+			}
+		}
+		if len(events) == 0 {
+			yield(nil, fmt.Errorf("invocation %q not found in session %q", invocationID, sessionID))
+			return
+		}
+
+		agentToRun, msg, err := r.findAgentToResume(storedSession, events)
+		if err != nil {
 			yield(nil, err)
 			return
 		}
+		if invocationCompleted(agentToRun, events) {
+			return
+		}
+
+		ctx := r.newInvocationContext(checkpoint.ToContext(runCtx, checkpoint.FromEvents(events)), storedSession, agentToRun, invocationID, msg, &cfg, &runconfig.RunConfig{
+			StreamingMode: runconfig.StreamingMode(cfg.StreamingMode),
+			Limits:        runconfig.NewLimits(parentLimits(runCtx), &cfg),
+		})
+
+		// The usage of the interrupted run is reported with the resumed one.
+		if r.runAgent(ctx, storedSession, agentToRun, r.newUsageReport(events), yield) {
+			r.compactEvents(ctx, storedSession)
+		}
+	}
+}
+
+// findAgentToResume returns the agent that ran the invocation, i.e. the
+// author of its first agent event, and the user message that started it.
+func (r *Runner) findAgentToResume(storedSession session.Session, events []*session.Event) (agent.Agent, *genai.Content, error) {
+	var msg *genai.Content
+	for _, event := range events {
+		if event.Author == "user" {
+			if msg == nil {
+				msg = event.Content
+			}
+			continue
+		}
+		agentToRun := findAgent(r.rootAgent, event.Author)
+		if agentToRun == nil {
+			return nil, nil, fmt.Errorf("invocation %q was run by an unknown agent: %s", event.InvocationID, event.Author)
+		}
+		return agentToRun, msg, nil
+	}
+	// The invocation was interrupted before any agent event.
+	agentToRun, err := r.findAgentToRun(storedSession, msg)
+	return agentToRun, msg, err
+}
+
+// invocationCompleted reports whether the agent completed the invocation.
+// Workflow agents record their end; for the other agents, the invocation is
+// completed once the last event is a final response.
+func invocationCompleted(agentToRun agent.Agent, events []*session.Event) bool {
+	for _, event := range events {
+		if event.Author == agentToRun.Name() && event.Actions.EndOfAgent {
+			return true
+		}
+	}
+	if _, ok := agentToRun.(llminternal.Agent); !ok {
+		return false
+	}
+	last := events[len(events)-1]
+	return last.Author != "user" && last.Actions.AgentState == nil && !last.Actions.EndOfAgent && last.IsFinalResponse()
+}
+
+// parentLimits returns the invocation limits of the invocation running this
+// one, e.g. through an agent tool, if any.
+func parentLimits(ctx context.Context) *runconfig.Limits {
+	if cfg := runconfig.FromContext(ctx); cfg != nil {
+		return cfg.Limits
+	}
+	return nil
+}
+
+func (r *Runner) getSession(ctx context.Context, userID, sessionID string) (session.Session, error) {
+	resp, err := r.sessionService.Get(ctx, &session.GetRequest{
+		AppName:   r.appName,
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Session, nil
+}
+
+func (r *Runner) newInvocationContext(ctx context.Context, storedSession session.Session, agentToRun agent.Agent, invocationID string, msg *genai.Content, cfg *agent.RunConfig, rcfg *runconfig.RunConfig) agent.InvocationContext {
+	ctx = parentmap.ToContext(ctx, r.parents)
+	ctx = runconfig.ToContext(ctx, rcfg)
+	// Without plugins of its own, the runner applies the ones of the
+	// invocation running it, if any.
+	if r.plugins != nil {
+		ctx = plugininternal.ToContext(ctx, r.plugins)
+	}
+
+	var artifacts agent.Artifacts
+	if r.artifactService != nil {
+		artifacts = &artifactinternal.Artifacts{
+			Service:   r.artifactService,
+			SessionID: storedSession.ID(),
+			AppName:   storedSession.AppName(),
+			UserID:    storedSession.UserID(),
+		}
+	}
+
+	var memoryImpl agent.Memory = nil
+	if r.memoryService != nil {
+		memoryImpl = &imemory.Memory{
+			Service:   r.memoryService,
+			SessionID: storedSession.ID(),
+			UserID:    storedSession.UserID(),
+			AppName:   storedSession.AppName(),
+		}
+	}
 
-		for event, err := range agentToRun.Run(ctx) {
-			if err != nil {
+	return icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
+		InvocationID: invocationID,
+		Artifacts:    artifacts,
+		Memory:       memoryImpl,
+		Session:      sessioninternal.NewMutableSession(r.sessionService, storedSession),
+		Agent:        agentToRun,
+		UserContent:  msg,
+		RunConfig:    cfg,
+	})
+}
+
+// runAgent runs the agent, committing non-partial events to the session
+// before forwarding them. It reports whether the agent completed its run.
+//
+// The usage of the model calls is added to report, if not nil, and reported
+// once the agent completed its run.
+//
+// The run and event callbacks of the plugins are called around the run of
+// the agent and with its events.
+func (r *Runner) runAgent(ctx agent.InvocationContext, storedSession session.Session, agentToRun agent.Agent, report *usage.Report, yield func(*session.Event, error) bool) bool {
+	plugins := plugininternal.FromContext(ctx)
+	defer plugins.AfterRun(ctx)
+
+	content, err := plugins.BeforeRun(ctx)
+	if err != nil {
+		yield(nil, err)
+		return false
+	}
+	if content != nil {
+		// A plugin responded in place of the agent.
+		event := session.NewEvent(ctx.InvocationID())
+		event.Author = agentToRun.Name()
+		event.Branch = ctx.Branch()
+		event.LLMResponse = model.LLMResponse{Content: content}
+		if err := r.sessionService.AppendEvent(ctx, storedSession, event); err != nil {
+			yield(nil, fmt.Errorf("failed to add event to session: %w", err))
+			return false
+		}
+		return yield(event, nil)
+	}
+
+	lastAuthor := agentToRun.Name()
+	for event, err := range agentToRun.Run(ctx) {
+		if err != nil {
+			limitErr := runconfig.LimitExceeded(ctx, err)
+			if limitErr == nil {
 				if !yield(event, err) {
-					return
+					return false
 				}
 				continue
 			}
+			// The agent did not report the exceeded limit itself.
+			event = session.NewEvent(ctx.InvocationID())
+			event.Author = agentToRun.Name()
+			event.LLMResponse = model.LLMResponse{
+				ErrorCode:    limitErr.Code,
+				ErrorMessage: limitErr.Message,
+			}
+		}
 
-			// only commit non-partial event to a session service
-			if !event.LLMResponse.Partial {
-				if err := r.sessionService.AppendEvent(ctx, session, event); err != nil {
-					yield(nil, fmt.Errorf("failed to add event to session: %w", err))
-					return
-				}
+		modified, err := plugins.OnEvent(ctx, event)
+		if err != nil {
+			yield(nil, err)
+			return false
+		}
+		if modified != nil {
+			event = modified
+		}
+
+		// only commit non-partial event to a session service
+		if !event.LLMResponse.Partial {
+			// The invocation context is cancelled once MaxDuration elapses,
+			// the event reporting it must still be committed.
+			if err := r.sessionService.AppendEvent(context.WithoutCancel(ctx), storedSession, event); err != nil {
+				yield(nil, fmt.Errorf("failed to add event to session: %w", err))
+				return false
+			}
+			r.recordUsage(report, event)
+			if event.Author != "user" {
+				lastAuthor = event.Author
 			}
+		}
+
+		if !yield(event, nil) {
+			return false
+		}
+		// Exceeding an invocation limit ends the invocation.
+		if isLimitExceeded(event) {
+			return false
+		}
+	}
+	return r.reportUsage(ctx, storedSession, lastAuthor, report, yield)
+}
+
+// newUsageReport returns the usage report of an invocation with the given
+// events so far, or nil if the usage accounting is disabled.
+func (r *Runner) newUsageReport(events []*session.Event) *usage.Report {
+	if r.usage == nil {
+		return nil
+	}
+	report := &usage.Report{}
+	for _, event := range events {
+		r.recordUsage(report, event)
+	}
+	return report
+}
 
-			if !yield(event, nil) {
-				return
+// recordUsage adds the usage of the model call of the event, if any, to the
+// report. The call is attributed to the model that served it, if the
+// response names it, otherwise to the model of the agent.
+func (r *Runner) recordUsage(report *usage.Report, event *session.Event) {
+	if report == nil || event.UsageMetadata == nil || event.Partial {
+		return
+	}
+	modelName, _ := event.CustomMetadata[model.ServedModelMetadataKey].(string)
+	if modelName == "" {
+		if llmAgent, ok := findAgent(r.rootAgent, event.Author).(llminternal.Agent); ok {
+			if m := llminternal.Reveal(llmAgent).Model; m != nil {
+				modelName = m.Name()
 			}
 		}
 	}
+	report.Record(event.Author, modelName, event.UsageMetadata, r.usage.Pricing)
+}
+
+// reportUsage yields the usage summary event of the invocation, which adds
+// its usage to the totals of the session and of the user. The event is
+// authored by the agent of the last event, so that it does not change the
+// agent handling the next message. It reports whether the consumer is still
+// listening.
+//
+// The totals are read from the session fetched at the start of the
+// invocation: concurrent invocations of the same user may overwrite each
+// other's addition to the user total.
+func (r *Runner) reportUsage(ctx agent.InvocationContext, storedSession session.Session, author string, report *usage.Report, yield func(*session.Event, error) bool) bool {
+	if report == nil || report.Total.LLMCalls == 0 {
+		return true
+	}
+	delta := make(map[string]any)
+	for _, key := range []string{usage.SessionStateKey, usage.UserStateKey} {
+		stored, err := usage.FromState(storedSession.State(), key)
+		if err != nil {
+			log.Printf("Failed to read the usage of session %s, resetting it: %v", storedSession.ID(), err)
+			stored = &usage.Report{}
+		}
+		total := &usage.Report{}
+		total.Merge(stored)
+		total.Merge(report)
+		delta[key] = *total
+	}
+
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = author
+	event.Branch = ctx.Branch()
+	event.CustomMetadata = map[string]any{usage.MetadataKey: *report}
+	event.Actions.StateDelta = delta
+	if err := r.sessionService.AppendEvent(context.WithoutCancel(ctx), storedSession, event); err != nil {
+		return yield(nil, fmt.Errorf("failed to add event to session: %w", err))
+	}
+	return yield(event, nil)
+}
+
+// compactEvents appends the compaction of the session events to the session
+// if it is due. Compaction failures are logged: the invocation itself
+// succeeded.
+func (r *Runner) compactEvents(ctx agent.InvocationContext, storedSession session.Session) {
+	if r.compaction == nil {
+		return
+	}
+	event, err := compaction.Compact(ctx, r.compaction, slices.Collect(storedSession.Events().All()), ctx.InvocationID())
+	if err == nil && event != nil {
+		err = r.sessionService.AppendEvent(ctx, storedSession, event)
+	}
+	if err != nil {
+		log.Printf("Failed to compact the events of session %s: %v", storedSession.ID(), err)
+	}
+}
+
+func isLimitExceeded(event *session.Event) bool {
+	switch event.LLMResponse.ErrorCode {
+	case agent.ErrorCodeMaxLLMCallsExceeded, agent.ErrorCodeMaxToolCallsExceeded, agent.ErrorCodeMaxDurationExceeded:
+		return true
+	}
+	return false
 }
 
 func (r *Runner) appendMessageToSession(ctx agent.InvocationContext, storedSession session.Session, msg *genai.Content, saveInputBlobsAsArtifacts bool) error {
@@ -211,14 +601,23 @@ func (r *Runner) appendMessageToSession(ctx agent.InvocationContext, storedSessi
 }
 
 // findAgentToRun returns the agent that should handle the next request based on
-// session history.
-func (r *Runner) findAgentToRun(session session.Session) (agent.Agent, error) {
+// session history and the new message.
+func (r *Runner) findAgentToRun(session session.Session, msg *genai.Content) (agent.Agent, error) {
 	events := session.Events()
+
+	// A function response, e.g. the result of a long-running tool, is sent
+	// back to the agent that issued the function call, wherever it is in the
+	// tree and whether or not it allows transfers.
+	if event := findMatchingFunctionCall(events, msg); event != nil {
+		if subAgent := findAgent(r.rootAgent, event.Author); subAgent != nil {
+			return subAgent, nil
+		}
+		log.Printf("Function call from an unknown agent: %s, event id: %s", event.Author, event.ID)
+	}
+
 	for i := events.Len() - 1; i >= 0; i-- {
 		event := events.At(i)
 
-		// TODO: findMatchingFunctionCall.
-
 		if event.Author == "user" {
 			continue
 		}
@@ -239,6 +638,27 @@ func (r *Runner) findAgentToRun(session session.Session) (agent.Agent, error) {
 	return r.rootAgent, nil
 }
 
+// findMatchingFunctionCall returns the event holding the function call the
+// message responds to, or nil if the message holds no function response.
+func findMatchingFunctionCall(events session.Events, msg *genai.Content) *session.Event {
+	ids := make(map[string]bool)
+	for _, resp := range utils.FunctionResponses(msg) {
+		ids[resp.ID] = true
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	for i := events.Len() - 1; i >= 0; i-- {
+		event := events.At(i)
+		for _, call := range utils.FunctionCalls(event.LLMResponse.Content) {
+			if ids[call.ID] {
+				return event
+			}
+		}
+	}
+	return nil
+}
+
 // checks if the agent and its parent chain allow transfer up the tree.
 func (r *Runner) isTransferableAcrossAgentTree(agentToRun agent.Agent) bool {
 	for curAgent := agentToRun; curAgent != nil; curAgent = r.parents[curAgent.Name()] {
@@ -25,6 +25,7 @@ import (
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 	"google.golang.org/genai"
 )
@@ -40,6 +41,7 @@ func TestRunner_findAgentToRun(t *testing.T) {
 		name      string
 		rootAgent agent.Agent
 		session   session.Session
+		msg       *genai.Content
 		wantAgent agent.Agent
 		wantErr   bool
 	}{
@@ -69,6 +71,41 @@ func TestRunner_findAgentToRun(t *testing.T) {
 			rootAgent: agentTree.root,
 			wantAgent: agentTree.root,
 		},
+		{
+			name: "function response to agent not allowing transfer",
+			session: createSession(t, t.Context(), appName, userID, sessionID, []*session.Event{
+				{
+					Author: "no_transfer_agent",
+					LLMResponse: model.LLMResponse{
+						Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+							{FunctionCall: &genai.FunctionCall{ID: "call-1", Name: "approve"}},
+						}},
+					},
+					LongRunningToolIDs: []string{"call-1"},
+				},
+				{
+					Author: "allows_transfer_agent",
+				},
+			}),
+			msg: &genai.Content{Role: genai.RoleUser, Parts: []*genai.Part{
+				{FunctionResponse: &genai.FunctionResponse{ID: "call-1", Name: "approve"}},
+			}},
+			rootAgent: agentTree.root,
+			wantAgent: agentTree.noTransferAgent,
+		},
+		{
+			name: "function response without matching call",
+			session: createSession(t, t.Context(), appName, userID, sessionID, []*session.Event{
+				{
+					Author: "allows_transfer_agent",
+				},
+			}),
+			msg: &genai.Content{Role: genai.RoleUser, Parts: []*genai.Part{
+				{FunctionResponse: &genai.FunctionResponse{ID: "unknown", Name: "approve"}},
+			}},
+			rootAgent: agentTree.root,
+			wantAgent: agentTree.allowsTransferAgent,
+		},
 		{
 			name: "no events from agents, call root",
 			session: createSession(t, t.Context(), appName, userID, sessionID, []*session.Event{
@@ -86,7 +123,7 @@ func TestRunner_findAgentToRun(t *testing.T) {
 			r := &Runner{
 				rootAgent: tt.rootAgent,
 			}
-			gotAgent, err := r.findAgentToRun(tt.session)
+			gotAgent, err := r.findAgentToRun(tt.session, tt.msg)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Runner.findAgentToRun() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -369,3 +406,51 @@ func createSession(t *testing.T, ctx context.Context, sessionID, appName, userID
 
 	return resp.Session
 }
+
+func TestRunner_Resume(t *testing.T) {
+	ctx := t.Context()
+	sessionService := session.InMemoryService()
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "session"}); err != nil {
+		t.Fatalf("sessionService.Create() error = %v", err)
+	}
+	testAgent := must(agent.New(agent.Config{
+		Name: "test_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {}
+		},
+	}))
+
+	tests := []struct {
+		name         string
+		resumable    bool
+		invocationID string
+		wantErr      string
+	}{
+		{
+			name:         "runner not resumable",
+			invocationID: "e-1",
+			wantErr:      "runner is not resumable",
+		},
+		{
+			name:         "unknown invocation",
+			resumable:    true,
+			invocationID: "e-unknown",
+			wantErr:      `invocation "e-unknown" not found`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := New(Config{AppName: "app", Agent: testAgent, SessionService: sessionService, Resumable: tc.resumable})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			var gotErr error
+			for _, err := range r.Resume(ctx, "user", "session", tc.invocationID, agent.RunConfig{}) {
+				gotErr = err
+			}
+			if gotErr == nil || !strings.Contains(gotErr.Error(), tc.wantErr) {
+				t.Errorf("Resume() error = %v, want %q", gotErr, tc.wantErr)
+			}
+		})
+	}
+}
@@ -13,3 +13,282 @@
 // limitations under the License.
 
 package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/server/restapi/errors"
+	"google.golang.org/adk/server/restapi/models"
+	"google.golang.org/adk/server/restapi/services"
+	"google.golang.org/adk/session"
+)
+
+// evalSetIDPattern restricts the eval set IDs to names valid in every
+// eval.Store.
+var evalSetIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// EvalAPIController is the controller for the Eval API.
+type EvalAPIController struct {
+	sessionService  session.Service
+	agentLoader     services.AgentLoader
+	artifactService artifact.Service
+	memoryService   memory.Service
+	store           eval.Store
+}
+
+// NewEvalAPIController creates a new EvalAPIController. The eval cases run
+// in sessions of sessionService, with the agents of agentLoader using
+// artifactService and memoryService, both optional, and the eval sets and
+// results are persisted in store.
+func NewEvalAPIController(sessionService session.Service, agentLoader services.AgentLoader, artifactService artifact.Service, memoryService memory.Service, store eval.Store) *EvalAPIController {
+	return &EvalAPIController{
+		sessionService:  sessionService,
+		agentLoader:     agentLoader,
+		artifactService: artifactService,
+		memoryService:   memoryService,
+		store:           store,
+	}
+}
+
+// CreateEvalSetHTTP creates an empty eval set.
+func (c *EvalAPIController) CreateEvalSetHTTP(rw http.ResponseWriter, req *http.Request) error {
+	params := mux.Vars(req)
+	appName, evalSetID := params["app_name"], params["eval_set_id"]
+	if !evalSetIDPattern.MatchString(evalSetID) {
+		return errors.NewStatusError(fmt.Errorf("invalid eval set ID %q, it should only contain letters, digits and underscores", evalSetID), http.StatusBadRequest)
+	}
+	existing, err := c.store.GetEvalSet(req.Context(), appName, evalSetID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errors.NewStatusError(fmt.Errorf("eval set %q already exists", evalSetID), http.StatusBadRequest)
+	}
+	set := &eval.EvalSet{
+		EvalSetID:         evalSetID,
+		Name:              evalSetID,
+		EvalCases:         []*eval.EvalCase{},
+		CreationTimestamp: float64(time.Now().UnixMilli()) / 1000,
+	}
+	if err := c.store.SaveEvalSet(req.Context(), appName, set); err != nil {
+		return err
+	}
+	EncodeJSONResponse(set, http.StatusOK, rw)
+	return nil
+}
+
+// ListEvalSetsHTTP lists the IDs of the eval sets of an app.
+func (c *EvalAPIController) ListEvalSetsHTTP(rw http.ResponseWriter, req *http.Request) error {
+	ids, err := c.store.ListEvalSets(req.Context(), mux.Vars(req)["app_name"])
+	if err != nil {
+		return err
+	}
+	EncodeJSONResponse(ids, http.StatusOK, rw)
+	return nil
+}
+
+// GetEvalSetHTTP returns an eval set.
+func (c *EvalAPIController) GetEvalSetHTTP(rw http.ResponseWriter, req *http.Request) error {
+	set, err := c.getEvalSet(req)
+	if err != nil {
+		return err
+	}
+	EncodeJSONResponse(set, http.StatusOK, rw)
+	return nil
+}
+
+// ListEvalCasesHTTP lists the IDs of the eval cases of an eval set.
+func (c *EvalAPIController) ListEvalCasesHTTP(rw http.ResponseWriter, req *http.Request) error {
+	set, err := c.getEvalSet(req)
+	if err != nil {
+		return err
+	}
+	ids := []string{}
+	for _, ec := range set.EvalCases {
+		ids = append(ids, ec.EvalID)
+	}
+	EncodeJSONResponse(ids, http.StatusOK, rw)
+	return nil
+}
+
+// GetEvalCaseHTTP returns an eval case of an eval set.
+func (c *EvalAPIController) GetEvalCaseHTTP(rw http.ResponseWriter, req *http.Request) error {
+	set, err := c.getEvalSet(req)
+	if err != nil {
+		return err
+	}
+	evalID := mux.Vars(req)["eval_case_id"]
+	i := slices.IndexFunc(set.EvalCases, func(ec *eval.EvalCase) bool { return ec.EvalID == evalID })
+	if i < 0 {
+		return errors.NewStatusError(fmt.Errorf("eval case %q not found in eval set %q", evalID, set.EvalSetID), http.StatusNotFound)
+	}
+	EncodeJSONResponse(set.EvalCases[i], http.StatusOK, rw)
+	return nil
+}
+
+// AddSessionToEvalSetHTTP adds the conversation of a session as an eval
+// case of an eval set.
+func (c *EvalAPIController) AddSessionToEvalSetHTTP(rw http.ResponseWriter, req *http.Request) error {
+	var addRequest models.AddSessionToEvalSetRequest
+	if err := json.NewDecoder(req.Body).Decode(&addRequest); err != nil {
+		return errors.NewStatusError(fmt.Errorf("decode request: %w", err), http.StatusBadRequest)
+	}
+	if addRequest.EvalID == "" || addRequest.SessionID == "" || addRequest.UserID == "" {
+		return errors.NewStatusError(fmt.Errorf("evalId, sessionId and userId are required"), http.StatusBadRequest)
+	}
+	set, err := c.getEvalSet(req)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(set.EvalCases, func(ec *eval.EvalCase) bool { return ec.EvalID == addRequest.EvalID }) {
+		return errors.NewStatusError(fmt.Errorf("eval case %q already exists in eval set %q", addRequest.EvalID, set.EvalSetID), http.StatusBadRequest)
+	}
+
+	resp, err := c.sessionService.Get(req.Context(), &session.GetRequest{
+		AppName:   mux.Vars(req)["app_name"],
+		UserID:    addRequest.UserID,
+		SessionID: addRequest.SessionID,
+	})
+	if err != nil {
+		return errors.NewStatusError(fmt.Errorf("get session: %w", err), http.StatusNotFound)
+	}
+	evalCase := eval.NewEvalCase(addRequest.EvalID, resp.Session)
+	if len(evalCase.Conversation) == 0 {
+		return errors.NewStatusError(fmt.Errorf("session %q has no user messages", addRequest.SessionID), http.StatusBadRequest)
+	}
+	set.EvalCases = append(set.EvalCases, evalCase)
+	if err := c.store.SaveEvalSet(req.Context(), mux.Vars(req)["app_name"], set); err != nil {
+		return err
+	}
+	EncodeJSONResponse(evalCase, http.StatusOK, rw)
+	return nil
+}
+
+// RunEvalHTTP runs eval cases of an eval set against the agent of the app,
+// saves the result and returns the results of the cases.
+func (c *EvalAPIController) RunEvalHTTP(rw http.ResponseWriter, req *http.Request) error {
+	var runRequest models.RunEvalRequest
+	if err := json.NewDecoder(req.Body).Decode(&runRequest); err != nil {
+		return errors.NewStatusError(fmt.Errorf("decode request: %w", err), http.StatusBadRequest)
+	}
+	criteria, err := runRequest.Criteria()
+	if err != nil {
+		return errors.NewStatusError(err, http.StatusBadRequest)
+	}
+	set, err := c.getEvalSet(req)
+	if err != nil {
+		return err
+	}
+	if len(runRequest.EvalIDs) > 0 {
+		var cases []*eval.EvalCase
+		for _, id := range runRequest.EvalIDs {
+			i := slices.IndexFunc(set.EvalCases, func(ec *eval.EvalCase) bool { return ec.EvalID == id })
+			if i < 0 {
+				return errors.NewStatusError(fmt.Errorf("eval case %q not found in eval set %q", id, set.EvalSetID), http.StatusBadRequest)
+			}
+			cases = append(cases, set.EvalCases[i])
+		}
+		set.EvalCases = cases
+	}
+
+	appName := mux.Vars(req)["app_name"]
+	agent, err := c.agentLoader.LoadAgent(appName)
+	if err != nil {
+		return errors.NewStatusError(fmt.Errorf("load agent: %w", err), http.StatusInternalServerError)
+	}
+	// The eval set is validated first: Evaluate then fails only on server
+	// faults, e.g. when the runner cannot be created.
+	if err := set.Validate(); err != nil {
+		return errors.NewStatusError(fmt.Errorf("invalid eval set: %w", err), http.StatusBadRequest)
+	}
+	result, err := eval.Evaluate(req.Context(), eval.Config{
+		Agent:           agent,
+		AppName:         appName,
+		SessionService:  c.sessionService,
+		ArtifactService: c.artifactService,
+		MemoryService:   c.memoryService,
+		Criteria:        criteria,
+	}, set)
+	if err != nil {
+		return errors.NewStatusError(fmt.Errorf("evaluate: %w", err), http.StatusInternalServerError)
+	}
+	if err := c.store.SaveResult(req.Context(), appName, result); err != nil {
+		return err
+	}
+	EncodeJSONResponse(models.FromEvalResult(result).EvalCaseResults, http.StatusOK, rw)
+	return nil
+}
+
+// ListEvalResultsHTTP lists the IDs of the eval results of an app.
+func (c *EvalAPIController) ListEvalResultsHTTP(rw http.ResponseWriter, req *http.Request) error {
+	ids, err := c.store.ListResults(req.Context(), mux.Vars(req)["app_name"])
+	if err != nil {
+		return err
+	}
+	EncodeJSONResponse(ids, http.StatusOK, rw)
+	return nil
+}
+
+// GetEvalResultHTTP returns an eval result, with the sessions of its eval
+// cases.
+func (c *EvalAPIController) GetEvalResultHTTP(rw http.ResponseWriter, req *http.Request) error {
+	params := mux.Vars(req)
+	appName, resultID := params["app_name"], params["eval_result_id"]
+	stored, err := c.store.GetResult(req.Context(), appName, resultID)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		return errors.NewStatusError(fmt.Errorf("eval result %q not found", resultID), http.StatusNotFound)
+	}
+	result := models.FromEvalResult(stored)
+	for i := range result.EvalCaseResults {
+		result.EvalCaseResults[i].SessionDetails = c.sessionDetails(req.Context(), appName, &result.EvalCaseResults[i])
+	}
+	EncodeJSONResponse(result, http.StatusOK, rw)
+	return nil
+}
+
+// sessionDetails returns the session of the run of the eval case, or nil
+// if it does not exist anymore.
+func (c *EvalAPIController) sessionDetails(ctx context.Context, appName string, result *models.EvalCaseResult) *models.Session {
+	if result.SessionID == "" {
+		return nil
+	}
+	resp, err := c.sessionService.Get(ctx, &session.GetRequest{
+		AppName:   appName,
+		UserID:    result.UserID,
+		SessionID: result.SessionID,
+	})
+	if err != nil {
+		return nil
+	}
+	s, err := models.FromSession(resp.Session)
+	if err != nil {
+		return nil
+	}
+	return &s
+}
+
+// getEvalSet returns the eval set of the request parameters.
+func (c *EvalAPIController) getEvalSet(req *http.Request) (*eval.EvalSet, error) {
+	params := mux.Vars(req)
+	set, err := c.store.GetEvalSet(req.Context(), params["app_name"], params["eval_set_id"])
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, errors.NewStatusError(fmt.Errorf("eval set %q not found", params["eval_set_id"]), http.StatusNotFound)
+	}
+	return set, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"google.golang.org/adk/adktest"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/eval"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/restapi/handlers"
+	"google.golang.org/adk/server/restapi/models"
+	"google.golang.org/adk/server/restapi/routers"
+	"google.golang.org/adk/server/restapi/services"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func TestEvalAPI(t *testing.T) {
+	m := adktest.NewModel(t,
+		// Recorded session.
+		adktest.Text("Hello!"),
+		// Evaluation.
+		adktest.Text("Hello!"),
+	)
+	a, err := llmagent.New(llmagent.Config{Name: "greeter", Model: m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	h := adktest.NewHarness(t, runner.Config{AppName: "greeter", Agent: a, SessionService: sessionService})
+	h.CreateSession("s", nil)
+	h.Run("s", "Hi")
+
+	// The agent tree of "broken" has duplicate names: its runner cannot be
+	// created.
+	newAgent := func(name string, subAgents ...agent.Agent) agent.Agent {
+		t.Helper()
+		a, err := llmagent.New(llmagent.Config{Name: name, Model: m, SubAgents: subAgents})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	broken := newAgent("broken", newAgent("dup"), newAgent("other", newAgent("dup")))
+	loader, err := services.NewMultiAgentLoader(a, broken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := eval.NewMemoryStore()
+
+	router := mux.NewRouter()
+	controller := handlers.NewEvalAPIController(sessionService, loader, artifact.InMemoryService(), memory.InMemoryService(), store)
+	routers.SetupSubRouters(router, routers.NewEvalAPIRouter(controller))
+	do := func(method, path string, body any, wantStatus int, resp any) {
+		t.Helper()
+		var b bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&b).Encode(body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, &b))
+		if rr.Code != wantStatus {
+			t.Fatalf("%s %s returned status %d, want %d: %s", method, path, rr.Code, wantStatus, rr.Body)
+		}
+		if resp != nil {
+			if err := json.NewDecoder(rr.Body).Decode(resp); err != nil {
+				t.Fatalf("%s %s: decode response: %v", method, path, err)
+			}
+		}
+	}
+
+	do(http.MethodPost, "/apps/greeter/eval_sets/greetings", nil, http.StatusOK, nil)
+	do(http.MethodPost, "/apps/greeter/eval_sets/greetings", nil, http.StatusBadRequest, nil)
+	do(http.MethodPost, "/apps/greeter/eval_sets/bad-id", nil, http.StatusBadRequest, nil)
+	do(http.MethodGet, "/apps/greeter/eval_sets/missing/evals", nil, http.StatusNotFound, nil)
+	var setIDs []string
+	do(http.MethodGet, "/apps/greeter/eval_sets", nil, http.StatusOK, &setIDs)
+	if diff := cmp.Diff([]string{"greetings"}, setIDs); diff != "" {
+		t.Errorf("eval sets mismatch (-want +got):\n%s", diff)
+	}
+
+	addSession := models.AddSessionToEvalSetRequest{EvalID: "hello", SessionID: "s", UserID: adktest.UserID}
+	do(http.MethodPost, "/apps/greeter/eval_sets/greetings/add_session", addSession, http.StatusOK, nil)
+	do(http.MethodPost, "/apps/greeter/eval_sets/greetings/add_session", addSession, http.StatusBadRequest, nil)
+	var caseIDs []string
+	do(http.MethodGet, "/apps/greeter/eval_sets/greetings/evals", nil, http.StatusOK, &caseIDs)
+	if diff := cmp.Diff([]string{"hello"}, caseIDs); diff != "" {
+		t.Errorf("eval cases mismatch (-want +got):\n%s", diff)
+	}
+	var evalCase eval.EvalCase
+	do(http.MethodGet, "/apps/greeter/eval_sets/greetings/evals/hello", nil, http.StatusOK, &evalCase)
+	if got := len(evalCase.Conversation); got != 1 {
+		t.Errorf("eval case has %d turns, want 1", got)
+	}
+
+	run := models.RunEvalRequest{
+		EvalIDs: []string{"hello"},
+		EvalMetrics: []models.EvalMetric{
+			{MetricName: eval.MetricToolTrajectory, Threshold: 1},
+			{MetricName: eval.MetricResponseMatch, Threshold: 0.7},
+		},
+	}
+	do(http.MethodPost, "/apps/greeter/eval_sets/greetings/run_eval", models.RunEvalRequest{EvalIDs: []string{"missing"}}, http.StatusBadRequest, nil)
+	do(http.MethodPost, "/apps/greeter/eval_sets/greetings/run_eval", models.RunEvalRequest{EvalMetrics: []models.EvalMetric{{MetricName: "unknown"}}}, http.StatusBadRequest, nil)
+	do(http.MethodPost, "/apps/greeter/eval_sets/greetings/run_eval", models.RunEvalRequest{EvalMetrics: []models.EvalMetric{{MetricName: eval.MetricResponseMatch, Threshold: 1.5}}}, http.StatusBadRequest, nil)
+	if err := store.SaveEvalSet(t.Context(), "broken", &eval.EvalSet{EvalSetID: "set", EvalCases: []*eval.EvalCase{{
+		EvalID:       "case",
+		Conversation: []*eval.Invocation{{UserContent: genai.NewContentFromText("Hi", genai.RoleUser)}},
+	}}}); err != nil {
+		t.Fatal(err)
+	}
+	do(http.MethodPost, "/apps/broken/eval_sets/set/run_eval", models.RunEvalRequest{}, http.StatusInternalServerError, nil)
+	var caseResults []models.EvalCaseResult
+	do(http.MethodPost, "/apps/greeter/eval_sets/greetings/run_eval", run, http.StatusOK, &caseResults)
+	if len(caseResults) != 1 || caseResults[0].EvalID != "hello" || caseResults[0].FinalEvalStatus != models.EvalStatusPassed {
+		t.Fatalf("run_eval results = %+v, want a passed hello case", caseResults)
+	}
+
+	var resultIDs []string
+	do(http.MethodGet, "/apps/greeter/eval_results", nil, http.StatusOK, &resultIDs)
+	if len(resultIDs) != 1 {
+		t.Fatalf("eval results = %v, want one result", resultIDs)
+	}
+	var result models.EvalSetResult
+	do(http.MethodGet, "/apps/greeter/eval_results/"+resultIDs[0], nil, http.StatusOK, &result)
+	if result.EvalSetID != "greetings" || len(result.EvalCaseResults) != 1 {
+		t.Fatalf("eval result = %+v, want the result of the greetings eval set", result)
+	}
+	if details := result.EvalCaseResults[0].SessionDetails; details == nil || len(details.Events) != 2 {
+		t.Errorf("session details = %+v, want the session of the run", details)
+	}
+	do(http.MethodGet, "/apps/greeter/eval_results/missing", nil, http.StatusNotFound, nil)
+}
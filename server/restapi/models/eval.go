@@ -0,0 +1,171 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+
+	"google.golang.org/adk/eval"
+)
+
+// EvalStatus is the status of an evaluation, numbered as in ADK Web.
+type EvalStatus int
+
+const (
+	EvalStatusPassed       EvalStatus = 1
+	EvalStatusFailed       EvalStatus = 2
+	EvalStatusNotEvaluated EvalStatus = 3
+)
+
+func fromEvalStatus(s eval.Status) EvalStatus {
+	switch s {
+	case eval.StatusPassed:
+		return EvalStatusPassed
+	case eval.StatusFailed:
+		return EvalStatusFailed
+	default:
+		return EvalStatusNotEvaluated
+	}
+}
+
+// AddSessionToEvalSetRequest adds a session as an eval case of an eval set.
+type AddSessionToEvalSetRequest struct {
+	EvalID    string `json:"evalId"`
+	SessionID string `json:"sessionId"`
+	UserID    string `json:"userId"`
+}
+
+// RunEvalRequest runs eval cases of an eval set.
+type RunEvalRequest struct {
+	// EvalIDs are the IDs of the eval cases to run, all of them if empty.
+	EvalIDs     []string     `json:"evalIds"`
+	EvalMetrics []EvalMetric `json:"evalMetrics"`
+}
+
+// EvalMetric is an evaluated metric and its threshold.
+type EvalMetric struct {
+	MetricName string  `json:"metricName"`
+	Threshold  float64 `json:"threshold"`
+}
+
+// Criteria returns the validated criteria of the metrics of the request.
+// The tool trajectories are matched exactly.
+func (r RunEvalRequest) Criteria() (eval.Criteria, error) {
+	var c eval.Criteria
+	for _, m := range r.EvalMetrics {
+		switch m.MetricName {
+		case eval.MetricToolTrajectory:
+			c.ToolTrajectory = &eval.TrajectoryCriterion{Threshold: m.Threshold, Match: eval.MatchExact}
+		case eval.MetricResponseMatch:
+			c.ResponseMatch = &eval.ResponseCriterion{Threshold: m.Threshold}
+		default:
+			return eval.Criteria{}, fmt.Errorf("unsupported metric %q", m.MetricName)
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return eval.Criteria{}, err
+	}
+	return c, nil
+}
+
+// EvalMetricResult is the score of a metric.
+type EvalMetricResult struct {
+	MetricName string     `json:"metricName"`
+	Threshold  float64    `json:"threshold"`
+	Score      float64    `json:"score"`
+	EvalStatus EvalStatus `json:"evalStatus"`
+}
+
+// EvalMetricResultPerInvocation is the result of a turn of an eval case.
+type EvalMetricResultPerInvocation struct {
+	ActualInvocation   *eval.Invocation   `json:"actualInvocation"`
+	ExpectedInvocation *eval.Invocation   `json:"expectedInvocation"`
+	EvalMetricResults  []EvalMetricResult `json:"evalMetricResults"`
+}
+
+// EvalCaseResult is the result of an eval case.
+type EvalCaseResult struct {
+	EvalSetID                     string                          `json:"evalSetId"`
+	EvalID                        string                          `json:"evalId"`
+	FinalEvalStatus               EvalStatus                      `json:"finalEvalStatus"`
+	Error                         string                          `json:"error,omitempty"`
+	OverallEvalMetricResults      []EvalMetricResult              `json:"overallEvalMetricResults"`
+	EvalMetricResultPerInvocation []EvalMetricResultPerInvocation `json:"evalMetricResultPerInvocation"`
+	SessionID                     string                          `json:"sessionId"`
+	// SessionDetails is the session of the run of the case, if it still
+	// exists.
+	SessionDetails *Session `json:"sessionDetails,omitempty"`
+	UserID         string   `json:"userId"`
+}
+
+// EvalSetResult is the result of the evaluation of an eval set.
+type EvalSetResult struct {
+	EvalSetResultID   string           `json:"evalSetResultId"`
+	EvalSetResultName string           `json:"evalSetResultName"`
+	EvalSetID         string           `json:"evalSetId"`
+	EvalCaseResults   []EvalCaseResult `json:"evalCaseResults"`
+	CreationTimestamp float64          `json:"creationTimestamp"`
+}
+
+// FromEvalResult maps the result of the evaluation of an eval set.
+func FromEvalResult(r *eval.Result) EvalSetResult {
+	result := EvalSetResult{
+		EvalSetResultID:   r.EvalSetResultID,
+		EvalSetResultName: r.EvalSetResultID,
+		EvalSetID:         r.EvalSetID,
+		EvalCaseResults:   []EvalCaseResult{},
+		CreationTimestamp: r.CreationTimestamp,
+	}
+	for _, c := range r.EvalCaseResults {
+		result.EvalCaseResults = append(result.EvalCaseResults, FromEvalCaseResult(r.EvalSetID, c))
+	}
+	return result
+}
+
+// FromEvalCaseResult maps the result of an eval case of the eval set
+// evalSetID.
+func FromEvalCaseResult(evalSetID string, c *eval.CaseResult) EvalCaseResult {
+	result := EvalCaseResult{
+		EvalSetID:                     evalSetID,
+		EvalID:                        c.EvalID,
+		FinalEvalStatus:               fromEvalStatus(c.FinalEvalStatus),
+		Error:                         c.Error,
+		OverallEvalMetricResults:      fromMetricResults(c.OverallEvalMetricResults),
+		EvalMetricResultPerInvocation: []EvalMetricResultPerInvocation{},
+		SessionID:                     c.SessionID,
+		UserID:                        c.UserID,
+	}
+	for _, inv := range c.EvalMetricResultPerInvocation {
+		result.EvalMetricResultPerInvocation = append(result.EvalMetricResultPerInvocation, EvalMetricResultPerInvocation{
+			ActualInvocation:   inv.ActualInvocation,
+			ExpectedInvocation: inv.ExpectedInvocation,
+			EvalMetricResults:  fromMetricResults(inv.EvalMetricResults),
+		})
+	}
+	return result
+}
+
+func fromMetricResults(results []*eval.MetricResult) []EvalMetricResult {
+	mapped := []EvalMetricResult{}
+	for _, m := range results {
+		mapped = append(mapped, EvalMetricResult{
+			MetricName: m.MetricName,
+			Threshold:  m.Threshold,
+			Score:      m.Score,
+			EvalStatus: fromEvalStatus(m.EvalStatus),
+		})
+	}
+	return mapped
+}
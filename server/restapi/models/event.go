@@ -19,13 +19,15 @@ import (
 
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/toolconfirmation"
 	"google.golang.org/genai"
 )
 
 // EventActions represent a data model for session.EventActions
 type EventActions struct {
-	StateDelta    map[string]any   `json:"stateDelta"`
-	ArtifactDelta map[string]int64 `json:"artifactDelta"`
+	StateDelta                 map[string]any                            `json:"stateDelta"`
+	ArtifactDelta              map[string]int64                          `json:"artifactDelta"`
+	RequestedToolConfirmations map[string]*toolconfirmation.Confirmation `json:"requestedToolConfirmations,omitempty"`
 }
 
 // Event represents a single event in a session.
@@ -65,8 +67,9 @@ func ToSessionEvent(event Event) *session.Event {
 			ErrorMessage:      event.ErrorMessage,
 		},
 		Actions: session.EventActions{
-			StateDelta:    event.Actions.StateDelta,
-			ArtifactDelta: event.Actions.ArtifactDelta,
+			StateDelta:                 event.Actions.StateDelta,
+			ArtifactDelta:              event.Actions.ArtifactDelta,
+			RequestedToolConfirmations: event.Actions.RequestedToolConfirmations,
 		},
 	}
 }
@@ -88,8 +91,9 @@ func FromSessionEvent(event session.Event) Event {
 		ErrorCode:          event.LLMResponse.ErrorCode,
 		ErrorMessage:       event.LLMResponse.ErrorMessage,
 		Actions: EventActions{
-			StateDelta:    event.Actions.StateDelta,
-			ArtifactDelta: event.Actions.ArtifactDelta,
+			StateDelta:                 event.Actions.StateDelta,
+			ArtifactDelta:              event.Actions.ArtifactDelta,
+			RequestedToolConfirmations: event.Actions.RequestedToolConfirmations,
 		},
 	}
 }
@@ -21,28 +21,71 @@ import (
 )
 
 // EvalAPIRouter defines the routes for the Eval API.
-type EvalAPIRouter struct{}
+type EvalAPIRouter struct {
+	evalController *handlers.EvalAPIController
+}
+
+// NewEvalAPIRouter creates a new EvalAPIRouter.
+func NewEvalAPIRouter(controller *handlers.EvalAPIController) *EvalAPIRouter {
+	return &EvalAPIRouter{evalController: controller}
+}
 
-// Routes returns the routes for the Apps API.
+// Routes returns the routes for the Eval API.
 func (r *EvalAPIRouter) Routes() Routes {
 	return Routes{
+		Route{
+			Name:        "CreateEvalSet",
+			Methods:     []string{http.MethodPost, http.MethodOptions},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}",
+			HandlerFunc: handlers.FromErrorHandler(r.evalController.CreateEvalSetHTTP),
+		},
 		Route{
 			Name:        "ListEvalSets",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/eval_sets",
-			HandlerFunc: handlers.Unimplemented,
+			HandlerFunc: handlers.FromErrorHandler(r.evalController.ListEvalSetsHTTP),
 		},
 		Route{
-			Name:        "ListEvalSets",
+			Name:        "GetEvalSet",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}",
+			HandlerFunc: handlers.FromErrorHandler(r.evalController.GetEvalSetHTTP),
+		},
+		Route{
+			Name:        "ListEvalCases",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/evals",
+			HandlerFunc: handlers.FromErrorHandler(r.evalController.ListEvalCasesHTTP),
+		},
+		Route{
+			Name:        "GetEvalCase",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/evals/{eval_case_id}",
+			HandlerFunc: handlers.FromErrorHandler(r.evalController.GetEvalCaseHTTP),
+		},
+		Route{
+			Name:        "AddSessionToEvalSet",
 			Methods:     []string{http.MethodPost, http.MethodOptions},
-			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_name}",
-			HandlerFunc: handlers.Unimplemented,
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/add_session",
+			HandlerFunc: handlers.FromErrorHandler(r.evalController.AddSessionToEvalSetHTTP),
+		},
+		Route{
+			Name:        "RunEval",
+			Methods:     []string{http.MethodPost, http.MethodOptions},
+			Pattern:     "/apps/{app_name}/eval_sets/{eval_set_id}/run_eval",
+			HandlerFunc: handlers.FromErrorHandler(r.evalController.RunEvalHTTP),
 		},
 		Route{
 			Name:        "ListEvalResults",
 			Methods:     []string{http.MethodGet},
 			Pattern:     "/apps/{app_name}/eval_results",
-			HandlerFunc: handlers.Unimplemented,
+			HandlerFunc: handlers.FromErrorHandler(r.evalController.ListEvalResultsHTTP),
+		},
+		Route{
+			Name:        "GetEvalResult",
+			Methods:     []string{http.MethodGet},
+			Pattern:     "/apps/{app_name}/eval_results/{eval_result_id}",
+			HandlerFunc: handlers.FromErrorHandler(r.evalController.GetEvalResultHTTP),
 		},
 	}
 }
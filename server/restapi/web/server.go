@@ -18,6 +18,7 @@ package web
 import (
 	"github.com/gorilla/mux"
 	"google.golang.org/adk/cmd/launcher/adk"
+	"google.golang.org/adk/eval"
 	"google.golang.org/adk/internal/telemetry"
 	"google.golang.org/adk/server/restapi/handlers"
 	"google.golang.org/adk/server/restapi/routers"
@@ -28,6 +29,10 @@ import (
 
 // SetupRouter initiates mux.Router with ADK REST API routers
 func SetupRouter(router *mux.Router, routerConfig *adk.Config) *mux.Router {
+	evalStore := routerConfig.EvalStore
+	if evalStore == nil {
+		evalStore = eval.NewMemoryStore()
+	}
 	adkExporter := services.NewAPIServerSpanExporter()
 	telemetry.AddSpanProcessor(sdktrace.NewSimpleSpanProcessor(adkExporter))
 	return setupRouter(router,
@@ -36,7 +41,7 @@ func SetupRouter(router *mux.Router, routerConfig *adk.Config) *mux.Router {
 		routers.NewAppsAPIRouter(handlers.NewAppsAPIController(routerConfig.AgentLoader)),
 		routers.NewDebugAPIRouter(handlers.NewDebugAPIController(routerConfig.SessionService, routerConfig.AgentLoader, adkExporter)),
 		routers.NewArtifactsAPIRouter(handlers.NewArtifactsAPIController(routerConfig.ArtifactService)),
-		&routers.EvalAPIRouter{},
+		routers.NewEvalAPIRouter(handlers.NewEvalAPIController(routerConfig.SessionService, routerConfig.AgentLoader, routerConfig.ArtifactService, routerConfig.MemoryService, evalStore)),
 	)
 }
 
@@ -0,0 +1,151 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compaction summarizes the older events of long sessions, so that
+// the history sent to the models stays within their context window.
+//
+// The summary is recorded in the session as an event with a
+// [session.EventCompaction], which replaces the events it covers in the
+// contents of the model requests. The events themselves are kept in the
+// session.
+package compaction
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// Config controls when and how the events of a session are compacted.
+//
+// The compaction is due once InvocationInterval invocations completed since
+// the last compaction, or once the prompt of the last model call reached
+// TokenThreshold tokens. At least one of them must be set.
+type Config struct {
+	// Summarizer summarizes the compacted events. Required.
+	Summarizer Summarizer
+
+	// InvocationInterval is the number of invocations after which their
+	// events are compacted. Zero disables it.
+	InvocationInterval int
+	// TokenThreshold is the number of prompt tokens of a model call after
+	// which the events are compacted. Zero disables it.
+	TokenThreshold int32
+	// OverlapInvocations is the number of invocations already covered by the
+	// previous compaction that are compacted again with the new ones, so that
+	// consecutive summaries share some context.
+	OverlapInvocations int
+}
+
+// Validate checks the configuration.
+func (c *Config) Validate() error {
+	if c.Summarizer == nil {
+		return fmt.Errorf("compaction summarizer is required")
+	}
+	if c.InvocationInterval < 0 || c.TokenThreshold < 0 || c.OverlapInvocations < 0 {
+		return fmt.Errorf("compaction interval, threshold and overlap must not be negative")
+	}
+	if c.InvocationInterval == 0 && c.TokenThreshold == 0 {
+		return fmt.Errorf("compaction requires an invocation interval or a token threshold")
+	}
+	return nil
+}
+
+// Summarizer summarizes a range of events of a session.
+type Summarizer interface {
+	// Summarize returns the content replacing the events in the model
+	// requests.
+	Summarize(ctx context.Context, events []*session.Event) (*genai.Content, error)
+}
+
+// Compact returns the event compacting the events of the session if the
+// compaction is due, or nil otherwise. The event belongs to the invocation
+// with the given ID and must be appended to the session.
+//
+// The compaction covers the invocations completed since the last compaction,
+// preceded by the OverlapInvocations last invocations it covered.
+func Compact(ctx context.Context, cfg *Config, events []*session.Event, invocationID string) (*session.Event, error) {
+	var (
+		lastEnd     time.Time
+		invocations []string
+		starts      = make(map[string]time.Time)
+	)
+	for _, ev := range events {
+		if c := ev.Actions.Compaction; c != nil {
+			if c.EndTimestamp.After(lastEnd) {
+				lastEnd = c.EndTimestamp
+			}
+			continue
+		}
+		if _, ok := starts[ev.InvocationID]; !ok {
+			invocations = append(invocations, ev.InvocationID)
+			starts[ev.InvocationID] = ev.Timestamp
+		}
+	}
+
+	// The invocations started after the last compaction.
+	first := len(invocations)
+	for i, id := range invocations {
+		if starts[id].After(lastEnd) {
+			first = i
+			break
+		}
+	}
+	newInvocations := len(invocations) - first
+	if newInvocations == 0 {
+		return nil, nil
+	}
+	due := cfg.InvocationInterval > 0 && newInvocations >= cfg.InvocationInterval
+	if !due && cfg.TokenThreshold > 0 {
+		due = lastPromptTokens(events) >= cfg.TokenThreshold
+	}
+	if !due {
+		return nil, nil
+	}
+
+	start := starts[invocations[max(0, first-cfg.OverlapInvocations)]]
+	var compacted []*session.Event
+	for _, ev := range events {
+		if ev.Actions.Compaction == nil && !ev.Timestamp.Before(start) {
+			compacted = append(compacted, ev)
+		}
+	}
+	content, err := cfg.Summarizer.Summarize(ctx, compacted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize events: %w", err)
+	}
+
+	ev := session.NewEvent(invocationID)
+	ev.Author = "user"
+	ev.Actions.Compaction = &session.EventCompaction{
+		StartTimestamp:   start,
+		EndTimestamp:     compacted[len(compacted)-1].Timestamp,
+		CompactedContent: content,
+	}
+	return ev, nil
+}
+
+// lastPromptTokens returns the number of prompt tokens of the last model
+// call recorded in the events.
+func lastPromptTokens(events []*session.Event) int32 {
+	for i := len(events) - 1; i >= 0; i-- {
+		if usage := events[i].UsageMetadata; usage != nil {
+			return usage.PromptTokenCount
+		}
+	}
+	return 0
+}
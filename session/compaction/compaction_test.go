@@ -0,0 +1,217 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compaction_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/compaction"
+	"google.golang.org/genai"
+)
+
+// summarizerFunc records the summarized events.
+type summarizerFunc func(events []*session.Event) (*genai.Content, error)
+
+func (f summarizerFunc) Summarize(_ context.Context, events []*session.Event) (*genai.Content, error) {
+	return f(events)
+}
+
+func TestCompact(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return base.Add(time.Duration(i) * time.Second) }
+	event := func(i int, invocationID string) *session.Event {
+		return &session.Event{
+			Timestamp:    at(i),
+			InvocationID: invocationID,
+			Author:       "user",
+			LLMResponse:  model.LLMResponse{Content: genai.NewContentFromText(invocationID, genai.RoleUser)},
+		}
+	}
+	withUsage := func(ev *session.Event, promptTokens int32) *session.Event {
+		ev.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: promptTokens}
+		return ev
+	}
+	compactionEvent := func(i, start, end int) *session.Event {
+		return &session.Event{
+			Timestamp:    at(i),
+			InvocationID: "e2",
+			Author:       "user",
+			Actions: session.EventActions{Compaction: &session.EventCompaction{
+				StartTimestamp: at(start),
+				EndTimestamp:   at(end),
+			}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		cfg  compaction.Config
+		// events are the session events, with the timestamp of their index.
+		events []*session.Event
+		// wantSummarized are the invocations of the summarized events, nil if
+		// no compaction is due.
+		wantSummarized []string
+		wantStart      int
+		wantEnd        int
+	}{
+		{
+			name:   "interval not reached",
+			cfg:    compaction.Config{InvocationInterval: 3},
+			events: []*session.Event{event(0, "e1"), event(1, "e1"), event(2, "e2")},
+		},
+		{
+			name:           "interval reached",
+			cfg:            compaction.Config{InvocationInterval: 2},
+			events:         []*session.Event{event(0, "e1"), event(1, "e1"), event(2, "e2")},
+			wantSummarized: []string{"e1", "e1", "e2"},
+			wantStart:      0,
+			wantEnd:        2,
+		},
+		{
+			name: "only the invocations since the last compaction count",
+			cfg:  compaction.Config{InvocationInterval: 2},
+			events: []*session.Event{
+				event(0, "e1"), event(1, "e2"), compactionEvent(2, 0, 1), event(3, "e3"),
+			},
+		},
+		{
+			name: "overlap with the last compaction",
+			cfg:  compaction.Config{InvocationInterval: 2, OverlapInvocations: 1},
+			events: []*session.Event{
+				event(0, "e1"), event(1, "e2"), compactionEvent(2, 0, 1), event(3, "e3"), event(4, "e4"),
+			},
+			wantSummarized: []string{"e2", "e3", "e4"},
+			wantStart:      1,
+			wantEnd:        4,
+		},
+		{
+			name: "token threshold reached",
+			cfg:  compaction.Config{TokenThreshold: 1000},
+			events: []*session.Event{
+				event(0, "e1"), withUsage(event(1, "e1"), 2000), event(2, "e2"), withUsage(event(3, "e2"), 1000),
+			},
+			wantSummarized: []string{"e1", "e1", "e2", "e2"},
+			wantStart:      0,
+			wantEnd:        3,
+		},
+		{
+			name: "token threshold not reached",
+			cfg:  compaction.Config{TokenThreshold: 1000},
+			events: []*session.Event{
+				event(0, "e1"), withUsage(event(1, "e1"), 2000), event(2, "e2"), withUsage(event(3, "e2"), 999),
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var summarized []string
+			tc.cfg.Summarizer = summarizerFunc(func(events []*session.Event) (*genai.Content, error) {
+				for _, ev := range events {
+					summarized = append(summarized, ev.InvocationID)
+				}
+				return genai.NewContentFromText("summary", genai.RoleModel), nil
+			})
+			if err := tc.cfg.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			got, err := compaction.Compact(t.Context(), &tc.cfg, tc.events, "e-current")
+			if err != nil {
+				t.Fatalf("Compact() error = %v", err)
+			}
+			if tc.wantSummarized == nil {
+				if got != nil {
+					t.Errorf("Compact() = %+v, want nil", got.Actions.Compaction)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("Compact() = nil, want a compaction event")
+			}
+			if diff := cmp.Diff(tc.wantSummarized, summarized); diff != "" {
+				t.Errorf("summarized events mismatch (-want +got):\n%s", diff)
+			}
+			want := &session.EventCompaction{
+				StartTimestamp:   at(tc.wantStart),
+				EndTimestamp:     at(tc.wantEnd),
+				CompactedContent: genai.NewContentFromText("summary", genai.RoleModel),
+			}
+			if diff := cmp.Diff(want, got.Actions.Compaction); diff != "" {
+				t.Errorf("Compact() compaction mismatch (-want +got):\n%s", diff)
+			}
+			if got.InvocationID != "e-current" || got.Author != "user" {
+				t.Errorf("Compact() event = (%q, %q), want (%q, %q)", got.InvocationID, got.Author, "e-current", "user")
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	summarizer := summarizerFunc(func([]*session.Event) (*genai.Content, error) { return nil, nil })
+	tests := []struct {
+		name    string
+		cfg     compaction.Config
+		wantErr bool
+	}{
+		{name: "valid", cfg: compaction.Config{Summarizer: summarizer, InvocationInterval: 1}},
+		{name: "no summarizer", cfg: compaction.Config{InvocationInterval: 1}, wantErr: true},
+		{name: "no trigger", cfg: compaction.Config{Summarizer: summarizer}, wantErr: true},
+		{name: "negative overlap", cfg: compaction.Config{Summarizer: summarizer, TokenThreshold: 1, OverlapInvocations: -1}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.cfg.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLLMSummarizer(t *testing.T) {
+	mockModel := &testutil.MockModel{Responses: []*genai.Content{genai.NewContentFromText("The user greeted the agent.", genai.RoleModel)}}
+	summarizer, err := compaction.NewLLMSummarizer(compaction.LLMSummarizerConfig{Model: mockModel, Instruction: "Summarize."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []*session.Event{
+		{Author: "user", LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("hello", genai.RoleUser)}},
+		{Author: "agent", LLMResponse: model.LLMResponse{Content: genai.NewContentFromFunctionCall("greet", map[string]any{"name": "Ann"}, genai.RoleModel)}},
+		{Author: "agent", LLMResponse: model.LLMResponse{Content: genai.NewContentFromFunctionResponse("greet", map[string]any{"ok": true}, genai.RoleUser)}},
+	}
+	got, err := summarizer.Summarize(t.Context(), events)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if diff := cmp.Diff(genai.NewContentFromText("The user greeted the agent.", genai.RoleModel), got); diff != "" {
+		t.Errorf("Summarize() mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(mockModel.Requests) != 1 {
+		t.Fatalf("model calls = %d, want 1", len(mockModel.Requests))
+	}
+	prompt := mockModel.Requests[0].Contents[0].Parts[0].Text
+	for _, want := range []string{"Summarize.", "user: hello", `agent called tool greet with arguments {"name":"Ann"}`, `tool greet returned {"ok":true}`} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("summarization prompt %q does not contain %q", prompt, want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// DefaultInstruction is the instruction given to the model summarizing the
+// events when the LLMSummarizerConfig has none.
+const DefaultInstruction = "The following is a conversation history between a user and an AI agent. " +
+	"Summarize it concisely, focusing on the key information, the decisions made, the results of the tool calls " +
+	"and any pending tasks. The summary replaces the conversation in the context of the agent, keep everything it needs to continue."
+
+// LLMSummarizerConfig is used to create a summarizer backed by a model.
+type LLMSummarizerConfig struct {
+	// Model generating the summaries. Required.
+	Model model.LLM
+	// Instruction given to the model, followed by the transcript of the
+	// events. Defaults to DefaultInstruction.
+	Instruction string
+}
+
+// NewLLMSummarizer returns a Summarizer asking a model to summarize the
+// transcript of the events.
+func NewLLMSummarizer(cfg LLMSummarizerConfig) (Summarizer, error) {
+	if cfg.Model == nil {
+		return nil, fmt.Errorf("summarizer model is required")
+	}
+	if cfg.Instruction == "" {
+		cfg.Instruction = DefaultInstruction
+	}
+	return &llmSummarizer{model: cfg.Model, instruction: cfg.Instruction}, nil
+}
+
+type llmSummarizer struct {
+	model       model.LLM
+	instruction string
+}
+
+func (s *llmSummarizer) Summarize(ctx context.Context, events []*session.Event) (*genai.Content, error) {
+	req := &model.LLMRequest{
+		Model:    s.model.Name(),
+		Contents: []*genai.Content{genai.NewContentFromText(s.instruction+"\n\n"+transcript(events), genai.RoleUser)},
+		Config:   &genai.GenerateContentConfig{},
+	}
+	var summary strings.Builder
+	for resp, err := range s.model.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return nil, err
+		}
+		if resp.ErrorCode != "" {
+			return nil, fmt.Errorf("model error %s: %s", resp.ErrorCode, resp.ErrorMessage)
+		}
+		if resp.Partial || resp.Content == nil {
+			continue
+		}
+		for _, p := range resp.Content.Parts {
+			if !p.Thought {
+				summary.WriteString(p.Text)
+			}
+		}
+	}
+	if summary.Len() == 0 {
+		return nil, fmt.Errorf("model %q returned an empty summary", s.model.Name())
+	}
+	return genai.NewContentFromText(summary.String(), genai.RoleModel), nil
+}
+
+// transcript returns the text of the events, one line per part.
+func transcript(events []*session.Event) string {
+	var b strings.Builder
+	for _, ev := range events {
+		if ev.Content == nil {
+			continue
+		}
+		for _, p := range ev.Content.Parts {
+			switch {
+			case p.Thought:
+			case p.Text != "":
+				fmt.Fprintf(&b, "%s: %s\n", ev.Author, p.Text)
+			case p.FunctionCall != nil:
+				fmt.Fprintf(&b, "%s called tool %s with arguments %s\n", ev.Author, p.FunctionCall.Name, toJSON(p.FunctionCall.Args))
+			case p.FunctionResponse != nil:
+				fmt.Fprintf(&b, "tool %s returned %s\n", p.FunctionResponse.Name, toJSON(p.FunctionResponse.Response))
+			}
+		}
+	}
+	return b.String()
+}
+
+func toJSON(v map[string]any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -314,6 +314,9 @@ func (s *session) State() State {
 }
 
 func (s *session) Events() Events {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return events(s.events)
 }
 
@@ -334,6 +337,9 @@ func (s *session) appendEvent(event *Event) error {
 		return fmt.Errorf("error on appendEvent: %w", err)
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.events = append(s.events, event)
 	s.updatedAt = event.Timestamp
 	return nil
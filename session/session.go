@@ -20,7 +20,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool/toolconfirmation"
+	"google.golang.org/genai"
 )
 
 // Session represents a series of interactions between a user and agents.
@@ -153,6 +156,34 @@ type EventActions struct {
 	TransferToAgent string
 	// The agent is escalating to a higher level agent.
 	Escalate bool
+	// RequestedAuthConfigs are the credentials requested by the tools,
+	// keyed by the ID of the function call that requested them.
+	// Only valid for function response event.
+	RequestedAuthConfigs map[string]*auth.Config
+	// RequestedToolConfirmations are the confirmations requested for the
+	// tool calls, keyed by the ID of the function call to confirm.
+	// Only valid for function response event.
+	RequestedToolConfirmations map[string]*toolconfirmation.Confirmation
+	// AgentState is the checkpoint recorded by a workflow agent of a
+	// resumable invocation, e.g. the position of a sequential agent among
+	// its sub-agents.
+	AgentState map[string]any
+	// EndOfAgent indicates that the author agent completed its run in the
+	// invocation. It is only recorded for resumable invocations.
+	EndOfAgent bool
+	// Compaction is set on the events summarizing older events of the
+	// session. See [EventCompaction].
+	Compaction *EventCompaction
+}
+
+// EventCompaction replaces the events of the session recorded between
+// StartTimestamp and EndTimestamp, both inclusive, by their summary in the
+// history sent to the models.
+type EventCompaction struct {
+	StartTimestamp time.Time
+	EndTimestamp   time.Time
+	// CompactedContent is the summary of the compacted events.
+	CompactedContent *genai.Content
 }
 
 // Prefixes for defining session's state scopes
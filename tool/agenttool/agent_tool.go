@@ -165,6 +165,7 @@ func (t *agentTool) Run(toolCtx tool.Context, args any) (map[string]any, error)
 
 	sessionService := session.InMemoryService()
 
+	// The runner applies the plugins of the invocation calling the tool.
 	r, err := runner.New(runner.Config{
 		AppName:        t.agent.Name(),
 		Agent:          t.agent,
@@ -17,6 +17,7 @@ package functiontool
 
 import (
 	"fmt"
+	"runtime/debug"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"google.golang.org/adk/internal/toolinternal/toolutils"
@@ -42,6 +43,19 @@ type Config struct {
 	OutputSchema *jsonschema.Schema
 	// IsLongRunning makes a FunctionTool a long-running operation.
 	IsLongRunning bool
+	// Sequential prevents the tool from running concurrently with the other
+	// function calls of the same model response.
+	Sequential bool
+	// RequireConfirmation makes the calls of the tool wait for the
+	// confirmation of a human before they run, see package
+	// toolconfirmation.
+	RequireConfirmation bool
+	// RequireConfirmationFunc, if set, decides from the arguments of a call
+	// whether it requires a confirmation, in place of RequireConfirmation.
+	RequireConfirmationFunc func(ctx tool.Context, args map[string]any) bool
+	// ConfirmationHint explains the human what is confirmed. If it is
+	// empty, a generic hint naming the tool is used.
+	ConfirmationHint string
 }
 
 // Func represents a Go function that can be wrapped in a tool.
@@ -98,6 +112,23 @@ func (f *functionTool[TArgs, TResults]) IsLongRunning() bool {
 	return f.cfg.IsLongRunning
 }
 
+// IsSequential implements tool.SequentialTool.
+func (f *functionTool[TArgs, TResults]) IsSequential() bool {
+	return f.cfg.Sequential
+}
+
+// RequiresConfirmation implements tool.ConfirmableTool.
+func (f *functionTool[TArgs, TResults]) RequiresConfirmation(ctx tool.Context, args map[string]any) (string, bool) {
+	required := f.cfg.RequireConfirmation
+	if f.cfg.RequireConfirmationFunc != nil {
+		required = f.cfg.RequireConfirmationFunc(ctx, args)
+	}
+	if !required {
+		return "", false
+	}
+	return f.cfg.ConfirmationHint, true
+}
+
 // ProcessRequest packs the function tool's declaration into the LLM request.
 func (f *functionTool[TArgs, TResults]) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
 	return toolutils.PackTool(req, f)
@@ -128,10 +159,33 @@ func (f *functionTool[TArgs, TResults]) Declaration() *genai.FunctionDeclaration
 	return decl
 }
 
+// PanicError is the error returned by a function tool when its function
+// panics.
+type PanicError struct {
+	// Tool is the name of the tool.
+	Tool string
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("tool %q panicked: %v", e.Tool, e.Value)
+}
+
+// Unwrap returns the value passed to panic if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
 // Run executes the tool with the provided context and yields events.
+//
+// If the function panics, Run recovers and returns a *PanicError.
 func (f *functionTool[TArgs, TResults]) Run(ctx tool.Context, args any) (map[string]any, error) {
 	// TODO: Handle function call request from tc.InvocationContext.
-	// TODO: Handle panic -> convert to error.
 	m, ok := args.(map[string]any)
 	if !ok {
 		return nil, fmt.Errorf("unexpected args type, got: %T", args)
@@ -140,7 +194,10 @@ func (f *functionTool[TArgs, TResults]) Run(ctx tool.Context, args any) (map[str
 	if err != nil {
 		return nil, err
 	}
-	output := f.handler(ctx, input)
+	output, err := f.call(ctx, input)
+	if err != nil {
+		return nil, err
+	}
 	resp, err := typeutil.ConvertToWithJSONSchema[TResults, map[string]any](output, f.outputSchema)
 	if err == nil { // all good
 		return resp, nil
@@ -159,6 +216,16 @@ func (f *functionTool[TArgs, TResults]) Run(ctx tool.Context, args any) (map[str
 	return wrappedOutput, nil
 }
 
+// call calls the function, turning a panic into a *PanicError.
+func (f *functionTool[TArgs, TResults]) call(ctx tool.Context, input TArgs) (output TResults, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Tool: f.Name(), Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return f.handler(ctx, input), nil
+}
+
 // ** NOTE FOR REVIEWERS **
 // Initially I started to borrow the design of the MCP ServerTool and
 // ToolHandlerFor/ToolHandler [1], but got diverged.
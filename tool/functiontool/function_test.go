@@ -16,6 +16,7 @@ package functiontool_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"net/http"
@@ -25,7 +26,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/jsonschema-go/jsonschema"
-	"google.golang.org/adk/internal/httprr"
+	"google.golang.org/adk/adktest/httprr"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/internal/typeutil"
@@ -229,6 +230,51 @@ func TestFunctionTool_DifferentFunctionDeclarations_ConsolidatedInOneGenAiTool(t
 	}
 }
 
+func TestFunctionTool_Panic(t *testing.T) {
+	type Args struct {
+		Key string `json:"key"`
+	}
+	errBackend := errors.New("backend unavailable")
+	flakyTool, err := functiontool.New(functiontool.Config{
+		Name:        "flaky",
+		Description: "calls a flaky backend",
+	}, func(ctx tool.Context, args Args) map[string]any {
+		if args.Key == "error" {
+			panic(errBackend)
+		}
+		panic("boom")
+	})
+	if err != nil {
+		t.Fatalf("NewFunctionTool failed: %v", err)
+	}
+	funcTool, ok := flakyTool.(toolinternal.FunctionTool)
+	if !ok {
+		t.Fatal("flakyTool does not implement toolinternal.FunctionTool")
+	}
+
+	for _, tc := range []struct {
+		key       string
+		wantValue any
+	}{
+		{key: "value", wantValue: "boom"},
+		{key: "error", wantValue: errBackend},
+	} {
+		t.Run(tc.key, func(t *testing.T) {
+			_, err := funcTool.Run(nil, map[string]any{"key": tc.key})
+			var panicErr *functiontool.PanicError
+			if !errors.As(err, &panicErr) {
+				t.Fatalf("Run() error = %v, want a *PanicError", err)
+			}
+			if panicErr.Tool != "flaky" || panicErr.Value != tc.wantValue || len(panicErr.Stack) == 0 {
+				t.Errorf("Run() error = %+v, want the panic of %q with %v", panicErr, "flaky", tc.wantValue)
+			}
+			if got, want := errors.Is(err, errBackend), tc.wantValue == errBackend; got != want {
+				t.Errorf("errors.Is(err, errBackend) = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
 func TestFunctionTool_ReturnsBasicType(t *testing.T) {
 	type Args struct {
 		City string `json:"city"`
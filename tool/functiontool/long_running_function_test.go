@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/internal/toolinternal"
@@ -324,3 +325,78 @@ func TestLongRunningToolIDsAreSet(t *testing.T) {
 			functionCallEvent.LLMResponse.Content.Parts[0].FunctionCall.ID)
 	}
 }
+
+func TestLongRunningFunctionInSubAgent(t *testing.T) {
+	approve, err := functiontool.New(functiontool.Config{
+		Name:          "approve",
+		Description:   "asks a human for approval",
+		IsLongRunning: true,
+	}, func(ctx tool.Context, x IncArgs) map[string]string {
+		return map[string]string{"status": "pending"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workerModel := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("approve", map[string]any{}, "model"),
+		genai.NewContentFromText("waiting for approval", "model"),
+		genai.NewContentFromText("approved, done", "model"),
+	}}
+	// The worker does not allow transfers back to its parent: without
+	// matching the function response, the root agent would handle it.
+	worker, err := llmagent.New(llmagent.Config{
+		Name:                     "worker",
+		Description:              "does the work",
+		Model:                    workerModel,
+		Tools:                    []tool.Tool{approve},
+		DisallowTransferToParent: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootModel := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("transfer_to_agent", map[string]any{"agent_name": "worker"}, "model"),
+	}}
+	root, err := llmagent.New(llmagent.Config{
+		Name:      "root",
+		Model:     rootModel,
+		SubAgents: []agent.Agent{worker},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, root)
+
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "do the work"))
+	if err != nil {
+		t.Fatalf("failed to collect events: %v", err)
+	}
+	var callID string
+	for _, ev := range events {
+		if len(ev.LongRunningToolIDs) > 0 {
+			callID = ev.LongRunningToolIDs[0]
+		}
+	}
+	if callID == "" {
+		t.Fatal("no long-running function call")
+	}
+
+	reply := NewContentFromFunctionResponseWithID("approve", map[string]any{"status": "approved"}, callID, "user")
+	events, err = testutil.CollectEvents(runner.RunContent(t, "session", reply))
+	if err != nil {
+		t.Fatalf("failed to collect events: %v", err)
+	}
+	if len(rootModel.Requests) != 1 {
+		t.Errorf("root model got %d requests, want 1", len(rootModel.Requests))
+	}
+	if len(events) != 1 || events[0].Author != "worker" || events[0].Content.Parts[0].Text != "approved, done" {
+		t.Fatalf("got events %+v, want the worker response", events)
+	}
+	// The worker continues with the approval result.
+	contents := workerModel.Requests[len(workerModel.Requests)-1].Contents
+	want := genai.NewContentFromFunctionResponse("approve", map[string]any{"status": "approved"}, "user")
+	if diff := cmp.Diff(want, contents[len(contents)-1], cmpopts.IgnoreFields(genai.FunctionResponse{}, "ID")); diff != "" {
+		t.Errorf("worker request last content mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -27,10 +27,10 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/adk/adktest/httprr"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	icontext "google.golang.org/adk/internal/context"
-	"google.golang.org/adk/internal/httprr"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
@@ -121,6 +121,7 @@ func TestMCPToolSet(t *testing.T) {
 					},
 					Role: genai.RoleModel,
 				},
+				CustomMetadata: map[string]any{model.ServedModelMetadataKey: "gemini-2.5-flash"},
 			},
 		},
 		{
@@ -152,6 +153,7 @@ func TestMCPToolSet(t *testing.T) {
 					},
 					Role: genai.RoleModel,
 				},
+				CustomMetadata: map[string]any{model.ServedModelMetadataKey: "gemini-2.5-flash"},
 			},
 		},
 	}
@@ -21,6 +21,7 @@ import (
 	"context"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/session"
 )
@@ -36,6 +37,33 @@ type Tool interface {
 	IsLongRunning() bool
 }
 
+// SequentialTool is implemented by tools that must not run concurrently with
+// the other function calls of the same model response, e.g. because they are
+// not safe for concurrent use.
+//
+// By default, the function calls of a model response run concurrently.
+type SequentialTool interface {
+	Tool
+	// IsSequential reports whether the tool must run on its own, after the
+	// preceding function calls completed.
+	IsSequential() bool
+}
+
+// ConfirmableTool is implemented by tools whose calls may require the
+// confirmation of a human before they run, e.g. refunds or deletions. See
+// package toolconfirmation.
+type ConfirmableTool interface {
+	Tool
+	// RequiresConfirmation reports whether the call with the args must be
+	// confirmed, and the hint explaining the human what is confirmed.
+	RequiresConfirmation(ctx Context, args map[string]any) (hint string, required bool)
+}
+
+// ConfirmationPolicy decides whether the call of the tool with the args must
+// be confirmed by a human before it runs, like
+// ConfirmableTool.RequiresConfirmation, for the tools of an agent.
+type ConfirmationPolicy func(ctx Context, tool Tool, args map[string]any) (hint string, required bool)
+
 // Context defines the interface for the context passed to a tool when it's
 // called. It provides access to invocation-specific information and allows
 // the tool to interact with the agent's state and memory.
@@ -51,6 +79,20 @@ type Context interface {
 	Actions() *session.EventActions
 	// SearchMemory performs a semantic search on the agent's memory.
 	SearchMemory(context.Context, string) (*memory.SearchResponse, error)
+
+	// Credential returns the credential for the auth config. It returns nil
+	// if the credential must first be requested with RequestCredential.
+	//
+	// Credentials that do not require user interaction, e.g. an API key set
+	// in the raw credential or OAuth2 client credentials, are always
+	// available.
+	Credential(cfg *auth.Config) (*auth.Credential, error)
+	// RequestCredential asks the client for the credential of the auth
+	// config. Once the tool returns, the agent emits an
+	// auth.RequestCredentialFunctionName function call and the invocation
+	// ends. The tool is called again when the client provides the
+	// credential.
+	RequestCredential(cfg *auth.Config) error
 }
 
 // Toolset is an interface for a collection of tools. It allows grouping
@@ -0,0 +1,56 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package toolconfirmation defines how the calls of sensitive tools are
+// confirmed by a human before they run.
+//
+// When a tool call requires a confirmation, see tool.ConfirmableTool and
+// llmagent.Config.ToolConfirmationPolicy, the tool is not run: the model
+// gets an error response, and the agent emits a function call named
+// FunctionName, whose arguments are described by RequestArgs, and ends the
+// invocation. The function call is marked long-running.
+//
+// The client asks the human and replies with a function response to that
+// call holding a Confirmation. In the next invocation, the tool is run if
+// the call is confirmed, with the arguments of the confirmation if set;
+// otherwise the model gets a rejection response.
+package toolconfirmation
+
+import "google.golang.org/genai"
+
+// FunctionName is the name of the function call emitted by the agent to
+// ask for the confirmation of a tool call.
+const FunctionName = "adk_request_confirmation"
+
+// Confirmation is the confirmation of a tool call.
+type Confirmation struct {
+	// Hint explains the human what is confirmed. It is set by the agent in
+	// the request.
+	Hint string `json:"hint,omitempty"`
+	// Confirmed reports whether the human approved the call. It is set by
+	// the client in the response.
+	Confirmed bool `json:"confirmed"`
+	// Args, if set by the client in the response, replace the arguments of
+	// the tool call, e.g. after the human edited them.
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// RequestArgs are the arguments of the function call asking for the
+// confirmation of a tool call.
+type RequestArgs struct {
+	// OriginalFunctionCall is the tool call to confirm.
+	OriginalFunctionCall *genai.FunctionCall `json:"originalFunctionCall"`
+	// ToolConfirmation is the requested confirmation, with its hint.
+	ToolConfirmation *Confirmation `json:"toolConfirmation"`
+}
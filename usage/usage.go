@@ -0,0 +1,236 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package usage aggregates the token usage reported by the models, and
+// estimates its cost.
+//
+// When enabled in the runner, the usage of each invocation is reported in a
+// summary event at its end, and added to the totals of the session and of
+// the user, kept in the session state under [SessionStateKey] and
+// [UserStateKey].
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+const (
+	// SessionStateKey is the session state key of the usage [Report] of the
+	// session.
+	SessionStateKey = "adk_usage"
+	// UserStateKey is the session state key of the usage [Report] of the
+	// user, across all their sessions of the app.
+	UserStateKey = session.KeyPrefixUser + "adk_usage"
+	// MetadataKey is the key of the usage [Report] of the invocation in the
+	// custom metadata of its summary event.
+	MetadataKey = "adk_usage"
+)
+
+// Config enables the usage accounting of the runner.
+type Config struct {
+	// Pricing estimates the cost of the model calls. Optional: without it,
+	// only the tokens are counted.
+	Pricing Pricing
+}
+
+// Usage is the number of tokens used by model calls.
+type Usage struct {
+	// PromptTokens is the number of tokens of the prompts, cached ones
+	// included.
+	PromptTokens int64 `json:"prompt_tokens"`
+	// CandidatesTokens is the number of tokens of the generated responses.
+	CandidatesTokens int64 `json:"candidates_tokens"`
+	// ThoughtsTokens is the number of tokens of the thoughts of thinking
+	// models.
+	ThoughtsTokens int64 `json:"thoughts_tokens"`
+	// CachedTokens is the number of prompt tokens read from a cache.
+	CachedTokens int64 `json:"cached_tokens"`
+	// TotalTokens is the total number of tokens reported by the models.
+	TotalTokens int64 `json:"total_tokens"`
+	// LLMCalls is the number of model calls reporting their usage.
+	LLMCalls int64 `json:"llm_calls"`
+	// Cost is the estimated cost of the calls, in the currency of the
+	// [Pricing]. Zero if the models have no price.
+	Cost float64 `json:"cost"`
+}
+
+// Add adds other to u.
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CandidatesTokens += other.CandidatesTokens
+	u.ThoughtsTokens += other.ThoughtsTokens
+	u.CachedTokens += other.CachedTokens
+	u.TotalTokens += other.TotalTokens
+	u.LLMCalls += other.LLMCalls
+	u.Cost += other.Cost
+}
+
+// FromMetadata returns the usage of a model call.
+func FromMetadata(md *genai.GenerateContentResponseUsageMetadata) Usage {
+	if md == nil {
+		return Usage{}
+	}
+	return Usage{
+		PromptTokens:     int64(md.PromptTokenCount),
+		CandidatesTokens: int64(md.CandidatesTokenCount),
+		ThoughtsTokens:   int64(md.ThoughtsTokenCount),
+		CachedTokens:     int64(md.CachedContentTokenCount),
+		TotalTokens:      int64(md.TotalTokenCount),
+		LLMCalls:         1,
+	}
+}
+
+// Price is the price of a model, per million tokens.
+type Price struct {
+	// Prompt is the price of the prompt tokens not read from a cache.
+	Prompt float64
+	// CachedPrompt is the price of the prompt tokens read from a cache.
+	CachedPrompt float64
+	// Output is the price of the candidates and thoughts tokens.
+	Output float64
+}
+
+// Pricing maps model names to their price.
+//
+// A model without an exact entry uses the price of the longest model name
+// prefixing its name, e.g. "gemini-2.5-flash" for "gemini-2.5-flash-001".
+type Pricing map[string]Price
+
+// Price returns the price of the model, and whether it has one.
+func (p Pricing) Price(modelName string) (Price, bool) {
+	if price, ok := p[modelName]; ok {
+		return price, true
+	}
+	var (
+		best  Price
+		found bool
+		n     int
+	)
+	for name, price := range p {
+		if len(name) > n && strings.HasPrefix(modelName, name) {
+			best, found, n = price, true, len(name)
+		}
+	}
+	return best, found
+}
+
+// Cost returns the estimated cost of u for the model, or zero if it has no
+// price.
+func (p Pricing) Cost(modelName string, u Usage) float64 {
+	price, ok := p.Price(modelName)
+	if !ok {
+		return 0
+	}
+	cached := min(u.CachedTokens, u.PromptTokens)
+	return (float64(u.PromptTokens-cached)*price.Prompt +
+		float64(cached)*price.CachedPrompt +
+		float64(u.CandidatesTokens+u.ThoughtsTokens)*price.Output) / 1e6
+}
+
+// Report is the usage of an invocation, a session or a user, with its
+// breakdown by agent and by model.
+type Report struct {
+	Total  Usage            `json:"total"`
+	Agents map[string]Usage `json:"agents,omitempty"`
+	Models map[string]Usage `json:"models,omitempty"`
+}
+
+// Record adds the usage of a model call made by the agent, priced with
+// pricing.
+func (r *Report) Record(agentName, modelName string, md *genai.GenerateContentResponseUsageMetadata, pricing Pricing) {
+	u := FromMetadata(md)
+	u.Cost = pricing.Cost(modelName, u)
+	r.add(agentName, modelName, u)
+}
+
+// Merge adds the usage of other to r.
+func (r *Report) Merge(other *Report) {
+	if other == nil {
+		return
+	}
+	r.Total.Add(other.Total)
+	for name, u := range other.Agents {
+		r.Agents = addTo(r.Agents, name, u)
+	}
+	for name, u := range other.Models {
+		r.Models = addTo(r.Models, name, u)
+	}
+}
+
+func (r *Report) add(agentName, modelName string, u Usage) {
+	r.Total.Add(u)
+	r.Agents = addTo(r.Agents, agentName, u)
+	if modelName != "" {
+		r.Models = addTo(r.Models, modelName, u)
+	}
+}
+
+func addTo(m map[string]Usage, key string, u Usage) map[string]Usage {
+	if m == nil {
+		m = make(map[string]Usage)
+	}
+	total := m[key]
+	total.Add(u)
+	m[key] = total
+	return m
+}
+
+// FromState returns the report stored in the state under key, e.g.
+// [SessionStateKey] or [UserStateKey], or an empty report if there is none.
+func FromState(state session.State, key string) (*Report, error) {
+	v, err := state.Get(key)
+	if err != nil {
+		if errors.Is(err, session.ErrStateKeyNotExist) {
+			return &Report{}, nil
+		}
+		return nil, err
+	}
+	return decode(v)
+}
+
+// FromEvent returns the report of the invocation held by its summary event,
+// or nil if the event is not a usage summary.
+func FromEvent(event *session.Event) (*Report, error) {
+	v, ok := event.CustomMetadata[MetadataKey]
+	if !ok {
+		return nil, nil
+	}
+	return decode(v)
+}
+
+// decode returns the report held in v, either a Report or its JSON
+// representation once stored, e.g. a map.
+func decode(v any) (*Report, error) {
+	switch v := v.(type) {
+	case *Report:
+		return v, nil
+	case Report:
+		return &v, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode usage report: %w", err)
+	}
+	var r Report
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("failed to decode usage report: %w", err)
+	}
+	return &r, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/usage"
+	"google.golang.org/genai"
+)
+
+func TestPricing_Cost(t *testing.T) {
+	pricing := usage.Pricing{
+		"gemini":           {Prompt: 1, CachedPrompt: 1, Output: 1},
+		"gemini-2.5-flash": {Prompt: 0.3, CachedPrompt: 0.075, Output: 2.5},
+		"gemini-2.5-pro":   {Prompt: 1.25, CachedPrompt: 0.3125, Output: 10},
+	}
+	u := usage.Usage{PromptTokens: 1_000_000, CachedTokens: 200_000, CandidatesTokens: 100_000, ThoughtsTokens: 100_000}
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{model: "gemini-2.5-flash", want: 0.8*0.3 + 0.2*0.075 + 0.2*2.5},
+		{model: "gemini-2.5-pro-preview", want: 0.8*1.25 + 0.2*0.3125 + 0.2*10},
+		{model: "gemini-1.5-flash", want: 1.2},
+		{model: "gpt-4o", want: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.model, func(t *testing.T) {
+			if got := pricing.Cost(tc.model, u); math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("Cost(%q) = %v, want %v", tc.model, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	md := &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5, TotalTokenCount: 15}
+	var r usage.Report
+	r.Record("a", "m1", md, nil)
+	r.Record("b", "m1", md, nil)
+	r.Record("b", "", md, nil)
+
+	call := usage.Usage{PromptTokens: 10, CandidatesTokens: 5, TotalTokens: 15, LLMCalls: 1}
+	times := func(n int64) usage.Usage {
+		return usage.Usage{PromptTokens: n * 10, CandidatesTokens: n * 5, TotalTokens: n * 15, LLMCalls: n}
+	}
+	want := usage.Report{
+		Total:  times(3),
+		Agents: map[string]usage.Usage{"a": call, "b": times(2)},
+		Models: map[string]usage.Usage{"m1": times(2)},
+	}
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("Record() mismatch (-want +got):\n%s", diff)
+	}
+
+	var merged usage.Report
+	merged.Merge(&r)
+	merged.Merge(&r)
+	want = usage.Report{
+		Total:  times(6),
+		Agents: map[string]usage.Usage{"a": times(2), "b": times(4)},
+		Models: map[string]usage.Usage{"m1": times(4)},
+	}
+	if diff := cmp.Diff(want, merged); diff != "" {
+		t.Errorf("Merge() mismatch (-want +got):\n%s", diff)
+	}
+	if r.Total != times(3) {
+		t.Errorf("Merge() modified the merged report: %+v", r.Total)
+	}
+}
+
+func TestFromState(t *testing.T) {
+	report := usage.Report{Total: usage.Usage{PromptTokens: 10, LLMCalls: 1, Cost: 0.5}, Agents: map[string]usage.Usage{"a": {PromptTokens: 10, LLMCalls: 1, Cost: 0.5}}}
+	// Reports read from persistent storage are JSON objects.
+	b, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored map[string]any
+	if err := json.Unmarshal(b, &stored); err != nil {
+		t.Fatal(err)
+	}
+
+	service := session.InMemoryService()
+	resp, err := service.Create(t.Context(), &session.CreateRequest{
+		AppName: "app", UserID: "user",
+		State: map[string]any{usage.SessionStateKey: report, usage.UserStateKey: stored},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{usage.SessionStateKey, usage.UserStateKey} {
+		got, err := usage.FromState(resp.Session.State(), key)
+		if err != nil {
+			t.Fatalf("FromState(%q) error = %v", key, err)
+		}
+		if diff := cmp.Diff(report, *got); diff != "" {
+			t.Errorf("FromState(%q) mismatch (-want +got):\n%s", key, diff)
+		}
+	}
+
+	empty, err := session.InMemoryService().Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := usage.FromState(empty.Session.State(), usage.SessionStateKey)
+	if err != nil {
+		t.Fatalf("FromState() error = %v", err)
+	}
+	if diff := cmp.Diff(usage.Report{}, *got); diff != "" {
+		t.Errorf("FromState() of an empty state mismatch (-want +got):\n%s", diff)
+	}
+}